@@ -0,0 +1,10 @@
+#!/bin/bash
+
+set -o errexit
+set -o xtrace
+
+install -o "$(id -u)" -g "$(id -g)" -m 0755 -D /backup-lib.sh /opt/percona/backup-lib.sh
+install -o "$(id -u)" -g "$(id -g)" -m 0755 -D /backup-physical.sh /opt/percona/backup-physical.sh
+install -o "$(id -u)" -g "$(id -g)" -m 0755 -D /backup-logical.sh /opt/percona/backup-logical.sh
+install -o "$(id -u)" -g "$(id -g)" -m 0755 -D /restore-logical.sh /opt/percona/restore-logical.sh
+install -o "$(id -u)" -g "$(id -g)" -m 0755 -D /restore-export.sh /opt/percona/restore-export.sh
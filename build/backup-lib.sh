@@ -0,0 +1,52 @@
+#!/bin/bash
+# Functions shared by the backup and restore scripts of the operator.
+# The scripts are installed into the backup image containers by the
+# backup-init-entrypoint.sh init container.
+
+MYSQL_OPTS=(--host="${PXC_SERVICE}" --user="${PXC_USER:-root}" --port=3306)
+
+function mysql_root() {
+    MYSQL_PWD="${PXC_PASS}" mysql "${MYSQL_OPTS[@]}" "$@"
+}
+
+function xbcloud_opts() {
+    local bucket=$1
+
+    echo "--storage=s3 --parallel=10 --s3-bucket=${bucket}"
+    if [ -n "${ENDPOINT}" ]; then
+        echo "--s3-endpoint=${ENDPOINT}"
+    fi
+    if [ -n "${DEFAULT_REGION}" ]; then
+        echo "--s3-region=${DEFAULT_REGION}"
+    fi
+    # without static credentials xbcloud gets them from the instance metadata,
+    # web identity (IRSA) tokens aren't supported by xbcloud
+    if [ -n "${ACCESS_KEY_ID}" ]; then
+        echo "--s3-access-key=${ACCESS_KEY_ID} --s3-secret-key=${SECRET_ACCESS_KEY}"
+    fi
+}
+
+# s3_put uploads files of the directory $1 into the backup $2 (bucket/path)
+function s3_put() {
+    local dir=$1 dest=$2
+
+    # shellcheck disable=SC2046
+    (cd "${dir}" && xbstream -c *) \
+        | xbcloud put $(xbcloud_opts "${dest%%/*}") "${dest#*/}"
+}
+
+# s3_get downloads files $3... of the backup $1 (bucket/path) into the directory $2,
+# compressed files are decompressed
+function s3_get() {
+    local src=$1 dir=$2
+    shift 2
+
+    # shellcheck disable=SC2046
+    xbcloud get $(xbcloud_opts "${src%%/*}") "${src#*/}" "$@" \
+        | xbstream -x --decompress -C "${dir}"
+}
+
+# dir_size prints size of the directory in bytes
+function dir_size() {
+    du -sb "$1" | cut -f1
+}
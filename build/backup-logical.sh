@@ -0,0 +1,114 @@
+#!/bin/bash
+# Dumps each table of the chosen databases (all user databases if
+# BACKUP_DATABASES is empty) into separate files, so any of them
+# can be restored independently by restore-logical.sh:
+#
+#   <db>-schema-create.sql  CREATE DATABASE statement
+#   <db>-schema-post.sql    routines and events of the database
+#   <db>.<table>-schema.sql CREATE TABLE (or VIEW) statement
+#   <db>.<table>*.sql       data of the table
+#   manifest                "<size in bytes> <file>" lines for the files above
+#
+# The dump is written into BACKUP_DIR or, if S3_BUCKET is set, uploaded to S3.
+# The backup summary is reported through the termination message.
+
+set -o errexit
+set -o xtrace
+set -o pipefail
+
+# shellcheck source=build/backup-lib.sh
+. "$(dirname "$0")/backup-lib.sh"
+
+SYSTEM_DATABASES="'mysql','sys','information_schema','performance_schema'"
+THREADS=${BACKUP_THREADS:-4}
+
+function list_databases() {
+    if [ -n "${BACKUP_DATABASES}" ]; then
+        tr ',' '\n' <<<"${BACKUP_DATABASES}"
+        return
+    fi
+    mysql_root -N -s -e "SELECT schema_name FROM information_schema.schemata WHERE schema_name NOT IN (${SYSTEM_DATABASES})"
+}
+
+function dump_mydumper() {
+    local dir=$1 db
+    for db in $(list_databases); do
+        MYSQL_PWD="${PXC_PASS}" mydumper "${MYSQL_OPTS[@]}" \
+            --database="${db}" --threads="${THREADS}" --outputdir="${dir}" \
+            --trx-consistency-only --triggers --events --routines
+    done
+}
+
+function mysqldump_root() {
+    MYSQL_PWD="${PXC_PASS}" mysqldump "${MYSQL_OPTS[@]}" --single-transaction --skip-lock-tables "$@"
+}
+
+function dump_table() {
+    local dir=$1 db=$2 table=$3
+    mysqldump_root --no-data --triggers "${db}" "${table}" >"${dir}/${db}.${table}-schema.sql"
+    mysqldump_root --no-create-info --skip-triggers "${db}" "${table}" >"${dir}/${db}.${table}.sql"
+}
+
+function dump_mysqldump() {
+    local dir=$1 db table
+    for db in $(list_databases); do
+        mysql_root -N -s -e "SHOW CREATE DATABASE \`${db}\`" | cut -f2 | sed 's/$/;/' >"${dir}/${db}-schema-create.sql"
+        mysqldump_root --no-create-info --no-data --skip-triggers --routines --events "${db}" >"${dir}/${db}-schema-post.sql"
+
+        for table in $(mysql_root -N -s -e "SELECT table_name FROM information_schema.tables WHERE table_schema='${db}'"); do
+            dump_table "${dir}" "${db}" "${table}" &
+            if [ "$(jobs -rp | wc -l)" -ge "${THREADS}" ]; then
+                wait -n
+            fi
+        done
+        # wait -n fails (and errexit stops the script) if any dump has failed
+        while [ -n "$(jobs -rp)" ]; do
+            wait -n
+        done
+    done
+}
+
+function main() {
+    if ! command -v "${LOGICAL_BACKUP_TOOL}"; then
+        echo "${LOGICAL_BACKUP_TOOL} isn't installed in the backup image" >&2
+        exit 1
+    fi
+
+    local dir donor
+    # dump threads have to read from the same member, the service can resolve to any of them
+    donor=$(mysql_root -N -s -e 'SELECT @@hostname')
+    MYSQL_OPTS[0]="--host=${donor}.${PXC_SERVICE}"
+
+    if [ -n "${S3_BUCKET}" ]; then
+        dir=$(mktemp -d)
+    else
+        dir="${BACKUP_DIR}"
+        mkdir -p "${dir}"
+        # the job is restarted after a failure
+        rm -rf "${dir:?}"/*
+    fi
+
+    case "${LOGICAL_BACKUP_TOOL}" in
+        mydumper) dump_mydumper "${dir}" ;;
+        mysqldump) dump_mysqldump "${dir}" ;;
+        *)
+            echo "unknown logical backup tool: ${LOGICAL_BACKUP_TOOL}" >&2
+            exit 1
+            ;;
+    esac
+
+    (cd "${dir}" && find . -maxdepth 1 -type f ! -name manifest -printf '%s %f\n' >manifest)
+    {
+        echo "tool_version = $("${LOGICAL_BACKUP_TOOL}" --version | head -1)"
+        echo "server_version = $(mysql_root -N -s -e 'SELECT @@version')"
+        echo "backup_size = $(dir_size "${dir}")"
+        echo "donor = ${donor}"
+    } >/dev/termination-log
+
+    if [ -n "${S3_BUCKET}" ]; then
+        s3_put "${dir}" "${S3_BUCKET}/${S3_BUCKET_PATH}"
+        rm -rf "${dir:?}"
+    fi
+}
+
+main
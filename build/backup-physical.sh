@@ -0,0 +1,73 @@
+#!/bin/bash
+# Runs backup.sh of the backup image and reports the backup summary through
+# the termination message of the container (see backup.Info in the operator):
+# the xtrabackup_info keys the operator uses, backup_size (in bytes),
+# donor (the pod the backup was taken from) and galera_gtid (from sst_info).
+# The summary is optional, the backup doesn't fail if it can't be fully collected.
+
+set -o errexit
+set -o xtrace
+set -o pipefail
+
+# shellcheck source=build/backup-lib.sh
+. "$(dirname "$0")/backup-lib.sh"
+
+INFO_KEYS='^(tool_version|server_version|innodb_to_lsn|binlog_pos) = '
+
+# xtrabackup_info_from_stream extracts xtrabackup_info from the backup stream file.
+# The file is at the end of the stream, so only the tail of the stream is read
+# starting from the first chunk of the file.
+function xtrabackup_info_from_stream() {
+    local stream=$1 dir=$2 tail_size=$((16 * 1024 * 1024)) size offset
+    size=$(stat -c%s "${stream}")
+    if [ "${size}" -lt "${tail_size}" ]; then
+        tail_size=${size}
+    fi
+    offset=$(tail -c "${tail_size}" "${stream}" \
+        | LC_ALL=C grep -obUaP 'XBSTCK01[\x00-\xff]{6}xtrabackup_info' | head -1 | cut -d: -f1)
+    if [ -z "${offset}" ]; then
+        echo "xtrabackup_info isn't found in ${stream}" >&2
+        return 1
+    fi
+    tail -c +"$((size - tail_size + offset + 1))" "${stream}" | xbstream -x --decompress -C "${dir}"
+}
+
+function collect_info() {
+    local log=$1 dir donor size=""
+    dir=$(mktemp -d)
+
+    if [ -n "${S3_BUCKET}" ]; then
+        s3_get "${S3_BUCKET}/${S3_BUCKET_PATH}.sst_info" "${dir}" sst_info
+        # the file is compressed if the backup is
+        s3_get "${S3_BUCKET}/${S3_BUCKET_PATH}" "${dir}" \
+            xtrabackup_info xtrabackup_info.qp xtrabackup_info.zst xtrabackup_info.lz4
+    else
+        xbstream -x -C "${dir}" <"${BACKUP_DIR}/sst_info"
+        xtrabackup_info_from_stream "${BACKUP_DIR}/xtrabackup.stream" "${dir}"
+        size=$(dir_size "${BACKUP_DIR}")
+    fi
+
+    # garbd logs "... Selected 0.0 (cluster1-pxc-2)(SYNCED) as donor."
+    donor=$(sed -n 's/.*Selected [0-9.]* (\([^)]*\)).* as donor.*/\1/p' "${log}" | tail -1)
+
+    {
+        # values of binlog_pos can take several lines
+        awk -v keys="${INFO_KEYS}" '/ = / {print_key = ($0 ~ keys)} print_key' "${dir}/xtrabackup_info"
+        if [ -n "${size}" ]; then
+            echo "backup_size = ${size}"
+        fi
+        if [ -n "${donor}" ]; then
+            echo "donor = ${donor}"
+        fi
+        echo "galera_gtid = $(sed -n 's/^galera-gtid=//p' "${dir}/sst_info")"
+    } >/dev/termination-log
+
+    rm -rf "${dir:?}"
+}
+
+LOG=$(mktemp)
+bash /usr/bin/backup.sh 2>&1 | tee "${LOG}"
+
+# whatever is collected is reported
+set +o errexit
+collect_info "${LOG}"
@@ -503,6 +503,7 @@ if [ "$1" = 'mysqld' -a -z "$wantHelp" ]; then
 					| sed 's/^[ \t]*//'
 			)"
 			wsrep_start_position_opt="--wsrep_start_position=$start_pos"
+			uuid=$(echo "$start_pos" | awk -F':' '{print $1}' || :)
 			seqno=$(echo "$start_pos" | awk -F':' '{print $NF}' || :)
 		else
 			# The server prints "..skipping position recovery.." if started without wsrep.
@@ -553,10 +554,11 @@ if [ "$1" = 'mysqld' -a -z "$wantHelp" ]; then
 		is_manual_recovery
 		if [[ -z $is_primary_exists && -f $grastate_loc && $safe_to_bootstrap != 1 ]] || [[ -z $is_primary_exists && -f "${DATADIR}/gvwstate.dat" ]]; then
 			trap '{ node_recovery "$@" ; }' USR1
-			touch /tmp/recovery-case
 			if [[ -z ${seqno} ]]; then
 				seqno="-1"
 			fi
+			# the operator reads the state of the node from this file to choose the node to bootstrap from
+			printf 'uuid: %s\nseqno: %s\n' "$uuid" "$seqno" >/tmp/recovery-case
 
 			set +o xtrace
 			sleep 3
@@ -568,7 +570,7 @@ if [ "$1" = 'mysqld' -a -z "$wantHelp" ]; then
 			echo 'Cluster will recover automatically from the crash now.'
 			echo 'If you have set spec.pxc.autoRecovery to false, run the following command to recover manually from this node:'
 			echo "kubectl -n $POD_NAMESPACE exec $(hostname) -c pxc -- sh -c 'kill -s USR1 1'"
-			#DO NOT CHANGE THE LINE BELOW. OUR AUTO-RECOVERY IS USING IT TO DETECT SEQNO OF CURRENT NODE. See K8SPXC-564
+			#DO NOT CHANGE THE LINE BELOW. OUR AUTO-RECOVERY IS USING IT TO DETECT SEQNO OF THE NODE WITH THE OLDER RECOVERY FILE. See K8SPXC-564
 			echo "#####################################################LAST_LINE:$NODE_NAME:$seqno:#####################################################"
 
 			for (( ; ; )); do
@@ -0,0 +1,79 @@
+#!/bin/bash
+# Prepares the physical backup for the partial restore and serves tablespaces
+# of the requested databases (RESTORE_DATABASES) and tables (RESTORE_TABLES,
+# in db.table form) to PXC members:
+#
+#   ${EXPORT_DIR}/tables   db.table names of the exported tables, one per line,
+#                          the pod is ready once the file is written
+#   ${EXPORT_DIR}/current  db/table the operator imports now
+#   port 3307              tar of the files of the current table (.ibd, .cfg and
+#                          the ones of partitions) for every connection
+#
+# The backup is read from BACKUP_DIR or, if S3_BUCKET_URL is set, downloaded from S3.
+
+set -o errexit
+set -o xtrace
+set -o pipefail
+
+# shellcheck source=build/backup-lib.sh
+. "$(dirname "$0")/backup-lib.sh"
+
+PORT=3307
+DATA="${EXPORT_DIR}/data"
+VAULT_CONF=/etc/mysql/vault-keyring-secret/keyring_vault.conf
+
+function fetch_backup() {
+    mkdir -p "${DATA}"
+    if [ -n "${S3_BUCKET_URL}" ]; then
+        s3_get "${S3_BUCKET_URL}" "${DATA}"
+    else
+        xbstream -x --decompress -C "${DATA}" <"${BACKUP_DIR}/xtrabackup.stream"
+    fi
+}
+
+function prepare() {
+    local opts=()
+    if [ -f "${VAULT_CONF}" ]; then
+        opts+=(--keyring-vault-config="${VAULT_CONF}")
+    fi
+    xtrabackup --prepare --export "${opts[@]}" --target-dir="${DATA}"
+}
+
+# exported reports if the tablespace of the table (or of its partitions) is exported,
+# it's possible for InnoDB tables only
+function exported() {
+    local db=${1%%.*} table=${1#*.}
+    compgen -G "${DATA}/${db}/${table}[.#]*cfg" >/dev/null
+}
+
+function list_tables() {
+    local db table
+    for db in $(tr ',' '\n' <<<"${RESTORE_DATABASES}"); do
+        find "${DATA}/${db}" -maxdepth 1 -name '*.cfg' -printf '%f\n' \
+            | sed "s/\.cfg$//; s/#[pP]#.*//; s/^/${db}./"
+    done
+    for table in $(tr ',' '\n' <<<"${RESTORE_TABLES}"); do
+        if ! exported "${table}"; then
+            echo "table ${table} isn't found in the backup or isn't an InnoDB table" >&2
+            return 1
+        fi
+        echo "${table}"
+    done
+}
+
+function main() {
+    local tables
+    fetch_backup
+    prepare
+
+    tables=$(list_tables | sort -u)
+    echo "${tables}" >"${EXPORT_DIR}/tables.tmp"
+    mv "${EXPORT_DIR}/tables.tmp" "${EXPORT_DIR}/tables"
+
+    # the operator imports tables one by one, so every connection gets
+    # the files of the table written into the current file
+    exec ncat --listen --keep-open --send-only --port "${PORT}" \
+        --sh-exec "cd '${DATA}' && exec tar -cf - \$(cat '${EXPORT_DIR}/current')[.#]*"
+}
+
+main
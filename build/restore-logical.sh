@@ -0,0 +1,159 @@
+#!/bin/bash
+# Loads databases (RESTORE_DATABASES) and tables (RESTORE_TABLES, in db.table form)
+# from the dump made by backup-logical.sh into the running cluster. Everything is
+# loaded if both are empty. Restored tables are dropped and created again, other
+# tables of the cluster are not touched.
+#
+# The dump is read from BACKUP_DIR or, if S3_BUCKET_URL is set, downloaded from S3.
+
+set -o errexit
+set -o xtrace
+set -o pipefail
+
+# shellcheck source=build/backup-lib.sh
+. "$(dirname "$0")/backup-lib.sh"
+
+DIR="${BACKUP_DIR}"
+if [ -n "${S3_BUCKET_URL}" ]; then
+    DIR=$(mktemp -d)
+    s3_get "${S3_BUCKET_URL}" "${DIR}" manifest
+fi
+MANIFEST="${DIR}/manifest"
+
+# files of the table: schema, data (mydumper splits it into numbered chunks) and triggers
+function table_files() {
+    local db=$1 table=$2
+    awk '{print $2}' "${MANIFEST}" \
+        | grep -E "^$(escape "${db}.${table}")(-schema(-view|-triggers)?\.sql|(\.[0-9]+)?\.sql)$" || :
+}
+
+function escape() {
+    sed 's/[.[\*^$()+?{|]/\\&/g' <<<"$1"
+}
+
+function list_databases() {
+    if [ -n "${RESTORE_DATABASES}" ]; then
+        tr ',' '\n' <<<"${RESTORE_DATABASES}"
+        return
+    fi
+    if [ -z "${RESTORE_TABLES}" ]; then
+        awk '{print $2}' "${MANIFEST}" | sed -n 's/-schema-create\.sql$//p'
+    fi
+}
+
+function list_tables() {
+    local db
+    for db in $(list_databases); do
+        awk '{print $2}' "${MANIFEST}" | sed -n "s/^$(escape "${db}")\.\([^.]*\)-schema\(-view\)\?\.sql$/${db}.\1/p"
+    done
+    if [ -n "${RESTORE_TABLES}" ]; then
+        tr ',' '\n' <<<"${RESTORE_TABLES}"
+    fi
+}
+
+TOTAL=0
+LOADED=0
+
+function file_size() {
+    awk -v f="$1" '$2 == f {s = $1} END {print s + 0}' "${MANIFEST}"
+}
+
+function report_progress() {
+    echo "Restore progress: downloaded=${LOADED} total=${TOTAL}"
+}
+
+# fetch makes sure files are in DIR, they are downloaded on demand if the backup is on S3
+function fetch() {
+    if [ -n "${S3_BUCKET_URL}" ] && [ $# -gt 0 ]; then
+        s3_get "${S3_BUCKET_URL}" "${DIR}" "$@"
+    fi
+}
+
+function load() {
+    local db=$1 file
+    shift
+    for file in "$@"; do
+        { echo "SET SESSION foreign_key_checks=0;"; cat "${DIR}/${file}"; } | mysql_root ${db:+"${db}"}
+        LOADED=$((LOADED + $(file_size "${file}")))
+        if [ -n "${S3_BUCKET_URL}" ]; then
+            rm -f "${DIR:?}/${file}"
+        fi
+    done
+    report_progress
+}
+
+function main() {
+    local databases tables db table files views=()
+
+    databases=$(list_databases | sort -u)
+    tables=$(list_tables | sort -u)
+    if [ -z "${tables}" ] && [ -z "${databases}" ]; then
+        echo "nothing to restore" >&2
+        exit 1
+    fi
+
+    for table in ${tables}; do
+        files=$(table_files "${table%%.*}" "${table#*.}")
+        if [ -z "${files}" ]; then
+            echo "table ${table} isn't found in the backup" >&2
+            exit 1
+        fi
+        for file in ${files}; do
+            TOTAL=$((TOTAL + $(file_size "${file}")))
+        done
+    done
+    for db in ${databases}; do
+        TOTAL=$((TOTAL + $(file_size "${db}-schema-post.sql")))
+    done
+    report_progress
+
+    for db in $({ echo "${databases}"; sed 's/\..*//' <<<"${tables}"; } | sort -u); do
+        if grep -q " ${db}-schema-create.sql$" "${MANIFEST}"; then
+            fetch "${db}-schema-create.sql"
+            sed 's/^CREATE DATABASE /CREATE DATABASE IF NOT EXISTS /' "${DIR}/${db}-schema-create.sql" | mysql_root
+        else
+            mysql_root -e "CREATE DATABASE IF NOT EXISTS \`${db}\`"
+        fi
+    done
+
+    for table in ${tables}; do
+        db=${table%%.*}
+        table=${table#*.}
+        # shellcheck disable=SC2046
+        fetch $(table_files "${db}" "${table}")
+        if [ -f "${DIR}/${db}.${table}-schema-view.sql" ]; then
+            # views are created when all tables are there
+            views+=("${db}.${table}")
+            continue
+        fi
+        mysql_root -e "DROP VIEW IF EXISTS \`${db}\`.\`${table}\`; DROP TABLE IF EXISTS \`${db}\`.\`${table}\`"
+        load "${db}" "${db}.${table}-schema.sql"
+        # shellcheck disable=SC2046
+        load "${db}" $(table_files "${db}" "${table}" | grep -v -- '-schema')
+        # shellcheck disable=SC2046
+        load "${db}" $(table_files "${db}" "${table}" | grep -- '-schema-triggers\.sql$')
+    done
+
+    for table in "${views[@]}"; do
+        db=${table%%.*}
+        table=${table#*.}
+        mysql_root -e "DROP VIEW IF EXISTS \`${db}\`.\`${table}\`; DROP TABLE IF EXISTS \`${db}\`.\`${table}\`"
+        load "${db}" "${db}.${table}-schema-view.sql"
+        # mydumper creates a placeholder table for the view, it isn't needed
+        LOADED=$((LOADED + $(file_size "${db}.${table}-schema.sql")))
+    done
+
+    # routines and events are restored with the whole database only
+    for db in ${databases}; do
+        if grep -q " ${db}-schema-post.sql$" "${MANIFEST}"; then
+            fetch "${db}-schema-post.sql"
+            load "${db}" "${db}-schema-post.sql"
+        fi
+    done
+
+    if [ -n "${S3_BUCKET_URL}" ]; then
+        rm -rf "${DIR:?}"
+    fi
+}
+
+main
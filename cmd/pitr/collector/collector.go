@@ -34,8 +34,8 @@ type Config struct {
 	PXCUser        string  `env:"PXC_USER,required"`
 	PXCPass        string  `env:"PXC_PASS,required"`
 	S3Endpoint     string  `env:"ENDPOINT" envDefault:"s3.amazonaws.com"`
-	S3AccessKeyID  string  `env:"ACCESS_KEY_ID,required"`
-	S3AccessKey    string  `env:"SECRET_ACCESS_KEY,required"`
+	S3AccessKeyID  string  `env:"ACCESS_KEY_ID"`
+	S3AccessKey    string  `env:"SECRET_ACCESS_KEY"`
 	S3BucketURL    string  `env:"S3_BUCKET_URL,required"`
 	S3Region       string  `env:"DEFAULT_REGION,required"`
 	BufferSize     int64   `env:"BUFFER_SIZE"`
@@ -28,6 +28,7 @@ type Recoverer struct {
 	recoverType    RecoverType
 	pxcServiceName string
 	binlogs        []string
+	binlogGTIDSets map[string]string
 	gtidSet        string
 	startGTID      string
 	recoverFlag    string
@@ -43,21 +44,22 @@ type Config struct {
 	RecoverTime    string `env:"PITR_DATE"`
 	RecoverType    string `env:"PITR_RECOVERY_TYPE,required"`
 	GTID           string `env:"PITR_GTID"`
+	StartGTID      string `env:"PITR_START_GTID"`
 	BinlogStorage  BinlogS3
 }
 
 type BackupS3 struct {
 	Endpoint    string `env:"ENDPOINT" envDefault:"s3.amazonaws.com"`
-	AccessKeyID string `env:"ACCESS_KEY_ID,required"`
-	AccessKey   string `env:"SECRET_ACCESS_KEY,required"`
+	AccessKeyID string `env:"ACCESS_KEY_ID"`
+	AccessKey   string `env:"SECRET_ACCESS_KEY"`
 	Region      string `env:"DEFAULT_REGION,required"`
 	BackupDest  string `env:"S3_BUCKET_URL,required"`
 }
 
 type BinlogS3 struct {
 	Endpoint    string `env:"BINLOG_S3_ENDPOINT" envDefault:"s3.amazonaws.com"`
-	AccessKeyID string `env:"BINLOG_ACCESS_KEY_ID,required"`
-	AccessKey   string `env:"BINLOG_SECRET_ACCESS_KEY,required"`
+	AccessKeyID string `env:"BINLOG_ACCESS_KEY_ID"`
+	AccessKey   string `env:"BINLOG_SECRET_ACCESS_KEY"`
 	Region      string `env:"BINLOG_S3_REGION,required"`
 	BucketURL   string `env:"BINLOG_S3_BUCKET_URL,required"`
 }
@@ -85,9 +87,13 @@ func New(c Config) (*Recoverer, error) {
 		return nil, errors.Wrap(err, "new storage manager")
 	}
 
-	startGTID, err := getStartGTIDSet(c.BackupStorage)
-	if err != nil {
-		return nil, errors.Wrap(err, "get start GTID")
+	// the last GTID set of the backup is passed by the operator if it was reported by the backup job
+	startGTID := c.StartGTID
+	if startGTID == "" {
+		startGTID, err = getStartGTIDSet(c.BackupStorage)
+		if err != nil {
+			return nil, errors.Wrap(err, "get start GTID")
+		}
 	}
 
 	if c.RecoverType == string(Transaction) {
@@ -258,7 +264,7 @@ func (r *Recoverer) recover() (err error) {
 	if err != nil {
 		return errors.Wrap(err, "drop collector funcs")
 	}
-	for _, binlog := range r.binlogs {
+	for i, binlog := range r.binlogs {
 		log.Println("working with", binlog)
 		if r.recoverType == Date {
 			binlogArr := strings.Split(binlog, "_")
@@ -295,6 +301,8 @@ func (r *Recoverer) recover() (err error) {
 		if err != nil {
 			return errors.Wrapf(err, "cmd run. stderr: %s, stdout: %s", errb.String(), outb.String())
 		}
+		// the operator parses this line to report the progress in the restore status
+		log.Printf("PITR progress: binlog=%s applied=%d/%d gtid=%s", binlog, i+1, len(r.binlogs), r.binlogGTIDSets[binlog])
 	}
 
 	return nil
@@ -403,6 +411,7 @@ func (r *Recoverer) setBinlogs() error {
 	}
 	reverse(list)
 	binlogs := []string{}
+	r.binlogGTIDSets = make(map[string]string)
 	sourceID := strings.Split(r.startGTID, ":")[0]
 	log.Println("current gtid set is", r.startGTID)
 	for _, binlog := range list {
@@ -443,6 +452,7 @@ func (r *Recoverer) setBinlogs() error {
 		}
 
 		binlogs = append(binlogs, binlog)
+		r.binlogGTIDSets[binlog] = binlogGTIDSet
 		subResult, err := r.db.SubtractGTIDSet(r.startGTID, binlogGTIDSet)
 		log.Println("Checking sub result", " binlog gtid ", binlogGTIDSet, " sub result ", subResult)
 		if err != nil {
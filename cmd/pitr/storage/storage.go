@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"io"
+	"net/http"
 	"strings"
 
 	"github.com/minio/minio-go/v7"
@@ -24,10 +25,25 @@ type S3 struct {
 	prefix      string          // prefix for S3 requests
 }
 
-// NewS3 return new Manager, useSSL using ssl for connection with storage
+// NewS3 return new Manager, useSSL using ssl for connection with storage.
+// If access keys are empty the default AWS credentials chain is used
+// (environment, web identity token, instance metadata).
 func NewS3(endpoint, accessKeyID, secretAccessKey, bucketName, prefix, region string, useSSL bool) (*S3, error) {
+	creds := credentials.NewStaticV4(accessKeyID, secretAccessKey, "")
+	if accessKeyID == "" && secretAccessKey == "" {
+		creds = credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.FileAWSCredentials{},
+			&credentials.IAM{
+				Client: &http.Client{
+					Transport: http.DefaultTransport,
+				},
+			},
+		})
+	}
+
 	minioClient, err := minio.New(strings.TrimRight(endpoint, "/"), &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
+		Creds:  creds,
 		Secure: useSSL,
 		Region: region,
 	})
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
@@ -36,17 +37,109 @@ type PerconaXtraDBClusterBackup struct {
 }
 
 type PXCBackupSpec struct {
-	PXCCluster  string `json:"pxcCluster"`
-	StorageName string `json:"storageName,omitempty"`
+	PXCCluster  string             `json:"pxcCluster"`
+	StorageName string             `json:"storageName,omitempty"`
+	Type        BackupType         `json:"type,omitempty"`
+	Logical     *LogicalBackupSpec `json:"logical,omitempty"`
+	Hooks       *BackupHooks       `json:"hooks,omitempty"`
+}
+
+// LabelBackupCatalog marks read-only backups imported from the storage
+const LabelBackupCatalog = "percona.com/catalog"
+
+type BackupType string
+
+const (
+	// BackupTypePhysical is a full binary backup made by xtrabackup
+	BackupTypePhysical BackupType = "physical"
+	// BackupTypeLogical is a per-schema and per-table SQL dump
+	BackupTypeLogical BackupType = "logical"
+)
+
+type LogicalBackupTool string
+
+const (
+	LogicalBackupMydumper  LogicalBackupTool = "mydumper"
+	LogicalBackupMysqldump LogicalBackupTool = "mysqldump"
+)
+
+type LogicalBackupSpec struct {
+	Tool LogicalBackupTool `json:"tool,omitempty"`
+	// Databases to dump, all user databases are dumped if empty
+	Databases []string `json:"databases,omitempty"`
+	Threads   int32    `json:"threads,omitempty"`
 }
 
 type PXCBackupStatus struct {
-	State         PXCBackupState       `json:"state,omitempty"`
-	CompletedAt   *metav1.Time         `json:"completed,omitempty"`
-	LastScheduled *metav1.Time         `json:"lastscheduled,omitempty"`
-	Destination   string               `json:"destination,omitempty"`
-	StorageName   string               `json:"storageName,omitempty"`
-	S3            *BackupStorageS3Spec `json:"s3,omitempty"`
+	State             PXCBackupState       `json:"state,omitempty"`
+	Type              BackupType           `json:"type,omitempty"`
+	StartedAt         *metav1.Time         `json:"started,omitempty"`
+	CompletedAt       *metav1.Time         `json:"completed,omitempty"`
+	LastScheduled     *metav1.Time         `json:"lastscheduled,omitempty"`
+	Destination       string               `json:"destination,omitempty"`
+	StorageName       string               `json:"storageName,omitempty"`
+	S3                *BackupStorageS3Spec `json:"s3,omitempty"`
+	Size              string               `json:"size,omitempty"`
+	XtrabackupVersion string               `json:"xtrabackupVersion,omitempty"`
+	ServerVersion     string               `json:"serverVersion,omitempty"`
+	// LastGTIDSet is the GTID set of the cluster the backup ends with
+	LastGTIDSet string `json:"lastGTIDSet,omitempty"`
+	LSN         string `json:"lsn,omitempty"`
+	Donor       string `json:"donor,omitempty"`
+	// PITRReady is true if binlogs were collected while the backup was taken
+	// and the point-in-time recovery can be started from it
+	PITRReady bool               `json:"pitrReady,omitempty"`
+	Hooks     []BackupHookStatus `json:"hooks,omitempty"`
+}
+
+// BackupHooks are executed by the operator right before the backup job
+// is created (Pre) and right after it is finished (Post).
+type BackupHooks struct {
+	Pre  []BackupHook `json:"pre,omitempty"`
+	Post []BackupHook `json:"post,omitempty"`
+}
+
+// BackupHook is either a list of SQL statements executed through the operator user
+// or a command executed in a container (usually a sidecar) of a PXC pod.
+type BackupHook struct {
+	Name          string                  `json:"name"`
+	SQL           []string                `json:"sql,omitempty"`
+	Exec          *BackupHookExec         `json:"exec,omitempty"`
+	FailurePolicy BackupHookFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+type BackupHookExec struct {
+	Container string   `json:"container"`
+	Command   []string `json:"command"`
+}
+
+type BackupHookFailurePolicy string
+
+const (
+	BackupHookFail     BackupHookFailurePolicy = "fail"
+	BackupHookContinue BackupHookFailurePolicy = "continue"
+)
+
+type BackupHookStage string
+
+const (
+	BackupHookPre  BackupHookStage = "pre"
+	BackupHookPost BackupHookStage = "post"
+)
+
+type BackupHookState string
+
+const (
+	BackupHookSucceeded BackupHookState = "Succeeded"
+	BackupHookFailed    BackupHookState = "Failed"
+)
+
+type BackupHookStatus struct {
+	Name     string          `json:"name"`
+	Stage    BackupHookStage `json:"stage"`
+	State    BackupHookState `json:"state"`
+	Message  string          `json:"message,omitempty"`
+	Executed *metav1.Time    `json:"executed,omitempty"`
 }
 
 type PXCBackupState string
@@ -59,6 +152,97 @@ const (
 	BackupSucceeded PXCBackupState = "Succeeded"
 )
 
+func (cr *PerconaXtraDBClusterBackup) CheckNsetDefaults() error {
+	if cr.Spec.PXCCluster == "" {
+		return errors.New("pxcCluster can't be empty")
+	}
+
+	switch cr.Spec.Type {
+	case "":
+		cr.Spec.Type = BackupTypePhysical
+	case BackupTypePhysical, BackupTypeLogical:
+	default:
+		return errors.Errorf("unknown backup type %s", cr.Spec.Type)
+	}
+
+	if cr.Spec.Type == BackupTypeLogical {
+		if cr.Spec.Logical == nil {
+			cr.Spec.Logical = &LogicalBackupSpec{}
+		}
+		switch cr.Spec.Logical.Tool {
+		case "":
+			cr.Spec.Logical.Tool = LogicalBackupMydumper
+		case LogicalBackupMydumper, LogicalBackupMysqldump:
+		default:
+			return errors.Errorf("unknown logical backup tool %s", cr.Spec.Logical.Tool)
+		}
+		if cr.Spec.Logical.Threads <= 0 {
+			cr.Spec.Logical.Threads = 4
+		}
+	}
+
+	return errors.Wrap(cr.Spec.Hooks.Validate(), "hooks")
+}
+
+// HookExecuted checks if the hook with given name was already run on given stage
+func (s *PXCBackupStatus) HookExecuted(stage BackupHookStage, name string) bool {
+	for _, h := range s.Hooks {
+		if h.Stage == stage && h.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *BackupHooks) Validate() error {
+	if h == nil {
+		return nil
+	}
+
+	names := make(map[string]struct{})
+	for _, hook := range append(append([]BackupHook{}, h.Pre...), h.Post...) {
+		if hook.Name == "" {
+			return errors.New("hook name can't be empty")
+		}
+		if len(hook.SQL) == 0 && hook.Exec == nil {
+			return errors.Errorf("hook %s: sql or exec should be specified", hook.Name)
+		}
+		if len(hook.SQL) > 0 && hook.Exec != nil {
+			return errors.Errorf("hook %s: sql and exec can't be specified simultaneously", hook.Name)
+		}
+		if hook.Exec != nil && (hook.Exec.Container == "" || len(hook.Exec.Command) == 0) {
+			return errors.Errorf("hook %s: exec.container and exec.command can't be empty", hook.Name)
+		}
+		switch hook.FailurePolicy {
+		case "", BackupHookFail, BackupHookContinue:
+		default:
+			return errors.Errorf("hook %s: unknown failure policy %s", hook.Name, hook.FailurePolicy)
+		}
+		if _, ok := names[hook.Name]; ok {
+			return errors.Errorf("hook %s: name must be unique", hook.Name)
+		}
+		names[hook.Name] = struct{}{}
+	}
+
+	return nil
+}
+
+// Merge returns hooks of h followed by hooks of o
+func (h *BackupHooks) Merge(o *BackupHooks) *BackupHooks {
+	if h == nil {
+		return o
+	}
+	if o == nil {
+		return h
+	}
+
+	return &BackupHooks{
+		Pre:  append(append([]BackupHook{}, h.Pre...), o.Pre...),
+		Post: append(append([]BackupHook{}, h.Post...), o.Post...),
+	}
+}
+
 // OwnerRef returns OwnerReference to object
 func (cr *PerconaXtraDBClusterBackup) OwnerRef(scheme *runtime.Scheme) (metav1.OwnerReference, error) {
 	gvk, err := apiutil.GVKForObject(cr, scheme)
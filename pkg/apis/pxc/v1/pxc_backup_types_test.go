@@ -0,0 +1,75 @@
+package v1
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBackupHooksValidate(t *testing.T) {
+	sql := []string{"FLUSH TABLES"}
+	exec := &BackupHookExec{Container: "logs", Command: []string{"sync"}}
+
+	cases := []struct {
+		name  string
+		hooks *BackupHooks
+		valid bool
+	}{
+		{"nil hooks", nil, true},
+		{"sql and exec hooks", &BackupHooks{
+			Pre:  []BackupHook{{Name: "flush", SQL: sql}},
+			Post: []BackupHook{{Name: "sync", Exec: exec, FailurePolicy: BackupHookContinue}},
+		}, true},
+		{"empty name", &BackupHooks{Pre: []BackupHook{{SQL: sql}}}, false},
+		{"no action", &BackupHooks{Pre: []BackupHook{{Name: "flush"}}}, false},
+		{"sql and exec", &BackupHooks{Pre: []BackupHook{{Name: "flush", SQL: sql, Exec: exec}}}, false},
+		{"exec without container", &BackupHooks{Post: []BackupHook{{Name: "sync", Exec: &BackupHookExec{Command: []string{"sync"}}}}}, false},
+		{"exec without command", &BackupHooks{Post: []BackupHook{{Name: "sync", Exec: &BackupHookExec{Container: "logs"}}}}, false},
+		{"unknown failure policy", &BackupHooks{Pre: []BackupHook{{Name: "flush", SQL: sql, FailurePolicy: "ignore"}}}, false},
+		{"duplicate name across stages", &BackupHooks{
+			Pre:  []BackupHook{{Name: "flush", SQL: sql}},
+			Post: []BackupHook{{Name: "flush", SQL: sql}},
+		}, false},
+	}
+
+	for _, c := range cases {
+		err := c.hooks.Validate()
+		if (err == nil) != c.valid {
+			t.Errorf("%s: unexpected result: %v", c.name, err)
+		}
+	}
+}
+
+func TestBackupHooksMerge(t *testing.T) {
+	storage := &BackupHooks{
+		Pre:  []BackupHook{{Name: "storage-pre"}},
+		Post: []BackupHook{{Name: "storage-post"}},
+	}
+	backup := &BackupHooks{
+		Pre: []BackupHook{{Name: "backup-pre"}},
+	}
+
+	cases := []struct {
+		name     string
+		h, o     *BackupHooks
+		expected *BackupHooks
+	}{
+		{"both nil", nil, nil, nil},
+		{"storage only", storage, nil, storage},
+		{"backup only", nil, backup, backup},
+		{"storage hooks go first", storage, backup, &BackupHooks{
+			Pre:  []BackupHook{{Name: "storage-pre"}, {Name: "backup-pre"}},
+			Post: []BackupHook{{Name: "storage-post"}},
+		}},
+	}
+
+	for _, c := range cases {
+		got := c.h.Merge(c.o)
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("%s: expected %+v, got %+v", c.name, c.expected, got)
+		}
+	}
+
+	if len(storage.Pre) != 1 || len(backup.Pre) != 1 {
+		t.Error("merged hooks are changed")
+	}
+}
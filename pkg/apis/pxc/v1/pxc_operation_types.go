@@ -0,0 +1,134 @@
+package v1
+
+import (
+	"errors"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// OperationType is the day-2 task the operation runs on the cluster
+type OperationType string
+
+const (
+	// OperationRestartMember deletes the member pod and waits until it rejoins the cluster
+	OperationRestartMember OperationType = "RestartMember"
+	// OperationForceSST wipes the datadir volume of the member, so it gets the data with SST
+	OperationForceSST OperationType = "ForceSST"
+	// OperationRotatePasswords generates new passwords for the system users
+	OperationRotatePasswords OperationType = "RotatePasswords"
+	OperationAnalyzeTables   OperationType = "AnalyzeTables"
+	OperationOptimizeTables  OperationType = "OptimizeTables"
+	// OperationFlushLogs runs FLUSH LOGS on every member
+	OperationFlushLogs OperationType = "FlushLogs"
+	// OperationKillQueries kills queries running longer than QueryTimeSeconds on every member
+	OperationKillQueries OperationType = "KillQueries"
+	// OperationReevaluateWriter makes the proxies check the members and choose the writer again
+	OperationReevaluateWriter OperationType = "ReevaluateWriter"
+)
+
+// PerconaXtraDBClusterOperationSpec defines the desired state of PerconaXtraDBClusterOperation
+type PerconaXtraDBClusterOperationSpec struct {
+	PXCCluster string        `json:"pxcCluster"`
+	Type       OperationType `json:"type"`
+	// Member is the PXC pod for RestartMember and ForceSST
+	Member string `json:"member,omitempty"`
+	// Tables (in db.table form) for AnalyzeTables and OptimizeTables
+	Tables []string `json:"tables,omitempty"`
+	// QueryTimeSeconds is the running time of the queries KillQueries kills
+	QueryTimeSeconds int32 `json:"queryTimeSeconds,omitempty"`
+}
+
+// PerconaXtraDBClusterOperationStatus defines the observed state of PerconaXtraDBClusterOperation
+type PerconaXtraDBClusterOperationStatus struct {
+	State       OperationState `json:"state,omitempty"`
+	Message     string         `json:"message,omitempty"`
+	StartedAt   *metav1.Time   `json:"started,omitempty"`
+	CompletedAt *metav1.Time   `json:"completed,omitempty"`
+	// Results are the outcomes of the operation on each member
+	Results []OperationResult `json:"results,omitempty"`
+	// PodUID and PVCUID are the member objects replaced by the operation
+	PodUID types.UID `json:"podUID,omitempty"`
+	PVCUID types.UID `json:"pvcUID,omitempty"`
+	// Writer is the member the proxies chose as the writer
+	Writer string `json:"writer,omitempty"`
+}
+
+// OperationResult is the outcome of the operation on a member or a table
+type OperationResult struct {
+	Target  string `json:"target"`
+	Message string `json:"message,omitempty"`
+}
+
+type OperationState string
+
+const (
+	OperationNew OperationState = ""
+	// OperationPending waits for another operation or a backup, restore or update of the cluster to finish
+	OperationPending   OperationState = "Pending"
+	OperationRunning   OperationState = "Running"
+	OperationSucceeded OperationState = "Succeeded"
+	OperationFailed    OperationState = "Failed"
+)
+
+// Finished checks if the operation is over
+func (s OperationState) Finished() bool {
+	return s == OperationSucceeded || s == OperationFailed
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PerconaXtraDBClusterOperation is the Schema for the perconaxtradbclusteroperations API
+// +k8s:openapi-gen=true
+type PerconaXtraDBClusterOperation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PerconaXtraDBClusterOperationSpec   `json:"spec,omitempty"`
+	Status PerconaXtraDBClusterOperationStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PerconaXtraDBClusterOperationList contains a list of PerconaXtraDBClusterOperation
+type PerconaXtraDBClusterOperationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PerconaXtraDBClusterOperation `json:"items"`
+}
+
+func (cr *PerconaXtraDBClusterOperation) CheckNsetDefaults() error {
+	if cr.Spec.PXCCluster == "" {
+		return errors.New("pxcCluster can't be empty")
+	}
+
+	switch cr.Spec.Type {
+	case OperationRestartMember, OperationForceSST:
+		if cr.Spec.Member == "" {
+			return errors.New("member can't be empty")
+		}
+	case OperationAnalyzeTables, OperationOptimizeTables:
+		if len(cr.Spec.Tables) == 0 {
+			return errors.New("tables can't be empty")
+		}
+		for _, t := range cr.Spec.Tables {
+			if spl := strings.Split(t, "."); len(spl) != 2 || spl[0] == "" || spl[1] == "" {
+				return errors.New("tables should be specified in db.table form")
+			}
+		}
+	case OperationKillQueries:
+		if cr.Spec.QueryTimeSeconds <= 0 {
+			return errors.New("queryTimeSeconds should be greater than 0")
+		}
+	case OperationRotatePasswords, OperationFlushLogs, OperationReevaluateWriter:
+	default:
+		return errors.New("unknown operation type " + string(cr.Spec.Type))
+	}
+
+	return nil
+}
+
+func init() {
+	SchemeBuilder.Register(&PerconaXtraDBClusterOperation{}, &PerconaXtraDBClusterOperationList{})
+}
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"errors"
+	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -12,6 +13,43 @@ type PerconaXtraDBClusterRestoreSpec struct {
 	BackupName   string           `json:"backupName"`
 	BackupSource *PXCBackupStatus `json:"backupSource,omitempty"`
 	PITR         *PITR            `json:"pitr,omitempty"`
+	// Databases and Tables (in db.table form) limit the restore to the
+	// listed objects. The cluster keeps running during such restore.
+	// Tables are restored from physical backups with transportable
+	// tablespaces, so they have to exist in the cluster already. Writes to
+	// each table are blocked on all members while it's imported.
+	Databases []string `json:"databases,omitempty"`
+	Tables    []string `json:"tables,omitempty"`
+	// Cancel stops the restore and rolls the cluster back.
+	// Deletion of the unfinished restore does the same.
+	Cancel    bool              `json:"cancel,omitempty"`
+	SafetyNet *RestoreSafetyNet `json:"safetyNet,omitempty"`
+	// BackupNamespace is the namespace of the backup if it differs from the
+	// namespace of the restore. The operator has to watch both namespaces.
+	// Secrets of the backup namespace are copied into the restore namespace
+	// only if they allow it with AnnotationRestoreAllowedNamespaces,
+	// otherwise they have to be provided with SourceS3CredentialsSecret
+	// and SourceUsersSecret.
+	BackupNamespace string `json:"backupNamespace,omitempty"`
+	// SourceUsersSecret is the secret in the restore namespace with system
+	// users of the cluster the backup was taken from. It's needed if the
+	// internal secret of that cluster is gone or can't be copied.
+	SourceUsersSecret string `json:"sourceUsersSecret,omitempty"`
+	// SourceS3CredentialsSecret is the secret in the restore namespace with
+	// S3 credentials of the backup from another namespace.
+	SourceS3CredentialsSecret string `json:"sourceS3CredentialsSecret,omitempty"`
+}
+
+// AnnotationRestoreAllowedNamespaces lists namespaces (comma separated, "*" for any)
+// the secret can be copied into by restores of backups from another namespace
+const AnnotationRestoreAllowedNamespaces = "percona.com/restore-allowed-namespaces"
+
+// RestoreSafetyNet keeps CSI snapshots of the datadir volumes taken before
+// they are wiped, so a failed or cancelled restore can bring them back.
+// Snapshots are removed together with the restore object.
+type RestoreSafetyNet struct {
+	Enabled                 bool   `json:"enabled,omitempty"`
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
 }
 
 // PerconaXtraDBClusterRestoreStatus defines the observed state of PerconaXtraDBClusterRestore
@@ -20,6 +58,63 @@ type PerconaXtraDBClusterRestoreStatus struct {
 	Comments      string           `json:"comments,omitempty"`
 	CompletedAt   *metav1.Time     `json:"completed,omitempty"`
 	LastScheduled *metav1.Time     `json:"lastscheduled,omitempty"`
+	// PXCSize and AllowUnsafeConfig keep the cluster settings the cluster
+	// is started with after the restore. PITR runs on a single member.
+	PXCSize           int32                `json:"pxcSize,omitempty"`
+	AllowUnsafeConfig bool                 `json:"allowUnsafeConfig,omitempty"`
+	BackupName        string               `json:"backupName,omitempty"`
+	BackupDestination string               `json:"backupDestination,omitempty"`
+	Phases            []RestorePhaseStatus `json:"phases,omitempty"`
+	Progress          *RestoreProgress     `json:"progress,omitempty"`
+	Jobs              []string             `json:"jobs,omitempty"`
+	Pods              []string             `json:"pods,omitempty"`
+	// SavedVolumes are the datadir PVCs the safety net has snapshots of
+	SavedVolumes []string `json:"savedVolumes,omitempty"`
+	// ImportedTables are the tables already restored by the partial restore of a physical backup
+	ImportedTables []string `json:"importedTables,omitempty"`
+}
+
+// RestorePhaseStatus is the timing of one of the restore phases
+type RestorePhaseStatus struct {
+	State    BcpRestoreStates `json:"state"`
+	Started  *metav1.Time     `json:"started,omitempty"`
+	Finished *metav1.Time     `json:"finished,omitempty"`
+}
+
+// RestoreProgress is the progress reported by the restore and PITR jobs
+type RestoreProgress struct {
+	// DownloadedBytes and TotalBytes are reported only by logical restores,
+	// physical restores don't report the progress of the download
+	DownloadedBytes int64  `json:"downloadedBytes,omitempty"`
+	TotalBytes      int64  `json:"totalBytes,omitempty"`
+	Binlog          string `json:"binlog,omitempty"`
+	GTIDSet         string `json:"gtidSet,omitempty"`
+	BinlogsApplied  int    `json:"binlogsApplied,omitempty"`
+	BinlogsTotal    int    `json:"binlogsTotal,omitempty"`
+}
+
+// PhaseStarted returns the start time of the current phase
+func (s *PerconaXtraDBClusterRestoreStatus) PhaseStarted() *metav1.Time {
+	if len(s.Phases) == 0 {
+		return nil
+	}
+
+	return s.Phases[len(s.Phases)-1].Started
+}
+
+// SetState moves the restore to the given state and keeps timings of the phases
+func (s *PerconaXtraDBClusterRestoreStatus) SetState(state BcpRestoreStates, now metav1.Time) {
+	if s.State == state && len(s.Phases) > 0 {
+		return
+	}
+
+	if n := len(s.Phases); n > 0 && s.Phases[n-1].Finished == nil {
+		s.Phases[n-1].Finished = &now
+	}
+	if !state.Finished() {
+		s.Phases = append(s.Phases, RestorePhaseStatus{State: state, Started: &now})
+	}
+	s.State = state
 }
 
 type PITR struct {
@@ -58,11 +153,23 @@ const (
 	RestoreStopCluster  BcpRestoreStates = "Stopping Cluster"
 	RestoreRestore      BcpRestoreStates = "Restoring"
 	RestoreStartCluster BcpRestoreStates = "Starting Cluster"
-	RestorePITR         BcpRestoreStates = "Point-in-time recovering"
-	RestoreFailed       BcpRestoreStates = "Failed"
-	RestoreSucceeded    BcpRestoreStates = "Succeeded"
+	// RestorePrepareCluster starts a single member to apply binlogs on it
+	RestorePrepareCluster BcpRestoreStates = "Preparing Cluster"
+	RestorePITR           BcpRestoreStates = "Point-in-time recovering"
+	RestoreRollback       BcpRestoreStates = "Rolling Back"
+	RestoreFailed         BcpRestoreStates = "Failed"
+	RestoreSucceeded      BcpRestoreStates = "Succeeded"
+	RestoreCancelled      BcpRestoreStates = "Cancelled"
 )
 
+// Finished checks if the restore is over
+func (s BcpRestoreStates) Finished() bool {
+	return s == RestoreSucceeded || s == RestoreFailed || s == RestoreCancelled
+}
+
+// FinalizerRollbackRestore makes the deletion of the unfinished restore wait for the rollback
+const FinalizerRollbackRestore = "rollback-restore"
+
 func (cr *PerconaXtraDBClusterRestore) CheckNsetDefaults() error {
 	if cr.Spec.PXCCluster == "" {
 		return errors.New("pxcCluster can't be empty")
@@ -76,10 +183,23 @@ func (cr *PerconaXtraDBClusterRestore) CheckNsetDefaults() error {
 	if len(cr.Spec.BackupName) > 0 && cr.Spec.BackupSource != nil {
 		return errors.New("backupName and BackupSource can't be specified simultaneously")
 	}
+	for _, t := range cr.Spec.Tables {
+		if spl := strings.Split(t, "."); len(spl) != 2 || spl[0] == "" || spl[1] == "" {
+			return errors.New("tables should be specified in db.table form")
+		}
+	}
+	if cr.Spec.PITR != nil && cr.IsSelective() {
+		return errors.New("PITR can't be used with selective restore")
+	}
 
 	return nil
 }
 
+// IsSelective checks if only a part of the backup should be restored
+func (cr *PerconaXtraDBClusterRestore) IsSelective() bool {
+	return len(cr.Spec.Databases) > 0 || len(cr.Spec.Tables) > 0
+}
+
 func init() {
 	SchemeBuilder.Register(&PerconaXtraDBClusterRestore{}, &PerconaXtraDBClusterRestoreList{})
 }
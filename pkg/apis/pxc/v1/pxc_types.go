@@ -15,6 +15,7 @@ import (
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -34,6 +35,7 @@ type PerconaXtraDBClusterSpec struct {
 	PXC                       *PXCSpec                             `json:"pxc,omitempty"`
 	ProxySQL                  *PodSpec                             `json:"proxysql,omitempty"`
 	HAProxy                   *PodSpec                             `json:"haproxy,omitempty"`
+	Arbitrator                *PodSpec                             `json:"arbitrator,omitempty"`
 	PMM                       *PMMSpec                             `json:"pmm,omitempty"`
 	LogCollector              *LogCollectorSpec                    `json:"logcollector,omitempty"`
 	Backup                    *PXCScheduledBackup                  `json:"backup,omitempty"`
@@ -45,12 +47,113 @@ type PerconaXtraDBClusterSpec struct {
 }
 
 type PXCSpec struct {
-	AutoRecovery        *bool                `json:"autoRecovery,omitempty"`
-	ReplicationChannels []ReplicationChannel `json:"replicationChannels,omitempty"`
-	Expose              ServiceExpose        `json:"expose,omitempty"`
+	AutoRecovery *bool `json:"autoRecovery,omitempty"`
+	// RecoveryPolicy is what the operator does on the full cluster crash.
+	// It's Auto if autoRecovery is true and Disabled otherwise by default.
+	RecoveryPolicy RecoveryPolicy `json:"recoveryPolicy,omitempty"`
+	// NonPrimaryRecovery is what the operator does if no member is in the Primary component
+	NonPrimaryRecovery *NonPrimaryRecoverySpec `json:"nonPrimaryRecovery,omitempty"`
+	// Reseed is what the operator does with a member crash looping on the corrupted datadir
+	Reseed *ReseedSpec `json:"reseed,omitempty"`
+	// ScaleDownPolicy is how members are removed when the size is reduced
+	ScaleDownPolicy *ScaleDownPolicy `json:"scaleDownPolicy,omitempty"`
+	// MembersStatusIntervalSeconds is how often the Galera status of members is collected, 30 by default
+	MembersStatusIntervalSeconds int32                `json:"membersStatusIntervalSeconds,omitempty"`
+	ReplicationChannels          []ReplicationChannel `json:"replicationChannels,omitempty"`
+	Expose                       ServiceExpose        `json:"expose,omitempty"`
 	*PodSpec
 }
 
+type RecoveryPolicy string
+
+const (
+	// RecoveryPolicyAuto bootstraps the cluster from the member with the highest seqno
+	RecoveryPolicyAuto RecoveryPolicy = "Auto"
+	// RecoveryPolicyManual recommends the member to bootstrap from in the status
+	// and waits for the AnnotationBootstrapFrom annotation
+	RecoveryPolicyManual RecoveryPolicy = "Manual"
+	// RecoveryPolicyDisabled leaves the recovery to the user
+	RecoveryPolicyDisabled RecoveryPolicy = "Disabled"
+)
+
+// NonPrimaryRecoverySpec configures the recovery of the cluster split into non-Primary
+// components, e.g. by the network partition. The Primary component is bootstrapped
+// on the most advanced member after the grace period only if the policy is Auto
+// and all members and the arbitrator are running and report they are out of the Primary component.
+type NonPrimaryRecoverySpec struct {
+	Policy             NonPrimaryPolicy `json:"policy,omitempty"`
+	GracePeriodSeconds int32            `json:"gracePeriodSeconds,omitempty"`
+}
+
+type NonPrimaryPolicy string
+
+const (
+	// NonPrimaryPolicyAlert only reports the non-Primary components in the status and events
+	NonPrimaryPolicyAlert NonPrimaryPolicy = "Alert"
+	// NonPrimaryPolicyAuto bootstraps the Primary component on the most advanced member
+	NonPrimaryPolicyAuto NonPrimaryPolicy = "Auto"
+)
+
+const defaultNonPrimaryGracePeriodSeconds = 300
+
+// ReseedSpec configures re-seeding of members which crash loop with a datadir corruption
+// in the logs. The datadir volume of such member is deleted, so the member gets the data
+// with SST, only if the policy is Auto and the rest of the cluster has the quorum.
+type ReseedSpec struct {
+	Policy ReseedPolicy `json:"policy,omitempty"`
+	// RestartThreshold is the number of restarts after which the logs of the member are checked
+	RestartThreshold int32 `json:"restartThreshold,omitempty"`
+	// TimeoutSeconds is how long the re-seeded member has to get synced before the re-seed is failed
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+}
+
+type ReseedPolicy string
+
+const (
+	// ReseedPolicyAlert only reports corrupted members in the status and events
+	ReseedPolicyAlert ReseedPolicy = "Alert"
+	// ReseedPolicyAuto wipes the datadir of the corrupted member
+	ReseedPolicyAuto ReseedPolicy = "Auto"
+)
+
+const (
+	defaultReseedRestartThreshold = 3
+	defaultReseedTimeoutSeconds   = 3600
+)
+
+const defaultMembersStatusIntervalSeconds = 30
+
+// ScaleDownPolicy configures the graceful removal of members on scale down.
+// Members are removed one at a time from the highest ordinal: the member is put into
+// the maintenance mode, so the proxies move the writer and the traffic away from it,
+// its connections are drained and mysqld is stopped, so it leaves the cluster gracefully.
+type ScaleDownPolicy struct {
+	// Volumes is what happens to the datadir volume of the removed member
+	Volumes ScaleDownVolumes `json:"volumes,omitempty"`
+	// DrainTimeoutSeconds is how long the client connections are drained before mysqld is stopped
+	DrainTimeoutSeconds int32 `json:"drainTimeoutSeconds,omitempty"`
+}
+
+type ScaleDownVolumes string
+
+const (
+	// ScaleDownVolumesRetain keeps the volume, the member starts with its old data on scale up
+	ScaleDownVolumesRetain ScaleDownVolumes = "Retain"
+	// ScaleDownVolumesDelete deletes the volume, the member gets the data with SST on scale up
+	ScaleDownVolumesDelete ScaleDownVolumes = "Delete"
+)
+
+const defaultScaleDownDrainTimeoutSeconds = 60
+
+const (
+	// AnnotationBootstrapFrom is the name of the PXC pod the cluster is bootstrapped from
+	// after the full crash. It's removed once the recovery is started.
+	AnnotationBootstrapFrom = "percona.com/bootstrap-from"
+	// AnnotationBootstrapAcceptDataLoss allows to bootstrap the cluster from the pod
+	// which doesn't have the highest seqno, transactions after its seqno are lost.
+	AnnotationBootstrapAcceptDataLoss = "percona.com/bootstrap-accept-data-loss"
+)
+
 type ServiceExpose struct {
 	Enabled                  bool                                    `json:"enabled,omitempty"`
 	Type                     corev1.ServiceType                      `json:"type,omitempty"`
@@ -99,10 +202,13 @@ type PITRSpec struct {
 }
 
 type PXCScheduledBackupSchedule struct {
-	Name        string `json:"name,omitempty"`
-	Schedule    string `json:"schedule,omitempty"`
-	Keep        int    `json:"keep,omitempty"`
-	StorageName string `json:"storageName,omitempty"`
+	Name        string             `json:"name,omitempty"`
+	Schedule    string             `json:"schedule,omitempty"`
+	Keep        int                `json:"keep,omitempty"`
+	StorageName string             `json:"storageName,omitempty"`
+	Type        BackupType         `json:"type,omitempty"`
+	Logical     *LogicalBackupSpec `json:"logical,omitempty"`
+	Hooks       *BackupHooks       `json:"hooks,omitempty"`
 }
 type AppState string
 
@@ -130,6 +236,164 @@ type PerconaXtraDBClusterStatus struct {
 	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
 	Size               int32              `json:"size"`
 	Ready              int32              `json:"ready"`
+	// PVCResize is the progress of the PXC datadir volumes expansion
+	PVCResize *PVCResizeStatus `json:"pvcResize,omitempty"`
+	// VolumeAutoscaling is the datadir usage measured by the volume autoscaling and its last trigger
+	VolumeAutoscaling *VolumeAutoscalingStatus `json:"volumeAutoscaling,omitempty"`
+	// StorageMigration is the progress of moving PXC datadir volumes to another storage class
+	StorageMigration *StorageMigrationStatus `json:"storageMigration,omitempty"`
+	// Members is the Galera status of every PXC pod
+	Members []MemberStatus `json:"members,omitempty"`
+	// MembersCheckedAt is the time the Galera status of members was collected
+	MembersCheckedAt *metav1.Time `json:"membersCheckedAt,omitempty"`
+	// CrashRecovery is the state of PXC members found by the last full crash recovery
+	CrashRecovery *CrashRecoveryStatus `json:"crashRecovery,omitempty"`
+	// NonPrimary is set while some members are out of the Primary component
+	NonPrimary *NonPrimaryStatus `json:"nonPrimary,omitempty"`
+	// Reseed is set while some members crash loop on the corrupted datadir
+	Reseed *ReseedStatus `json:"reseed,omitempty"`
+}
+
+// ReseedStatus is the state of members with the corrupted datadir
+type ReseedStatus struct {
+	Corrupted []CorruptedMember `json:"corrupted,omitempty"`
+	// Pod is the member being re-seeded, PVCUID is its deleted datadir volume
+	Pod       string       `json:"pod,omitempty"`
+	PVCUID    types.UID    `json:"pvcUID,omitempty"`
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+	// Failed is the member which didn't get synced in time after the re-seed,
+	// it isn't re-seeded automatically again
+	Failed  string `json:"failed,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// CorruptedMember is the member crash looping with a datadir corruption in the logs
+type CorruptedMember struct {
+	Pod       string `json:"pod"`
+	Restarts  int32  `json:"restarts"`
+	Signature string `json:"signature"`
+	// LogLine is the line of the log the corruption is recognized by
+	LogLine string `json:"logLine,omitempty"`
+}
+
+// NonPrimaryStatus is the state of the cluster without the Primary component
+type NonPrimaryStatus struct {
+	// Members are members in non-Primary components
+	Members []string     `json:"members,omitempty"`
+	Since   *metav1.Time `json:"since,omitempty"`
+	Message string       `json:"message,omitempty"`
+	// BootstrapPod is the member the Primary component was bootstrapped on
+	BootstrapPod   string       `json:"bootstrapPod,omitempty"`
+	BootstrappedAt *metav1.Time `json:"bootstrappedAt,omitempty"`
+}
+
+// CrashRecoveryStatus is the Galera state of every member after the full cluster crash
+// and the member the cluster is bootstrapped from
+type CrashRecoveryStatus struct {
+	State   CrashRecoveryState    `json:"state,omitempty"`
+	Members []CrashRecoveryMember `json:"members,omitempty"`
+	// RecommendedPod is the member with the highest seqno
+	RecommendedPod string       `json:"recommendedPod,omitempty"`
+	BootstrapPod   string       `json:"bootstrapPod,omitempty"`
+	Seqno          int64        `json:"seqno"`
+	Message        string       `json:"message,omitempty"`
+	StartedAt      *metav1.Time `json:"startedAt,omitempty"`
+}
+
+type CrashRecoveryState string
+
+const (
+	CrashRecoveryWaitingForApproval CrashRecoveryState = "WaitingForApproval"
+	CrashRecoveryBootstrapping      CrashRecoveryState = "Bootstrapping"
+	CrashRecoveryRecovered          CrashRecoveryState = "Recovered"
+)
+
+type CrashRecoveryMember struct {
+	Pod   string `json:"pod"`
+	UUID  string `json:"uuid,omitempty"`
+	Seqno int64  `json:"seqno"`
+}
+
+type MemberRole string
+
+const (
+	MemberRoleWriter MemberRole = "writer"
+	MemberRoleReader MemberRole = "reader"
+)
+
+// MemberStatus is the Galera status of the PXC member
+type MemberStatus struct {
+	Name string `json:"name"`
+	Node string `json:"node,omitempty"`
+	// State is wsrep_local_state_comment of the member, e.g. Synced or Donor/Desynced
+	State string `json:"state,omitempty"`
+	// ClusterStatus is wsrep_cluster_status, Primary if the member is a part of the primary component
+	ClusterStatus string `json:"clusterStatus,omitempty"`
+	// LastCommitted is wsrep_last_committed, the seqno of the last transaction applied by the member
+	LastCommitted int64 `json:"lastCommitted,omitempty"`
+	// FlowControlPaused is wsrep_flow_control_paused, the fraction of time
+	// replication was paused by the flow control since the previous check
+	FlowControlPaused string `json:"flowControlPaused,omitempty"`
+	// Role is the role the member has in HAProxy or ProxySQL
+	Role        MemberRole `json:"role,omitempty"`
+	BackupDonor bool       `json:"backupDonor,omitempty"`
+	Message     string     `json:"message,omitempty"`
+}
+
+type StorageMigrationState string
+
+const (
+	StorageMigrationInProgress StorageMigrationState = "InProgress"
+	StorageMigrationFailed     StorageMigrationState = "Failed"
+	StorageMigrationDone       StorageMigrationState = "Done"
+)
+
+// StorageMigrationStatus reports the rolling migration of datadir volumes.
+// Members are moved one at a time: the PVC is recreated with the new storage
+// class and the member gets the data with SST.
+type StorageMigrationStatus struct {
+	State        StorageMigrationState `json:"state,omitempty"`
+	StorageClass string                `json:"storageClass,omitempty"`
+	Migrated     []string              `json:"migrated,omitempty"`
+	Current      string                `json:"current,omitempty"`
+	Total        int32                 `json:"total"`
+	Message      string                `json:"message,omitempty"`
+	StartedAt    *metav1.Time          `json:"startedAt,omitempty"`
+}
+
+type VolumeAutoscalingStatus struct {
+	LastChecked   *metav1.Time `json:"lastChecked,omitempty"`
+	LastTriggered *metav1.Time `json:"lastTriggered,omitempty"`
+	// Size is the size volumes were grown to by the last trigger
+	Size    string        `json:"size,omitempty"`
+	Message string        `json:"message,omitempty"`
+	Volumes []VolumeUsage `json:"volumes,omitempty"`
+}
+
+// VolumeUsage is the datadir usage of the member
+type VolumeUsage struct {
+	PVC         string `json:"pvc"`
+	UsedBytes   int64  `json:"usedBytes"`
+	TotalBytes  int64  `json:"totalBytes"`
+	UsedPercent int32  `json:"usedPercent"`
+}
+
+type PVCResizeState string
+
+const (
+	PVCResizeInProgress PVCResizeState = "InProgress"
+	PVCResizeFailed     PVCResizeState = "Failed"
+	PVCResizeDone       PVCResizeState = "Done"
+)
+
+// PVCResizeStatus reports the expansion of existing PVCs to the size requested in the volume spec
+type PVCResizeStatus struct {
+	State     PVCResizeState `json:"state,omitempty"`
+	Size      string         `json:"size,omitempty"`
+	Resized   int32          `json:"resized"`
+	Total     int32          `json:"total"`
+	Message   string         `json:"message,omitempty"`
+	StartedAt *metav1.Time   `json:"startedAt,omitempty"`
 }
 
 type ConditionStatus string
@@ -140,12 +404,36 @@ const (
 	ConditionUnknown ConditionStatus = "Unknown"
 )
 
+type ClusterConditionType string
+
+const (
+	// ConditionPXCReady is true when all PXC members are ready
+	ConditionPXCReady ClusterConditionType = "PXCReady"
+	// ConditionProxyReady is true when all HAProxy or ProxySQL pods are ready
+	ConditionProxyReady ClusterConditionType = "ProxyReady"
+	// ConditionBackupsHealthy is true when the latest finished backup of the cluster succeeded
+	ConditionBackupsHealthy ClusterConditionType = "BackupsHealthy"
+	// ConditionPITRHealthy is true when the binlog collector is running
+	ConditionPITRHealthy ClusterConditionType = "PITRHealthy"
+	// ConditionTLSValid is true when TLS certificates of the cluster exist and aren't expired
+	ConditionTLSValid ClusterConditionType = "TLSValid"
+	// ConditionUpgradeInProgress is true while pods are updated to the new revision
+	ConditionUpgradeInProgress ClusterConditionType = "UpgradeInProgress"
+	// ConditionFullCrashRecovery is true while the cluster recovers from the full crash
+	ConditionFullCrashRecovery ClusterConditionType = "FullCrashRecovery"
+	// ConditionReconciled is false when the last reconcile of the cluster failed, the message has the error
+	ConditionReconciled ClusterConditionType = "Reconciled"
+)
+
+// ClusterCondition follows the semantics of the standard Kubernetes conditions:
+// there is one condition of every type and its status is independent of others.
 type ClusterCondition struct {
-	Status             ConditionStatus `json:"status,omitempty"`
-	Type               AppState        `json:"type,omitempty"`
-	LastTransitionTime metav1.Time     `json:"lastTransitionTime,omitempty"`
-	Reason             string          `json:"reason,omitempty"`
-	Message            string          `json:"message,omitempty"`
+	Type               ClusterConditionType `json:"type"`
+	Status             ConditionStatus      `json:"status"`
+	ObservedGeneration int64                `json:"observedGeneration,omitempty"`
+	LastTransitionTime metav1.Time          `json:"lastTransitionTime,omitempty"`
+	Reason             string               `json:"reason,omitempty"`
+	Message            string               `json:"message,omitempty"`
 }
 
 type AppStatus struct {
@@ -185,6 +473,70 @@ func (cr *PerconaXtraDBCluster) Validate() error {
 		c.PXC.AutoRecovery = &boolVar
 	}
 
+	if c.PXC.NonPrimaryRecovery == nil {
+		c.PXC.NonPrimaryRecovery = &NonPrimaryRecoverySpec{}
+	}
+	switch c.PXC.NonPrimaryRecovery.Policy {
+	case "":
+		c.PXC.NonPrimaryRecovery.Policy = NonPrimaryPolicyAlert
+	case NonPrimaryPolicyAlert, NonPrimaryPolicyAuto:
+	default:
+		return errors.Errorf("unknown pxc.nonPrimaryRecovery.policy %s, it can be %s or %s",
+			c.PXC.NonPrimaryRecovery.Policy, NonPrimaryPolicyAlert, NonPrimaryPolicyAuto)
+	}
+	if c.PXC.NonPrimaryRecovery.GracePeriodSeconds <= 0 {
+		c.PXC.NonPrimaryRecovery.GracePeriodSeconds = defaultNonPrimaryGracePeriodSeconds
+	}
+
+	if c.PXC.Reseed == nil {
+		c.PXC.Reseed = &ReseedSpec{}
+	}
+	switch c.PXC.Reseed.Policy {
+	case "":
+		c.PXC.Reseed.Policy = ReseedPolicyAlert
+	case ReseedPolicyAlert, ReseedPolicyAuto:
+	default:
+		return errors.Errorf("unknown pxc.reseed.policy %s, it can be %s or %s",
+			c.PXC.Reseed.Policy, ReseedPolicyAlert, ReseedPolicyAuto)
+	}
+	if c.PXC.Reseed.RestartThreshold <= 0 {
+		c.PXC.Reseed.RestartThreshold = defaultReseedRestartThreshold
+	}
+	if c.PXC.Reseed.TimeoutSeconds <= 0 {
+		c.PXC.Reseed.TimeoutSeconds = defaultReseedTimeoutSeconds
+	}
+
+	if c.PXC.ScaleDownPolicy == nil {
+		c.PXC.ScaleDownPolicy = &ScaleDownPolicy{}
+	}
+	switch c.PXC.ScaleDownPolicy.Volumes {
+	case "":
+		c.PXC.ScaleDownPolicy.Volumes = ScaleDownVolumesRetain
+	case ScaleDownVolumesRetain, ScaleDownVolumesDelete:
+	default:
+		return errors.Errorf("unknown pxc.scaleDownPolicy.volumes %s, it can be %s or %s",
+			c.PXC.ScaleDownPolicy.Volumes, ScaleDownVolumesRetain, ScaleDownVolumesDelete)
+	}
+	if c.PXC.ScaleDownPolicy.DrainTimeoutSeconds <= 0 {
+		c.PXC.ScaleDownPolicy.DrainTimeoutSeconds = defaultScaleDownDrainTimeoutSeconds
+	}
+
+	if c.PXC.MembersStatusIntervalSeconds <= 0 {
+		c.PXC.MembersStatusIntervalSeconds = defaultMembersStatusIntervalSeconds
+	}
+
+	switch c.PXC.RecoveryPolicy {
+	case "":
+		c.PXC.RecoveryPolicy = RecoveryPolicyDisabled
+		if *c.PXC.AutoRecovery {
+			c.PXC.RecoveryPolicy = RecoveryPolicyAuto
+		}
+	case RecoveryPolicyAuto, RecoveryPolicyManual, RecoveryPolicyDisabled:
+	default:
+		return errors.Errorf("unknown pxc.recoveryPolicy %s, it can be %s, %s or %s",
+			c.PXC.RecoveryPolicy, RecoveryPolicyAuto, RecoveryPolicyManual, RecoveryPolicyDisabled)
+	}
+
 	if c.PXC.Image == "" {
 		return errors.New("pxc.Image can't be empty")
 	}
@@ -236,6 +588,16 @@ func (cr *PerconaXtraDBCluster) Validate() error {
 		}
 	}
 
+	if c.Arbitrator != nil && c.Arbitrator.Enabled {
+		// garbd is shipped in the backup image
+		if c.Arbitrator.Image == "" && c.Backup != nil {
+			c.Arbitrator.Image = c.Backup.Image
+		}
+		if c.Arbitrator.Image == "" {
+			return errors.New("arbitrator.Image can't be empty")
+		}
+	}
+
 	if c.Backup != nil {
 		if c.Backup.Image == "" {
 			return errors.New("backup.Image can't be empty")
@@ -249,11 +611,27 @@ func (cr *PerconaXtraDBCluster) Validate() error {
 				return errors.Errorf("pitr storage %s doesn't exist", cr.Spec.Backup.PITR.StorageName)
 			}
 		}
+		for name, strg := range c.Backup.Storages {
+			if strg == nil {
+				continue
+			}
+			if err := strg.Hooks.Validate(); err != nil {
+				return errors.Wrapf(err, "backup storage %s", name)
+			}
+		}
 		for _, sch := range c.Backup.Schedule {
 			strg, ok := cr.Spec.Backup.Storages[sch.StorageName]
 			if !ok {
 				return errors.Errorf("storage %s doesn't exist", sch.StorageName)
 			}
+			if err := sch.Hooks.Validate(); err != nil {
+				return errors.Wrapf(err, "backup schedule %s", sch.Name)
+			}
+			if strg.Type == BackupStorageS3 && sch.Type != BackupTypeLogical {
+				if err := strg.S3.CheckPhysical(); err != nil {
+					return errors.Wrapf(err, "backup schedule %s", sch.Name)
+				}
+			}
 			if strg.Type == BackupStorageFilesystem {
 				if strg.Volume == nil {
 					return errors.Errorf("backup storage %s: volume should be specified", sch.StorageName)
@@ -392,6 +770,25 @@ type BackupStorageSpec struct {
 	PodSecurityContext       *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
 	ContainerSecurityContext *corev1.SecurityContext    `json:"containerSecurityContext,omitempty"`
 	RuntimeClassName         *string                    `json:"runtimeClassName,omitempty"`
+	Hooks                    *BackupHooks               `json:"hooks,omitempty"`
+	Catalog                  *BackupCatalogSpec         `json:"catalog,omitempty"`
+	Snapshot                 *BackupStorageSnapshotSpec `json:"snapshot,omitempty"`
+}
+
+// BackupStorageSnapshotSpec configures CSI snapshots of the datadir volumes.
+// The default VolumeSnapshotClass is used if the class name isn't set.
+type BackupStorageSnapshotSpec struct {
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
+}
+
+// BackupCatalogSpec configures import of backups found on the storage.
+// Operator creates read-only backup objects for the backups it doesn't know about.
+type BackupCatalogSpec struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// Prefix inside the bucket to scan, the bucket path is used if empty
+	Prefix string `json:"prefix,omitempty"`
+	// IntervalSeconds between storage scans
+	IntervalSeconds int32 `json:"intervalSeconds,omitempty"`
 }
 
 type BackupStorageType string
@@ -399,6 +796,8 @@ type BackupStorageType string
 const (
 	BackupStorageFilesystem BackupStorageType = "filesystem"
 	BackupStorageS3         BackupStorageType = "s3"
+	// BackupStorageSnapshot is a CSI VolumeSnapshot of the datadir of one of PXC members
+	BackupStorageSnapshot BackupStorageType = "snapshot"
 )
 
 const (
@@ -406,12 +805,28 @@ const (
 )
 
 type BackupStorageS3Spec struct {
-	Bucket            string `json:"bucket"`
-	CredentialsSecret string `json:"credentialsSecret"`
+	Bucket string `json:"bucket"`
+	// CredentialsSecret holds AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+	// If it's empty, the operator, the binlog collector and PITR rely on the default
+	// AWS credentials chain (e.g. IRSA role of the service account or instance metadata),
+	// xbcloud of logical backups and restores gets credentials from the instance metadata only.
+	// Physical backups and restores require it, see CheckPhysical.
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
 	Region            string `json:"region,omitempty"`
 	EndpointURL       string `json:"endpointUrl,omitempty"`
 }
 
+// CheckPhysical checks if physical backups can be stored on or restored from the storage.
+// They are made by backup.sh and recovery-s3.sh of the backup image,
+// which pass only static keys to xbcloud and mc.
+func (s *BackupStorageS3Spec) CheckPhysical() error {
+	if s.CredentialsSecret == "" {
+		return errors.Errorf("s3 bucket %s: credentialsSecret is required for physical backups", s.Bucket)
+	}
+
+	return nil
+}
+
 type VolumeSpec struct {
 	// EmptyDir to use as data volume for mysql. EmptyDir represents a temporary
 	// directory that shares a pod's lifetime.
@@ -429,6 +844,25 @@ type VolumeSpec struct {
 	// EmptyDir. And represents the PVC specification.
 	// +optional
 	PersistentVolumeClaim *corev1.PersistentVolumeClaimSpec `json:"persistentVolumeClaim,omitempty"`
+
+	// Autoscaling grows PersistentVolumeClaim volumes before they fill.
+	// +optional
+	Autoscaling *VolumeAutoscaling `json:"autoscaling,omitempty"`
+}
+
+// VolumeAutoscaling makes the operator check the datadir usage of every member
+// and grow the storage request of the volume spec by GrowthStep once any of them
+// is used above ThresholdPercent. The storage class has to allow volume expansion.
+type VolumeAutoscaling struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// ThresholdPercent is the datadir usage that triggers the growth, 80 by default
+	ThresholdPercent int32 `json:"thresholdPercent,omitempty"`
+	// GrowthStep is added to the volume size, a quarter of the current size by default
+	GrowthStep *resource.Quantity `json:"growthStep,omitempty"`
+	// MaxSize is the size volumes are never grown above
+	MaxSize *resource.Quantity `json:"maxSize,omitempty"`
+	// CheckIntervalSeconds is how often the usage is measured, 60 by default
+	CheckIntervalSeconds int32 `json:"checkIntervalSeconds,omitempty"`
 }
 
 type Volume struct {
@@ -586,6 +1020,17 @@ func (cr *PerconaXtraDBCluster) CheckNSetDefaults(serverVersion *version.ServerV
 		}
 	}
 
+	if c.Arbitrator != nil && c.Arbitrator.Enabled {
+		if len(c.Arbitrator.ImagePullPolicy) == 0 {
+			c.Arbitrator.ImagePullPolicy = corev1.PullAlways
+		}
+		if len(c.Arbitrator.ServiceAccountName) == 0 {
+			c.Arbitrator.ServiceAccountName = workloadSA
+		}
+		c.Arbitrator.Size = 1
+		c.Arbitrator.reconcileAffinityOpts()
+	}
+
 	if c.HAProxy != nil && c.HAProxy.Enabled {
 		if len(c.HAProxy.ImagePullPolicy) == 0 {
 			c.HAProxy.ImagePullPolicy = corev1.PullAlways
@@ -708,41 +1153,68 @@ const (
 )
 
 func setSafeDefaults(spec *PerconaXtraDBClusterSpec, log logr.Logger) {
+	for _, msg := range applySafeDefaults(spec) {
+		log.Info(msg)
+		log.Info("Set allowUnsafeConfigurations=true to disable safe configuration")
+	}
+}
+
+// SafeConfigOverrides returns the changes the safe configuration makes to the cluster spec.
+// The spec itself isn't changed.
+func (cr *PerconaXtraDBCluster) SafeConfigOverrides() []string {
+	return applySafeDefaults(cr.Spec.DeepCopy())
+}
+
+// applySafeDefaults changes the spec to the safe configuration and describes every change
+func applySafeDefaults(spec *PerconaXtraDBClusterSpec) []string {
 	if spec.AllowUnsafeConfig {
-		return
+		return nil
 	}
 
-	loginfo := func(msg string, args ...interface{}) {
-		log.Info(fmt.Sprintf(msg, args...))
-		log.Info("Set allowUnsafeConfigurations=true to disable safe configuration")
+	var changes []string
+	change := func(msg string, args ...interface{}) {
+		changes = append(changes, fmt.Sprintf(msg, args...))
 	}
 
-	if spec.PXC.Size < 3 {
-		loginfo("Cluster size will be changed from %d to %d due to safe config", spec.PXC.Size, 3)
-		spec.PXC.Size = 3
+	// the arbitrator votes as a member, so two PXC members with it have the quorum
+	arbitrator := spec.Arbitrator != nil && spec.Arbitrator.Enabled
+	minSize := int32(3)
+	if arbitrator {
+		minSize = 2
+	}
+
+	if spec.PXC.Size < minSize {
+		change("Cluster size will be changed from %d to %d due to safe config", spec.PXC.Size, minSize)
+		spec.PXC.Size = minSize
 	} else if spec.PXC.Size > maxSafePXCSize {
-		loginfo("Cluster size will be changed from %d to %d due to safe config", spec.PXC.Size, maxSafePXCSize)
+		change("Cluster size will be changed from %d to %d due to safe config", spec.PXC.Size, maxSafePXCSize)
 		spec.PXC.Size = maxSafePXCSize
 	}
 
-	if spec.PXC.Size%2 == 0 {
-		loginfo("Cluster size will be changed from %d to %d due to safe config", spec.PXC.Size, spec.PXC.Size+1)
+	// members aren't resized because of the arbitrator, it isn't deployed if it makes the votes even
+	if arbitrator && spec.PXC.Size%2 == 1 {
+		change("Arbitrator will be disabled due to safe config, %d members with it have an even number of votes", spec.PXC.Size)
+		spec.Arbitrator.Enabled = false
+	} else if !arbitrator && spec.PXC.Size%2 == 0 {
+		change("Cluster size will be changed from %d to %d due to safe config", spec.PXC.Size, spec.PXC.Size+1)
 		spec.PXC.Size++
 	}
 
 	if spec.ProxySQL != nil && spec.ProxySQL.Enabled {
 		if spec.ProxySQL.Size < minSafeProxySize {
-			loginfo("ProxySQL size will be changed from %d to %d due to safe config", spec.ProxySQL.Size, minSafeProxySize)
+			change("ProxySQL size will be changed from %d to %d due to safe config", spec.ProxySQL.Size, minSafeProxySize)
 			spec.ProxySQL.Size = minSafeProxySize
 		}
 	}
 
 	if spec.HAProxy != nil && spec.HAProxy.Enabled {
 		if spec.HAProxy.Size < minSafeProxySize {
-			loginfo("HAProxy size will be changed from %d to %d due to safe config", spec.HAProxy.Size, minSafeProxySize)
+			change("HAProxy size will be changed from %d to %d due to safe config", spec.HAProxy.Size, minSafeProxySize)
 			spec.HAProxy.Size = minSafeProxySize
 		}
 	}
+
+	return changes
 }
 
 // setVersion sets the API version of a PXC resource.
@@ -861,6 +1333,21 @@ func (v *VolumeSpec) validate() error {
 			return errors.New("volume.resources.storage can't be empty")
 		}
 	}
+
+	if v.Autoscaling != nil && v.Autoscaling.Enabled {
+		if v.PersistentVolumeClaim == nil {
+			return errors.New("volume autoscaling requires persistentVolumeClaim")
+		}
+		if v.Autoscaling.ThresholdPercent == 0 {
+			v.Autoscaling.ThresholdPercent = 80
+		}
+		if v.Autoscaling.ThresholdPercent < 0 || v.Autoscaling.ThresholdPercent > 100 {
+			return errors.New("volume autoscaling thresholdPercent should be between 1 and 100")
+		}
+		if v.Autoscaling.CheckIntervalSeconds == 0 {
+			v.Autoscaling.CheckIntervalSeconds = 60
+		}
+	}
 	return nil
 }
 
@@ -918,6 +1405,12 @@ func (cr *PerconaXtraDBCluster) HAProxyEnabled() bool {
 	return cr.Spec.HAProxy != nil && cr.Spec.HAProxy.Enabled
 }
 
+// ArbitratorEnabled checks if the cluster has the Galera arbitrator (garbd),
+// a member without data which keeps the quorum of two PXC members
+func (cr *PerconaXtraDBCluster) ArbitratorEnabled() bool {
+	return cr.Spec.Arbitrator != nil && cr.Spec.Arbitrator.Enabled
+}
+
 func (cr *PerconaXtraDBCluster) ProxySQLEnabled() bool {
 	return cr.Spec.ProxySQL != nil && cr.Spec.ProxySQL.Enabled
 }
@@ -941,21 +1434,51 @@ func (s *PerconaXtraDBClusterStatus) ClusterStatus(inProgress, deleted bool) App
 	}
 }
 
-const maxStatusesQuantity = 20
+// SetCondition adds the condition or updates the existing one of the same type.
+// The transition time is changed only if the status of the condition is changed.
+func (s *PerconaXtraDBClusterStatus) SetCondition(c ClusterCondition) {
+	if c.LastTransitionTime.IsZero() {
+		c.LastTransitionTime = metav1.Now()
+	}
 
-func (s *PerconaXtraDBClusterStatus) AddCondition(c ClusterCondition) {
-	if len(s.Conditions) == 0 {
+	existing := s.FindCondition(c.Type)
+	if existing == nil {
 		s.Conditions = append(s.Conditions, c)
 		return
 	}
 
-	if s.Conditions[len(s.Conditions)-1].Type != c.Type {
-		s.Conditions = append(s.Conditions, c)
+	if existing.Status == c.Status {
+		c.LastTransitionTime = existing.LastTransitionTime
+	}
+	*existing = c
+}
+
+// FindCondition returns the condition of the given type or nil if there is no such condition
+func (s *PerconaXtraDBClusterStatus) FindCondition(t ClusterConditionType) *ClusterCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == t {
+			return &s.Conditions[i]
+		}
 	}
 
-	if len(s.Conditions) > maxStatusesQuantity {
-		s.Conditions = s.Conditions[len(s.Conditions)-maxStatusesQuantity:]
+	return nil
+}
+
+// RemoveCondition removes the condition of the given type
+func (s *PerconaXtraDBClusterStatus) RemoveCondition(t ClusterConditionType) {
+	conditions := s.Conditions[:0]
+	for _, c := range s.Conditions {
+		if c.Type != t {
+			conditions = append(conditions, c)
+		}
 	}
+	s.Conditions = conditions
+}
+
+// IsConditionTrue reports if the condition of the given type exists and is true
+func (s *PerconaXtraDBClusterStatus) IsConditionTrue(t ClusterConditionType) bool {
+	c := s.FindCondition(t)
+	return c != nil && c.Status == ConditionTrue
 }
 
 func (cr *PerconaXtraDBCluster) CanBackup() error {
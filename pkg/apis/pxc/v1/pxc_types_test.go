@@ -2,9 +2,12 @@ package v1
 
 import (
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestReconcileAffinity(t *testing.T) {
@@ -75,3 +78,147 @@ func TestReconcileAffinity(t *testing.T) {
 		}
 	}
 }
+
+func TestSetCondition(t *testing.T) {
+	s := PerconaXtraDBClusterStatus{}
+	s.SetCondition(ClusterCondition{Type: ConditionPXCReady, Status: ConditionFalse, Reason: "PodsNotReady"})
+	s.SetCondition(ClusterCondition{Type: ConditionTLSValid, Status: ConditionTrue})
+	transition := s.FindCondition(ConditionPXCReady).LastTransitionTime
+
+	s.SetCondition(ClusterCondition{Type: ConditionPXCReady, Status: ConditionFalse, Reason: "Paused"})
+	c := s.FindCondition(ConditionPXCReady)
+	if len(s.Conditions) != 2 || c.Reason != "Paused" || !c.LastTransitionTime.Equal(&transition) {
+		t.Errorf("unexpected conditions after the same status is set: %+v", s.Conditions)
+	}
+
+	s.SetCondition(ClusterCondition{Type: ConditionPXCReady, Status: ConditionTrue, LastTransitionTime: metav1.NewTime(transition.Add(time.Minute))})
+	if !s.IsConditionTrue(ConditionPXCReady) || s.FindCondition(ConditionPXCReady).LastTransitionTime.Equal(&transition) {
+		t.Errorf("transition time isn't updated on status change: %+v", s.Conditions)
+	}
+
+	s.RemoveCondition(ConditionTLSValid)
+	if len(s.Conditions) != 1 || s.FindCondition(ConditionTLSValid) != nil {
+		t.Errorf("condition isn't removed: %+v", s.Conditions)
+	}
+}
+
+func TestSafeConfigOverrides(t *testing.T) {
+	cr := &PerconaXtraDBCluster{Spec: PerconaXtraDBClusterSpec{
+		PXC:     &PXCSpec{PodSpec: &PodSpec{Size: 2}},
+		HAProxy: &PodSpec{Enabled: true, Size: 1},
+	}}
+
+	overrides := cr.SafeConfigOverrides()
+	if len(overrides) != 2 {
+		t.Errorf("expected overrides of PXC and HAProxy sizes, got %v", overrides)
+	}
+	if cr.Spec.PXC.Size != 2 || cr.Spec.HAProxy.Size != 1 {
+		t.Error("spec is changed")
+	}
+
+	cr.Spec.AllowUnsafeConfig = true
+	if overrides := cr.SafeConfigOverrides(); len(overrides) != 0 {
+		t.Errorf("expected no overrides with unsafe config, got %v", overrides)
+	}
+}
+
+func TestOperationCheckNsetDefaults(t *testing.T) {
+	cases := []struct {
+		name  string
+		spec  PerconaXtraDBClusterOperationSpec
+		valid bool
+	}{
+		{"restart", PerconaXtraDBClusterOperationSpec{PXCCluster: "c", Type: OperationRestartMember, Member: "c-pxc-1"}, true},
+		{"restart without member", PerconaXtraDBClusterOperationSpec{PXCCluster: "c", Type: OperationForceSST}, false},
+		{"no cluster", PerconaXtraDBClusterOperationSpec{Type: OperationFlushLogs}, false},
+		{"tables", PerconaXtraDBClusterOperationSpec{PXCCluster: "c", Type: OperationOptimizeTables, Tables: []string{"db.t"}}, true},
+		{"wrong table", PerconaXtraDBClusterOperationSpec{PXCCluster: "c", Type: OperationAnalyzeTables, Tables: []string{"t"}}, false},
+		{"kill without time", PerconaXtraDBClusterOperationSpec{PXCCluster: "c", Type: OperationKillQueries}, false},
+		{"unknown type", PerconaXtraDBClusterOperationSpec{PXCCluster: "c", Type: "Reboot"}, false},
+	}
+
+	for _, c := range cases {
+		op := PerconaXtraDBClusterOperation{Spec: c.spec}
+		err := op.CheckNsetDefaults()
+		if (err == nil) != c.valid {
+			t.Errorf("%s: unexpected result: %v", c.name, err)
+		}
+	}
+}
+
+func TestSafeConfigWithArbitrator(t *testing.T) {
+	tests := []struct {
+		size       int32
+		expected   int32
+		arbitrator bool
+	}{
+		{1, 2, true},
+		{2, 2, true},
+		{3, 3, false},
+		{4, 4, true},
+		{5, 5, false},
+		{6, 5, false},
+	}
+
+	for _, tt := range tests {
+		spec := &PerconaXtraDBClusterSpec{
+			PXC:        &PXCSpec{PodSpec: &PodSpec{Size: tt.size}},
+			Arbitrator: &PodSpec{Enabled: true},
+		}
+		applySafeDefaults(spec)
+		if spec.PXC.Size != tt.expected {
+			t.Errorf("size %d with arbitrator: expected %d, got %d", tt.size, tt.expected, spec.PXC.Size)
+		}
+		if spec.Arbitrator.Enabled != tt.arbitrator {
+			t.Errorf("size %d with arbitrator: expected the arbitrator enabled %t, got %t", tt.size, tt.arbitrator, spec.Arbitrator.Enabled)
+		}
+	}
+}
+
+func TestValidatePhysicalS3Credentials(t *testing.T) {
+	tests := []struct {
+		name       string
+		secret     string
+		backupType BackupType
+		valid      bool
+	}{
+		{"physical with credentials", "my-cluster-name-backup-s3", "", true},
+		{"physical without credentials", "", "", false},
+		{"explicit physical without credentials", "", BackupTypePhysical, false},
+		{"logical without credentials", "", BackupTypeLogical, true},
+	}
+
+	for _, tt := range tests {
+		cr := &PerconaXtraDBCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster1"},
+			Spec: PerconaXtraDBClusterSpec{
+				PXC: &PXCSpec{PodSpec: &PodSpec{
+					Enabled:    true,
+					Size:       3,
+					Image:      "percona/percona-xtradb-cluster:8.0",
+					VolumeSpec: &VolumeSpec{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+				}},
+				Backup: &PXCScheduledBackup{
+					Image: "percona/percona-xtradb-cluster-operator:1.9.0-pxc8.0-backup",
+					Storages: map[string]*BackupStorageSpec{
+						"s3-us-west": {
+							Type: BackupStorageS3,
+							S3:   BackupStorageS3Spec{Bucket: "bucket", CredentialsSecret: tt.secret},
+						},
+					},
+					Schedule: []PXCScheduledBackupSchedule{
+						{Name: "daily", Schedule: "0 0 * * *", StorageName: "s3-us-west", Type: tt.backupType},
+					},
+				},
+			},
+		}
+
+		err := cr.Validate()
+		if valid := err == nil; valid != tt.valid {
+			t.Errorf("%s: expected valid %t, got %v", tt.name, tt.valid, err)
+		}
+		if err != nil && !strings.Contains(err.Error(), "credentialsSecret") {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+	}
+}
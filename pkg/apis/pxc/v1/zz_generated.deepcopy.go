@@ -27,6 +27,119 @@ func (in *AppStatus) DeepCopy() *AppStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupCatalogSpec) DeepCopyInto(out *BackupCatalogSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupCatalogSpec.
+func (in *BackupCatalogSpec) DeepCopy() *BackupCatalogSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupCatalogSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupHook) DeepCopyInto(out *BackupHook) {
+	*out = *in
+	if in.SQL != nil {
+		in, out := &in.SQL, &out.SQL
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exec != nil {
+		in, out := &in.Exec, &out.Exec
+		*out = new(BackupHookExec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupHook.
+func (in *BackupHook) DeepCopy() *BackupHook {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupHookExec) DeepCopyInto(out *BackupHookExec) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupHookExec.
+func (in *BackupHookExec) DeepCopy() *BackupHookExec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupHookExec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupHookStatus) DeepCopyInto(out *BackupHookStatus) {
+	*out = *in
+	if in.Executed != nil {
+		in, out := &in.Executed, &out.Executed
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupHookStatus.
+func (in *BackupHookStatus) DeepCopy() *BackupHookStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupHookStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupHooks) DeepCopyInto(out *BackupHooks) {
+	*out = *in
+	if in.Pre != nil {
+		in, out := &in.Pre, &out.Pre
+		*out = make([]BackupHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Post != nil {
+		in, out := &in.Post, &out.Post
+		*out = make([]BackupHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupHooks.
+func (in *BackupHooks) DeepCopy() *BackupHooks {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupHooks)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupStorageS3Spec) DeepCopyInto(out *BackupStorageS3Spec) {
 	*out = *in
@@ -43,6 +156,22 @@ func (in *BackupStorageS3Spec) DeepCopy() *BackupStorageS3Spec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupStorageSnapshotSpec) DeepCopyInto(out *BackupStorageSnapshotSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStorageSnapshotSpec.
+func (in *BackupStorageSnapshotSpec) DeepCopy() *BackupStorageSnapshotSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupStorageSnapshotSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupStorageSpec) DeepCopyInto(out *BackupStorageSpec) {
 	*out = *in
@@ -105,6 +234,21 @@ func (in *BackupStorageSpec) DeepCopyInto(out *BackupStorageSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = new(BackupHooks)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Catalog != nil {
+		in, out := &in.Catalog, &out.Catalog
+		*out = new(BackupCatalogSpec)
+		**out = **in
+	}
+	if in.Snapshot != nil {
+		in, out := &in.Snapshot, &out.Snapshot
+		*out = new(BackupStorageSnapshotSpec)
+		**out = **in
+	}
 	return
 }
 
@@ -135,6 +279,63 @@ func (in *ClusterCondition) DeepCopy() *ClusterCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CorruptedMember) DeepCopyInto(out *CorruptedMember) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CorruptedMember.
+func (in *CorruptedMember) DeepCopy() *CorruptedMember {
+	if in == nil {
+		return nil
+	}
+	out := new(CorruptedMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrashRecoveryMember) DeepCopyInto(out *CrashRecoveryMember) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrashRecoveryMember.
+func (in *CrashRecoveryMember) DeepCopy() *CrashRecoveryMember {
+	if in == nil {
+		return nil
+	}
+	out := new(CrashRecoveryMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrashRecoveryStatus) DeepCopyInto(out *CrashRecoveryStatus) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]CrashRecoveryMember, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrashRecoveryStatus.
+func (in *CrashRecoveryStatus) DeepCopy() *CrashRecoveryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CrashRecoveryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LogCollectorSpec) DeepCopyInto(out *LogCollectorSpec) {
 	*out = *in
@@ -166,6 +367,104 @@ func (in *LogCollectorSpec) DeepCopy() *LogCollectorSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogicalBackupSpec) DeepCopyInto(out *LogicalBackupSpec) {
+	*out = *in
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogicalBackupSpec.
+func (in *LogicalBackupSpec) DeepCopy() *LogicalBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LogicalBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemberStatus) DeepCopyInto(out *MemberStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemberStatus.
+func (in *MemberStatus) DeepCopy() *MemberStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MemberStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NonPrimaryRecoverySpec) DeepCopyInto(out *NonPrimaryRecoverySpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NonPrimaryRecoverySpec.
+func (in *NonPrimaryRecoverySpec) DeepCopy() *NonPrimaryRecoverySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NonPrimaryRecoverySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NonPrimaryStatus) DeepCopyInto(out *NonPrimaryStatus) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Since != nil {
+		in, out := &in.Since, &out.Since
+		*out = (*in).DeepCopy()
+	}
+	if in.BootstrappedAt != nil {
+		in, out := &in.BootstrappedAt, &out.BootstrappedAt
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NonPrimaryStatus.
+func (in *NonPrimaryStatus) DeepCopy() *NonPrimaryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NonPrimaryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationResult) DeepCopyInto(out *OperationResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationResult.
+func (in *OperationResult) DeepCopy() *OperationResult {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PITR) DeepCopyInto(out *PITR) {
 	*out = *in
@@ -239,9 +538,39 @@ func (in *PMMSpec) DeepCopy() *PMMSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVCResizeStatus) DeepCopyInto(out *PVCResizeStatus) {
+	*out = *in
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCResizeStatus.
+func (in *PVCResizeStatus) DeepCopy() *PVCResizeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PVCResizeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PXCBackupSpec) DeepCopyInto(out *PXCBackupSpec) {
 	*out = *in
+	if in.Logical != nil {
+		in, out := &in.Logical, &out.Logical
+		*out = new(LogicalBackupSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = new(BackupHooks)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -258,6 +587,10 @@ func (in *PXCBackupSpec) DeepCopy() *PXCBackupSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PXCBackupStatus) DeepCopyInto(out *PXCBackupStatus) {
 	*out = *in
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
 	if in.CompletedAt != nil {
 		in, out := &in.CompletedAt, &out.CompletedAt
 		*out = (*in).DeepCopy()
@@ -271,6 +604,13 @@ func (in *PXCBackupStatus) DeepCopyInto(out *PXCBackupStatus) {
 		*out = new(BackupStorageS3Spec)
 		**out = **in
 	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]BackupHookStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -295,7 +635,9 @@ func (in *PXCScheduledBackup) DeepCopyInto(out *PXCScheduledBackup) {
 	if in.Schedule != nil {
 		in, out := &in.Schedule, &out.Schedule
 		*out = make([]PXCScheduledBackupSchedule, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Storages != nil {
 		in, out := &in.Storages, &out.Storages
@@ -336,6 +678,16 @@ func (in *PXCScheduledBackup) DeepCopy() *PXCScheduledBackup {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PXCScheduledBackupSchedule) DeepCopyInto(out *PXCScheduledBackupSchedule) {
 	*out = *in
+	if in.Logical != nil {
+		in, out := &in.Logical, &out.Logical
+		*out = new(LogicalBackupSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = new(BackupHooks)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -357,6 +709,27 @@ func (in *PXCSpec) DeepCopyInto(out *PXCSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.NonPrimaryRecovery != nil {
+		in, out := &in.NonPrimaryRecovery, &out.NonPrimaryRecovery
+		*out = new(NonPrimaryRecoverySpec)
+		**out = **in
+	}
+	if in.Reseed != nil {
+		in, out := &in.Reseed, &out.Reseed
+		*out = new(ReseedSpec)
+		**out = **in
+	}
+	if in.ScaleDownPolicy != nil {
+		in, out := &in.ScaleDownPolicy, &out.ScaleDownPolicy
+		*out = new(ScaleDownPolicy)
+		**out = **in
+	}
+	if in.ReplicationChannels != nil {
+		in, out := &in.ReplicationChannels, &out.ReplicationChannels
+		*out = make([]ReplicationChannel, len(*in))
+		copy(*out, *in)
+	}
+	in.Expose.DeepCopyInto(&out.Expose)
 	if in.PodSpec != nil {
 		in, out := &in.PodSpec, &out.PodSpec
 		*out = new(PodSpec)
@@ -408,7 +781,7 @@ func (in *PerconaXtraDBClusterBackup) DeepCopyInto(out *PerconaXtraDBClusterBack
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
@@ -461,42 +834,153 @@ func (in *PerconaXtraDBClusterBackupList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
-	return nil
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PerconaXtraDBClusterList) DeepCopyInto(out *PerconaXtraDBClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PerconaXtraDBCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerconaXtraDBClusterList.
+func (in *PerconaXtraDBClusterList) DeepCopy() *PerconaXtraDBClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(PerconaXtraDBClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PerconaXtraDBClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PerconaXtraDBClusterOperation) DeepCopyInto(out *PerconaXtraDBClusterOperation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerconaXtraDBClusterOperation.
+func (in *PerconaXtraDBClusterOperation) DeepCopy() *PerconaXtraDBClusterOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(PerconaXtraDBClusterOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PerconaXtraDBClusterOperation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PerconaXtraDBClusterOperationList) DeepCopyInto(out *PerconaXtraDBClusterOperationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PerconaXtraDBClusterOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerconaXtraDBClusterOperationList.
+func (in *PerconaXtraDBClusterOperationList) DeepCopy() *PerconaXtraDBClusterOperationList {
+	if in == nil {
+		return nil
+	}
+	out := new(PerconaXtraDBClusterOperationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PerconaXtraDBClusterOperationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PerconaXtraDBClusterOperationSpec) DeepCopyInto(out *PerconaXtraDBClusterOperationSpec) {
+	*out = *in
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerconaXtraDBClusterOperationSpec.
+func (in *PerconaXtraDBClusterOperationSpec) DeepCopy() *PerconaXtraDBClusterOperationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PerconaXtraDBClusterOperationSpec)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PerconaXtraDBClusterList) DeepCopyInto(out *PerconaXtraDBClusterList) {
+func (in *PerconaXtraDBClusterOperationStatus) DeepCopyInto(out *PerconaXtraDBClusterOperationStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]PerconaXtraDBCluster, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]OperationResult, len(*in))
+		copy(*out, *in)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerconaXtraDBClusterList.
-func (in *PerconaXtraDBClusterList) DeepCopy() *PerconaXtraDBClusterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerconaXtraDBClusterOperationStatus.
+func (in *PerconaXtraDBClusterOperationStatus) DeepCopy() *PerconaXtraDBClusterOperationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PerconaXtraDBClusterList)
+	out := new(PerconaXtraDBClusterOperationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PerconaXtraDBClusterList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PerconaXtraDBClusterRestore) DeepCopyInto(out *PerconaXtraDBClusterRestore) {
 	*out = *in
@@ -571,6 +1055,21 @@ func (in *PerconaXtraDBClusterRestoreSpec) DeepCopyInto(out *PerconaXtraDBCluste
 		*out = new(PITR)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SafetyNet != nil {
+		in, out := &in.SafetyNet, &out.SafetyNet
+		*out = new(RestoreSafetyNet)
+		**out = **in
+	}
 	return
 }
 
@@ -595,6 +1094,38 @@ func (in *PerconaXtraDBClusterRestoreStatus) DeepCopyInto(out *PerconaXtraDBClus
 		in, out := &in.LastScheduled, &out.LastScheduled
 		*out = (*in).DeepCopy()
 	}
+	if in.Phases != nil {
+		in, out := &in.Phases, &out.Phases
+		*out = make([]RestorePhaseStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = new(RestoreProgress)
+		**out = **in
+	}
+	if in.Jobs != nil {
+		in, out := &in.Jobs, &out.Jobs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SavedVolumes != nil {
+		in, out := &in.SavedVolumes, &out.SavedVolumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ImportedTables != nil {
+		in, out := &in.ImportedTables, &out.ImportedTables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -631,6 +1162,11 @@ func (in *PerconaXtraDBClusterSpec) DeepCopyInto(out *PerconaXtraDBClusterSpec)
 		*out = new(PodSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Arbitrator != nil {
+		in, out := &in.Arbitrator, &out.Arbitrator
+		*out = new(PodSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.PMM != nil {
 		in, out := &in.PMM, &out.PMM
 		*out = new(PMMSpec)
@@ -686,6 +1222,45 @@ func (in *PerconaXtraDBClusterStatus) DeepCopyInto(out *PerconaXtraDBClusterStat
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PVCResize != nil {
+		in, out := &in.PVCResize, &out.PVCResize
+		*out = new(PVCResizeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeAutoscaling != nil {
+		in, out := &in.VolumeAutoscaling, &out.VolumeAutoscaling
+		*out = new(VolumeAutoscalingStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StorageMigration != nil {
+		in, out := &in.StorageMigration, &out.StorageMigration
+		*out = new(StorageMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]MemberStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.MembersCheckedAt != nil {
+		in, out := &in.MembersCheckedAt, &out.MembersCheckedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.CrashRecovery != nil {
+		in, out := &in.CrashRecovery, &out.CrashRecovery
+		*out = new(CrashRecoveryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NonPrimary != nil {
+		in, out := &in.NonPrimary, &out.NonPrimary
+		*out = new(NonPrimaryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Reseed != nil {
+		in, out := &in.Reseed, &out.Reseed
+		*out = new(ReseedStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -900,6 +1475,63 @@ func (in *PodSpec) DeepCopy() *PodSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationChannel) DeepCopyInto(out *ReplicationChannel) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationChannel.
+func (in *ReplicationChannel) DeepCopy() *ReplicationChannel {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationChannel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReseedSpec) DeepCopyInto(out *ReseedSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReseedSpec.
+func (in *ReseedSpec) DeepCopy() *ReseedSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReseedSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReseedStatus) DeepCopyInto(out *ReseedStatus) {
+	*out = *in
+	if in.Corrupted != nil {
+		in, out := &in.Corrupted, &out.Corrupted
+		*out = make([]CorruptedMember, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReseedStatus.
+func (in *ReseedStatus) DeepCopy() *ReseedStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReseedStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourcesList) DeepCopyInto(out *ResourcesList) {
 	*out = *in
@@ -916,6 +1548,131 @@ func (in *ResourcesList) DeepCopy() *ResourcesList {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestorePhaseStatus) DeepCopyInto(out *RestorePhaseStatus) {
+	*out = *in
+	if in.Started != nil {
+		in, out := &in.Started, &out.Started
+		*out = (*in).DeepCopy()
+	}
+	if in.Finished != nil {
+		in, out := &in.Finished, &out.Finished
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestorePhaseStatus.
+func (in *RestorePhaseStatus) DeepCopy() *RestorePhaseStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RestorePhaseStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreProgress) DeepCopyInto(out *RestoreProgress) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreProgress.
+func (in *RestoreProgress) DeepCopy() *RestoreProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreSafetyNet) DeepCopyInto(out *RestoreSafetyNet) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreSafetyNet.
+func (in *RestoreSafetyNet) DeepCopy() *RestoreSafetyNet {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreSafetyNet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleDownPolicy) DeepCopyInto(out *ScaleDownPolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleDownPolicy.
+func (in *ScaleDownPolicy) DeepCopy() *ScaleDownPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleDownPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceExpose) DeepCopyInto(out *ServiceExpose) {
+	*out = *in
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceExpose.
+func (in *ServiceExpose) DeepCopy() *ServiceExpose {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceExpose)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageMigrationStatus) DeepCopyInto(out *StorageMigrationStatus) {
+	*out = *in
+	if in.Migrated != nil {
+		in, out := &in.Migrated, &out.Migrated
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageMigrationStatus.
+func (in *StorageMigrationStatus) DeepCopy() *StorageMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLSSpec) DeepCopyInto(out *TLSSpec) {
 	*out = *in
@@ -988,6 +1745,61 @@ func (in *Volume) DeepCopy() *Volume {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeAutoscaling) DeepCopyInto(out *VolumeAutoscaling) {
+	*out = *in
+	if in.GrowthStep != nil {
+		in, out := &in.GrowthStep, &out.GrowthStep
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeAutoscaling.
+func (in *VolumeAutoscaling) DeepCopy() *VolumeAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeAutoscalingStatus) DeepCopyInto(out *VolumeAutoscalingStatus) {
+	*out = *in
+	if in.LastChecked != nil {
+		in, out := &in.LastChecked, &out.LastChecked
+		*out = (*in).DeepCopy()
+	}
+	if in.LastTriggered != nil {
+		in, out := &in.LastTriggered, &out.LastTriggered
+		*out = (*in).DeepCopy()
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]VolumeUsage, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeAutoscalingStatus.
+func (in *VolumeAutoscalingStatus) DeepCopy() *VolumeAutoscalingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeAutoscalingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeSpec) DeepCopyInto(out *VolumeSpec) {
 	*out = *in
@@ -1006,6 +1818,11 @@ func (in *VolumeSpec) DeepCopyInto(out *VolumeSpec) {
 		*out = new(corev1.PersistentVolumeClaimSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(VolumeAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1018,3 +1835,19 @@ func (in *VolumeSpec) DeepCopy() *VolumeSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeUsage) DeepCopyInto(out *VolumeUsage) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeUsage.
+func (in *VolumeUsage) DeepCopy() *VolumeUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeUsage)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -1,10 +1,14 @@
 package controller
 
 import (
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
 	"github.com/percona/percona-xtradb-cluster-operator/pkg/controller/pxc"
 )
 
 func init() {
 	// AddToManagerFuncs is a list of functions to create controllers and add them to a manager.
-	AddToManagerFuncs = append(AddToManagerFuncs, pxc.Add)
+	AddToManagerFuncs = append(AddToManagerFuncs, func(m manager.Manager) error {
+		return pxc.Add(m, clusterLocks)
+	})
 }
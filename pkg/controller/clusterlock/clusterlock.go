@@ -0,0 +1,34 @@
+// Package clusterlock keeps the locks of clusters. The store is shared by the controllers
+// changing the same cluster, so their steps never run at the same time.
+package clusterlock
+
+import (
+	"sync"
+)
+
+type Store struct {
+	store *sync.Map
+}
+
+func NewStore() Store {
+	return Store{
+		store: new(sync.Map),
+	}
+}
+
+// LoadOrCreate returns the lock of the cluster, key is its namespaced name
+func (l Store) LoadOrCreate(key string) Lock {
+	val, _ := l.store.LoadOrStore(key, Lock{
+		StatusMutex: new(sync.Mutex),
+		UpdateSync:  new(int32),
+	})
+
+	return val.(Lock)
+}
+
+type Lock struct {
+	// StatusMutex is held while the cluster or its status is changed
+	StatusMutex *sync.Mutex
+	// UpdateSync makes the version service jobs of the cluster run with the reconcile in-between
+	UpdateSync *int32
+}
@@ -2,8 +2,14 @@ package controller
 
 import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/controller/clusterlock"
 )
 
+// clusterLocks are shared by the cluster and the operation controllers,
+// so an operation step never runs together with the reconcile of its cluster
+var clusterLocks = clusterlock.NewStore()
+
 // AddToManagerFuncs is a list of functions to add all Controllers to the Manager
 var AddToManagerFuncs []func(manager.Manager) error
 
@@ -0,0 +1,104 @@
+package pxc
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/k8s"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/deployment"
+)
+
+// reconcileArbitrator deploys the Galera arbitrator. garbd can only join the running cluster,
+// so it's created once PXC members are ready. It's removed if the cluster is paused.
+func (r *ReconcilePerconaXtraDBCluster) reconcileArbitrator(cr *api.PerconaXtraDBCluster) error {
+	if !cr.ArbitratorEnabled() || cr.Spec.Pause {
+		return r.deleteArbitrator(cr)
+	}
+
+	arbitrator, err := deployment.GetArbitratorDeployment(cr)
+	if err != nil {
+		return errors.Wrap(err, "get arbitrator deployment")
+	}
+
+	current := appsv1.Deployment{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: arbitrator.Name, Namespace: cr.Namespace}, &current)
+	if k8serrors.IsNotFound(err) {
+		if cr.Status.PXC.Ready < 1 {
+			return nil
+		}
+		err = setControllerReference(cr, &arbitrator, r.scheme)
+		if err != nil {
+			return errors.Wrap(err, "set controller reference")
+		}
+		err = r.client.Create(context.TODO(), &arbitrator)
+		if err != nil && !k8serrors.IsAlreadyExists(err) {
+			return errors.Wrap(err, "create arbitrator deployment")
+		}
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "get arbitrator deployment")
+	}
+
+	current.Spec = arbitrator.Spec
+	err = r.client.Update(context.TODO(), &current)
+	if err != nil {
+		return errors.Wrap(err, "update arbitrator deployment")
+	}
+
+	return nil
+}
+
+func (r *ReconcilePerconaXtraDBCluster) deleteArbitrator(cr *api.PerconaXtraDBCluster) error {
+	arbitrator := appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deployment.GetArbitratorDeploymentName(cr),
+			Namespace: cr.Namespace,
+		},
+	}
+	err := r.client.Delete(context.TODO(), &arbitrator)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Wrap(err, "delete arbitrator deployment")
+	}
+
+	return nil
+}
+
+// arbitratorRunning reports if the arbitrator pod is running and ready.
+// garbd can't be queried for its Galera status, so it's the only check of it.
+func (r *ReconcilePerconaXtraDBCluster) arbitratorRunning(cr *api.PerconaXtraDBCluster) (bool, error) {
+	arbitrator, err := deployment.GetArbitratorDeployment(cr)
+	if err != nil {
+		return false, errors.Wrap(err, "get arbitrator deployment")
+	}
+
+	pods := corev1.PodList{}
+	err = r.client.List(context.TODO(),
+		&pods,
+		&client.ListOptions{
+			Namespace:     cr.Namespace,
+			LabelSelector: labels.SelectorFromSet(arbitrator.Spec.Selector.MatchLabels),
+		},
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "get pods list")
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil && k8s.IsPodReady(pod) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
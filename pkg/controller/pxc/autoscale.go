@@ -0,0 +1,226 @@
+package pxc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/statefulset"
+)
+
+// autoscaleVolumes measures the datadir usage of PXC members and grows the datadir
+// volume size in the cluster spec once any of them is used above the threshold.
+// Volumes are expanded by reconcilePersistentVolumes as for the size changed by the user.
+// Members hold the same data and fill at the same rate, so volumes are kept of the same size.
+func (r *ReconcilePerconaXtraDBCluster) autoscaleVolumes(cr *api.PerconaXtraDBCluster) error {
+	vs := cr.Spec.PXC.VolumeSpec
+	if vs == nil || vs.PersistentVolumeClaim == nil || vs.Autoscaling == nil || !vs.Autoscaling.Enabled {
+		return nil
+	}
+	as := vs.Autoscaling
+
+	st := cr.Status.VolumeAutoscaling
+	if st == nil {
+		st = &api.VolumeAutoscalingStatus{}
+		cr.Status.VolumeAutoscaling = st
+	}
+	if st.LastChecked != nil && time.Since(st.LastChecked.Time) < time.Duration(as.CheckIntervalSeconds)*time.Second {
+		return nil
+	}
+	now := metav1.Now()
+	st.LastChecked = &now
+
+	sfs := statefulset.NewNode(cr)
+	pods := corev1.PodList{}
+	err := r.client.List(context.TODO(),
+		&pods,
+		&client.ListOptions{
+			Namespace:     cr.Namespace,
+			LabelSelector: labels.SelectorFromSet(sfs.Labels()),
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "get pods list")
+	}
+	pvcs := corev1.PersistentVolumeClaimList{}
+	err = r.client.List(context.TODO(),
+		&pvcs,
+		&client.ListOptions{
+			Namespace:     cr.Namespace,
+			LabelSelector: labels.SelectorFromSet(sfs.Labels()),
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "get PVC list")
+	}
+
+	st.Volumes = st.Volumes[:0]
+	var maxUsage int32
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		usage, err := r.datadirUsage(pod)
+		if err != nil {
+			r.logger(cr.Name, cr.Namespace).Info("failed to get datadir usage", "pod", pod.Name, "error", err.Error())
+			continue
+		}
+		st.Volumes = append(st.Volumes, usage)
+		if usage.UsedPercent > maxUsage {
+			maxUsage = usage.UsedPercent
+		}
+	}
+
+	if maxUsage < as.ThresholdPercent {
+		return nil
+	}
+
+	if cr.Status.PVCResize != nil && cr.Status.PVCResize.State == api.PVCResizeInProgress {
+		// the previous growth isn't finished yet
+		return nil
+	}
+
+	// PVCs can be bigger than the volume spec if they were expanded manually
+	current := vs.PersistentVolumeClaim.Resources.Requests[corev1.ResourceStorage]
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		if !strings.HasPrefix(pvc.Name, datadirVolume+"-") {
+			continue
+		}
+		size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+		if capacity.Cmp(size) < 0 {
+			// the previous growth isn't finished yet
+			return nil
+		}
+		if size.Cmp(current) > 0 {
+			current = size
+		}
+	}
+
+	size := nextVolumeSize(current, as)
+	if size.Cmp(current) <= 0 {
+		msg := fmt.Sprintf("datadir is %d%% used, but volumes already have the max size %s", maxUsage, current.String())
+		if st.Message != msg {
+			st.Message = msg
+			r.recorder.Event(cr, corev1.EventTypeWarning, "VolumeAutoscalingMaxSize", msg)
+		}
+		return nil
+	}
+
+	err = r.setVolumeSize(cr, size)
+	if err != nil {
+		st.Message = fmt.Sprintf("failed to grow volumes: %v", err)
+		r.recorder.Event(cr, corev1.EventTypeWarning, "VolumeAutoscalingFailed", st.Message)
+		return errors.Wrap(err, "set volume size")
+	}
+
+	st.LastTriggered = &now
+	st.Size = size.String()
+	st.Message = fmt.Sprintf("datadir is %d%% used, volumes are grown from %s to %s", maxUsage, current.String(), size.String())
+	r.recorder.Event(cr, corev1.EventTypeNormal, "VolumeAutoscaled", st.Message)
+	r.logger(cr.Name, cr.Namespace).Info("volume autoscaling", "usage", maxUsage, "from", current.String(), "to", size.String())
+
+	return nil
+}
+
+// setVolumeSize changes the datadir volume size in the spec of the cluster.
+// Only the size is patched, so defaults of the reconciled object don't get into the spec.
+func (r *ReconcilePerconaXtraDBCluster) setVolumeSize(cr *api.PerconaXtraDBCluster, size resource.Quantity) error {
+	c := &api.PerconaXtraDBCluster{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, c)
+	if err != nil {
+		return errors.Wrap(err, "get cluster")
+	}
+	if c.Spec.PXC == nil || c.Spec.PXC.VolumeSpec == nil || c.Spec.PXC.VolumeSpec.PersistentVolumeClaim == nil {
+		return errors.New("persistent volume claim isn't set in the PXC volume spec")
+	}
+
+	orig := c.DeepCopy()
+	pvc := c.Spec.PXC.VolumeSpec.PersistentVolumeClaim
+	if pvc.Resources.Requests == nil {
+		pvc.Resources.Requests = corev1.ResourceList{}
+	}
+	pvc.Resources.Requests[corev1.ResourceStorage] = size
+
+	return r.client.Patch(context.TODO(), c, client.MergeFrom(orig))
+}
+
+// nextVolumeSize returns the size the volume grows to from the current one
+func nextVolumeSize(current resource.Quantity, as *api.VolumeAutoscaling) resource.Quantity {
+	size := current.DeepCopy()
+	if as.GrowthStep != nil && !as.GrowthStep.IsZero() {
+		size.Add(*as.GrowthStep)
+	} else {
+		size.Add(*resource.NewQuantity(current.Value()/4, current.Format))
+	}
+
+	if as.MaxSize != nil && size.Cmp(*as.MaxSize) > 0 {
+		size = as.MaxSize.DeepCopy()
+	}
+
+	return size
+}
+
+// datadirUsage gets the usage of the datadir filesystem of the PXC pod
+func (r *ReconcilePerconaXtraDBCluster) datadirUsage(pod *corev1.Pod) (api.VolumeUsage, error) {
+	var outb, errb bytes.Buffer
+	err := r.clientcmd.Exec(pod, "pxc", []string{"df", "-P", "-B1", "/var/lib/mysql"}, nil, &outb, &errb, false)
+	if err != nil {
+		return api.VolumeUsage{}, errors.Wrapf(err, "run df: %s", strings.TrimSpace(errb.String()))
+	}
+
+	used, total, err := parseDF(outb.String())
+	if err != nil {
+		return api.VolumeUsage{}, err
+	}
+
+	return api.VolumeUsage{
+		PVC:         datadirVolume + "-" + pod.Name,
+		UsedBytes:   used,
+		TotalBytes:  total,
+		UsedPercent: int32(used * 100 / total),
+	}, nil
+}
+
+// parseDF gets used and total bytes from the POSIX output of df with 1-byte blocks
+func parseDF(out string) (used, total int64, err error) {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) < 2 {
+		return 0, 0, errors.Errorf("unexpected df output: %q", out)
+	}
+
+	// Filesystem 1-blocks Used Available Capacity Mounted on
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 4 {
+		return 0, 0, errors.Errorf("unexpected df output: %q", out)
+	}
+
+	total, err = strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "parse total size")
+	}
+	used, err = strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "parse used size")
+	}
+	if total == 0 {
+		return 0, 0, errors.New("zero filesystem size")
+	}
+
+	return used, total, nil
+}
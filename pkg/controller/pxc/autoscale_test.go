@@ -0,0 +1,81 @@
+package pxc
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+)
+
+func TestParseDF(t *testing.T) {
+	out := `Filesystem        1-blocks       Used  Available Capacity Mounted on
+/dev/sdb       10501771264 8401416704 2083577856      81% /var/lib/mysql
+`
+	used, total, err := parseDF(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if used != 8401416704 || total != 10501771264 {
+		t.Errorf("got used %d, total %d", used, total)
+	}
+
+	if _, _, err = parseDF("df: /var/lib/mysql: No such file or directory"); err == nil {
+		t.Error("expected error on wrong output")
+	}
+}
+
+func TestNextVolumeSize(t *testing.T) {
+	q := resource.MustParse
+	step, max := q("5Gi"), q("12Gi")
+
+	tests := []struct {
+		name     string
+		current  string
+		as       api.VolumeAutoscaling
+		expected string
+	}{
+		{"default step", "8Gi", api.VolumeAutoscaling{}, "10Gi"},
+		{"step", "6Gi", api.VolumeAutoscaling{GrowthStep: &step}, "11Gi"},
+		{"max size", "8Gi", api.VolumeAutoscaling{GrowthStep: &step, MaxSize: &max}, "12Gi"},
+		{"max size reached", "12Gi", api.VolumeAutoscaling{GrowthStep: &step, MaxSize: &max}, "12Gi"},
+	}
+
+	for _, tt := range tests {
+		got := nextVolumeSize(q(tt.current), &tt.as)
+		if got.Cmp(q(tt.expected)) != 0 {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got.String())
+		}
+	}
+}
+
+func TestSetVolumeSize(t *testing.T) {
+	cr := newVolumeCR("6Gi")
+	r := buildFakeClient([]runtime.Object{cr})
+
+	// the reconciled object has defaults, they must not get into the spec
+	reconciled := cr.DeepCopy()
+	reconciled.Spec.PXC.Size = 5
+
+	err := r.setVolumeSize(reconciled, resource.MustParse("8Gi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := &api.PerconaXtraDBCluster{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := got.Spec.PXC.VolumeSpec.PersistentVolumeClaim.Resources.Requests[corev1.ResourceStorage]
+	if size.String() != "8Gi" {
+		t.Errorf("expected volume size 8Gi, got %s", size.String())
+	}
+	if got.Spec.PXC.Size != 3 {
+		t.Errorf("expected PXC size to stay 3, got %d", got.Spec.PXC.Size)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"hash/crc32"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
@@ -83,7 +84,10 @@ func (r *ReconcilePerconaXtraDBCluster) reconcileBackups(cr *api.PerconaXtraDBCl
 			}
 
 			if !ok || sch.PXCScheduledBackupSchedule.Schedule != bcp.Schedule ||
-				sch.PXCScheduledBackupSchedule.StorageName != bcp.StorageName {
+				sch.PXCScheduledBackupSchedule.StorageName != bcp.StorageName ||
+				sch.PXCScheduledBackupSchedule.Type != bcp.Type ||
+				!reflect.DeepEqual(sch.PXCScheduledBackupSchedule.Logical, bcp.Logical) ||
+				!reflect.DeepEqual(sch.PXCScheduledBackupSchedule.Hooks, bcp.Hooks) {
 				r.log.Info("Creating or updating backup job", "name", bcp.Name, "schedule", bcp.Schedule)
 				r.deleteBackupJob(bcp.Name)
 				jobID, err := r.crons.crons.AddFunc(bcp.Schedule, r.createBackupJob(cr, bcp, strg.Type))
@@ -212,6 +216,9 @@ func (r *ReconcilePerconaXtraDBCluster) createBackupJob(cr *api.PerconaXtraDBClu
 			Spec: api.PXCBackupSpec{
 				PXCCluster:  cr.Name,
 				StorageName: backupJob.StorageName,
+				Type:        backupJob.Type,
+				Logical:     backupJob.Logical,
+				Hooks:       backupJob.Hooks,
 			},
 		}
 		err = r.client.Create(context.TODO(), bcp)
@@ -0,0 +1,233 @@
+package pxc
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/deployment"
+)
+
+var conditionTypes = map[api.ClusterConditionType]struct{}{
+	api.ConditionPXCReady:          {},
+	api.ConditionProxyReady:        {},
+	api.ConditionBackupsHealthy:    {},
+	api.ConditionPITRHealthy:       {},
+	api.ConditionTLSValid:          {},
+	api.ConditionUpgradeInProgress: {},
+	api.ConditionFullCrashRecovery: {},
+	api.ConditionReconciled:        {},
+}
+
+// updateConditions sets conditions of the cluster from its current state.
+// A condition which can't be checked is set to Unknown with the error in the message,
+// conditions of disabled features are removed.
+func (r *ReconcilePerconaXtraDBCluster) updateConditions(cr *api.PerconaXtraDBCluster, upgradeInProgress bool) {
+	// previous versions kept the history of the cluster state in conditions
+	conditions := cr.Status.Conditions[:0]
+	for _, c := range cr.Status.Conditions {
+		if _, ok := conditionTypes[c.Type]; ok {
+			conditions = append(conditions, c)
+		}
+	}
+	cr.Status.Conditions = conditions
+
+	setCondition := func(t api.ClusterConditionType, status api.ConditionStatus, reason, msg string) {
+		cr.Status.SetCondition(api.ClusterCondition{
+			Type:               t,
+			Status:             status,
+			ObservedGeneration: cr.Generation,
+			Reason:             reason,
+			Message:            msg,
+		})
+	}
+	setUnknown := func(t api.ClusterConditionType, err error) {
+		setCondition(t, api.ConditionUnknown, "CheckFailed", err.Error())
+	}
+
+	status, reason, msg := appCondition(&cr.Status.PXC, cr.Spec.Pause)
+	setCondition(api.ConditionPXCReady, status, reason, msg)
+
+	switch {
+	case cr.HAProxyEnabled():
+		status, reason, msg := appCondition(&cr.Status.HAProxy, cr.Spec.Pause)
+		setCondition(api.ConditionProxyReady, status, reason, msg)
+	case cr.ProxySQLEnabled():
+		status, reason, msg := appCondition(&cr.Status.ProxySQL, cr.Spec.Pause)
+		setCondition(api.ConditionProxyReady, status, reason, msg)
+	default:
+		cr.Status.RemoveCondition(api.ConditionProxyReady)
+	}
+
+	if cr.Spec.Backup == nil {
+		cr.Status.RemoveCondition(api.ConditionBackupsHealthy)
+	} else if status, reason, msg, err := r.backupsCondition(cr); err != nil {
+		setUnknown(api.ConditionBackupsHealthy, err)
+	} else {
+		setCondition(api.ConditionBackupsHealthy, status, reason, msg)
+	}
+
+	if cr.Spec.Backup == nil || !cr.Spec.Backup.PITR.Enabled {
+		cr.Status.RemoveCondition(api.ConditionPITRHealthy)
+	} else if status, reason, msg, err := r.pitrCondition(cr); err != nil {
+		setUnknown(api.ConditionPITRHealthy, err)
+	} else {
+		setCondition(api.ConditionPITRHealthy, status, reason, msg)
+	}
+
+	if status, reason, msg, err := r.tlsCondition(cr); err != nil {
+		setUnknown(api.ConditionTLSValid, err)
+	} else if status == "" {
+		cr.Status.RemoveCondition(api.ConditionTLSValid)
+	} else {
+		setCondition(api.ConditionTLSValid, status, reason, msg)
+	}
+
+	if upgradeInProgress {
+		setCondition(api.ConditionUpgradeInProgress, api.ConditionTrue, "RollingUpdate", "pods are being updated to the new revision")
+	} else {
+		setCondition(api.ConditionUpgradeInProgress, api.ConditionFalse, "UpToDate", "")
+	}
+
+	setCondition(api.ConditionReconciled, api.ConditionTrue, "ReconcileSucceeded", "")
+
+	// the recovery itself sets the condition, see recoverFullClusterCrashIfNeeded
+	if cr.Status.FindCondition(api.ConditionFullCrashRecovery) == nil {
+		setCondition(api.ConditionFullCrashRecovery, api.ConditionFalse, "NoCrashDetected", "")
+	}
+}
+
+// setReconcileError sets the Reconciled condition to False with the error of the failed reconcile.
+// Other conditions keep the state observed by the last successful reconcile.
+func setReconcileError(cr *api.PerconaXtraDBCluster, reconcileErr error) {
+	cr.Status.SetCondition(api.ClusterCondition{
+		Type:               api.ConditionReconciled,
+		Status:             api.ConditionFalse,
+		ObservedGeneration: cr.Generation,
+		Reason:             "ReconcileError",
+		Message:            reconcileErr.Error(),
+	})
+}
+
+func appCondition(st *api.AppStatus, paused bool) (api.ConditionStatus, string, string) {
+	msg := fmt.Sprintf("%d of %d pods are ready", st.Ready, st.Size)
+	switch {
+	case paused:
+		return api.ConditionFalse, "Paused", msg
+	case st.Status == api.AppStateReady:
+		return api.ConditionTrue, "AllPodsReady", msg
+	case st.Status == api.AppStateError:
+		return api.ConditionFalse, "Error", st.Message
+	default:
+		return api.ConditionFalse, "PodsNotReady", msg
+	}
+}
+
+// backupsCondition checks the latest finished backup of the cluster
+func (r *ReconcilePerconaXtraDBCluster) backupsCondition(cr *api.PerconaXtraDBCluster) (api.ConditionStatus, string, string, error) {
+	bcpList := api.PerconaXtraDBClusterBackupList{}
+	err := r.client.List(context.TODO(), &bcpList, &client.ListOptions{Namespace: cr.Namespace})
+	if err != nil {
+		return "", "", "", errors.Wrap(err, "get backups list")
+	}
+
+	var latest *api.PerconaXtraDBClusterBackup
+	for i := range bcpList.Items {
+		bcp := &bcpList.Items[i]
+		if bcp.Spec.PXCCluster != cr.Name {
+			continue
+		}
+		if bcp.Status.State != api.BackupSucceeded && bcp.Status.State != api.BackupFailed {
+			continue
+		}
+		if latest == nil || latest.CreationTimestamp.Before(&bcp.CreationTimestamp) {
+			latest = bcp
+		}
+	}
+
+	switch {
+	case latest == nil:
+		return api.ConditionUnknown, "NoBackups", "there are no finished backups", nil
+	case latest.Status.State == api.BackupFailed:
+		return api.ConditionFalse, "BackupFailed", fmt.Sprintf("backup %s failed", latest.Name), nil
+	default:
+		return api.ConditionTrue, "BackupSucceeded", fmt.Sprintf("backup %s succeeded", latest.Name), nil
+	}
+}
+
+// pitrCondition checks the binlog collector deployment
+func (r *ReconcilePerconaXtraDBCluster) pitrCondition(cr *api.PerconaXtraDBCluster) (api.ConditionStatus, string, string, error) {
+	collector := appsv1.Deployment{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: deployment.GetBinlogCollectorDeploymentName(cr), Namespace: cr.Namespace}, &collector)
+	if k8serrors.IsNotFound(err) {
+		return api.ConditionFalse, "CollectorNotFound", "binlog collector is created once the cluster is ready", nil
+	}
+	if err != nil {
+		return "", "", "", errors.Wrap(err, "get binlog collector deployment")
+	}
+
+	if collector.Status.AvailableReplicas < 1 {
+		return api.ConditionFalse, "CollectorNotReady", "binlog collector isn't available", nil
+	}
+
+	return api.ConditionTrue, "CollectorRunning", "", nil
+}
+
+// tlsCondition checks certificates of the external and the internal TLS secrets.
+// Empty status means TLS isn't used by the cluster.
+func (r *ReconcilePerconaXtraDBCluster) tlsCondition(cr *api.PerconaXtraDBCluster) (api.ConditionStatus, string, string, error) {
+	var expiresAt time.Time
+	for _, name := range []string{cr.Spec.PXC.SSLSecretName, cr.Spec.PXC.SSLInternalSecretName} {
+		if name == "" {
+			continue
+		}
+
+		secret := corev1.Secret{}
+		err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: cr.Namespace}, &secret)
+		if k8serrors.IsNotFound(err) {
+			if cr.Spec.AllowUnsafeConfig && (cr.Spec.TLS == nil || cr.Spec.TLS.IssuerConf == nil) {
+				return "", "", "", nil
+			}
+			return api.ConditionFalse, "SecretNotFound", fmt.Sprintf("secret %s not found", name), nil
+		}
+		if err != nil {
+			return "", "", "", errors.Wrapf(err, "get secret %s", name)
+		}
+
+		block, _ := pem.Decode(secret.Data["tls.crt"])
+		if block == nil {
+			return api.ConditionFalse, "InvalidCertificate", fmt.Sprintf("secret %s has no PEM certificate in tls.crt", name), nil
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return api.ConditionFalse, "InvalidCertificate", fmt.Sprintf("secret %s: %v", name, err), nil
+		}
+
+		now := time.Now()
+		if now.After(cert.NotAfter) {
+			return api.ConditionFalse, "CertificateExpired", fmt.Sprintf("certificate in secret %s expired at %s", name, cert.NotAfter.UTC().Format(time.RFC3339)), nil
+		}
+		if now.Before(cert.NotBefore) {
+			return api.ConditionFalse, "CertificateNotYetValid", fmt.Sprintf("certificate in secret %s is valid from %s", name, cert.NotBefore.UTC().Format(time.RFC3339)), nil
+		}
+		if expiresAt.IsZero() || cert.NotAfter.Before(expiresAt) {
+			expiresAt = cert.NotAfter
+		}
+	}
+
+	if expiresAt.IsZero() {
+		return "", "", "", nil
+	}
+
+	return api.ConditionTrue, "CertificatesValid", "certificates are valid until " + expiresAt.UTC().Format(time.RFC3339), nil
+}
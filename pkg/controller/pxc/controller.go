@@ -24,6 +24,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -35,6 +36,7 @@ import (
 
 	"github.com/percona/percona-xtradb-cluster-operator/clientcmd"
 	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/controller/clusterlock"
 	"github.com/percona/percona-xtradb-cluster-operator/pkg/k8s"
 	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc"
 	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app"
@@ -45,8 +47,9 @@ import (
 
 // Add creates a new PerconaXtraDBCluster Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
-func Add(mgr manager.Manager) error {
-	r, err := newReconciler(mgr)
+// The lock store is shared with other controllers changing the cluster.
+func Add(mgr manager.Manager, locks clusterlock.Store) error {
+	r, err := newReconciler(mgr, locks)
 	if err != nil {
 		return err
 	}
@@ -55,7 +58,7 @@ func Add(mgr manager.Manager) error {
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) (reconcile.Reconciler, error) {
+func newReconciler(mgr manager.Manager, locks clusterlock.Store) (*ReconcilePerconaXtraDBCluster, error) {
 	sv, err := version.Server()
 	if err != nil {
 		return nil, errors.Wrap(err, "get version")
@@ -73,12 +76,16 @@ func newReconciler(mgr manager.Manager) (reconcile.Reconciler, error) {
 
 	return &ReconcilePerconaXtraDBCluster{
 		client:        mgr.GetClient(),
+		apiReader:     mgr.GetAPIReader(),
 		scheme:        mgr.GetScheme(),
 		crons:         NewCronRegistry(),
 		serverVersion: sv,
 		clientcmd:     cli,
-		lockers:       newLockStore(),
+		lockers:       locks,
 		log:           zapr.NewLogger(zapLog),
+		recorder:      mgr.GetEventRecorderFor("pxc-controller"),
+
+		reportedOverrides: new(sync.Map),
 	}, nil
 }
 
@@ -105,14 +112,20 @@ var _ reconcile.Reconciler = &ReconcilePerconaXtraDBCluster{}
 type ReconcilePerconaXtraDBCluster struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client         client.Client
+	client client.Client
+	// apiReader reads objects the operator doesn't watch directly from the API server
+	apiReader      client.Reader
 	scheme         *runtime.Scheme
 	crons          CronRegistry
 	clientcmd      *clientcmd.Client
 	syncUsersState int32
 	serverVersion  *version.ServerVersion
-	lockers        lockStore
+	lockers        clusterlock.Store
 	log            logr.Logger
+	recorder       record.EventRecorder
+	// reportedOverrides keeps the safe configuration overrides
+	// reported by the last SafeConfigOverride events of every cluster
+	reportedOverrides *sync.Map
 }
 
 func (r *ReconcilePerconaXtraDBCluster) logger(name, namespace string) logr.Logger {
@@ -120,28 +133,20 @@ func (r *ReconcilePerconaXtraDBCluster) logger(name, namespace string) logr.Logg
 		WithValues("cluster", name, "namespace", namespace)
 }
 
-type lockStore struct {
-	store *sync.Map
-}
-
-func newLockStore() lockStore {
-	return lockStore{
-		store: new(sync.Map),
+// reportSafeConfigOverrides emits events about what the safe configuration overrides in the spec.
+// They are emitted only when the overrides change, not on every reconcile.
+func (r *ReconcilePerconaXtraDBCluster) reportSafeConfigOverrides(cr *api.PerconaXtraDBCluster) {
+	overrides := cr.SafeConfigOverrides()
+	key := cr.Namespace + "/" + cr.Name
+	reported := strings.Join(overrides, "\n")
+	if prev, ok := r.reportedOverrides.Load(key); ok && prev.(string) == reported {
+		return
 	}
-}
-
-func (l lockStore) LoadOrCreate(key string) lock {
-	val, _ := l.store.LoadOrStore(key, lock{
-		statusMutex: new(sync.Mutex),
-		updateSync:  new(int32),
-	})
+	r.reportedOverrides.Store(key, reported)
 
-	return val.(lock)
-}
-
-type lock struct {
-	statusMutex *sync.Mutex
-	updateSync  *int32
+	for _, msg := range overrides {
+		r.recorder.Event(cr, corev1.EventTypeWarning, "SafeConfigOverride", msg+", set allowUnsafeConfigurations=true to disable safe configuration")
+	}
 }
 
 const (
@@ -193,13 +198,13 @@ func (r *ReconcilePerconaXtraDBCluster) Reconcile(request reconcile.Request) (re
 
 	// Fetch the PerconaXtraDBCluster instance
 	// PerconaXtraDBCluster object is also accessed and changed by a version service's cron job (that run concurrently)
-	l.statusMutex.Lock()
-	defer l.statusMutex.Unlock()
+	l.StatusMutex.Lock()
+	defer l.StatusMutex.Unlock()
 	// we have to be sure the reconcile loop will be run at least once
 	// in-between any version service jobs (hence any two vs jobs shouldn't be run sequentially).
 	// the version service job sets the state to  `updateWait` and the next job can be run only
 	// after the state was dropped to`updateDone` again
-	defer atomic.StoreInt32(l.updateSync, updateDone)
+	defer atomic.StoreInt32(l.UpdateSync, updateDone)
 
 	o := &api.PerconaXtraDBCluster{}
 	err := r.client.Get(context.TODO(), request.NamespacedName, o)
@@ -207,6 +212,7 @@ func (r *ReconcilePerconaXtraDBCluster) Reconcile(request reconcile.Request) (re
 		if k8serrors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			r.reportedOverrides.Delete(request.NamespacedName.String())
 			return rr, nil
 		}
 		// Error reading the object - requeue the request.
@@ -228,18 +234,32 @@ func (r *ReconcilePerconaXtraDBCluster) Reconcile(request reconcile.Request) (re
 		}
 	}()
 
+	r.reportSafeConfigOverrides(o)
+
 	changed, err := o.CheckNSetDefaults(r.serverVersion, reqLogger)
 	if err != nil {
 		return reconcile.Result{}, errors.Wrap(err, "wrong PXC options")
 	}
 
-	if o.CompareVersionWith("1.7.0") >= 0 && *o.Spec.PXC.AutoRecovery {
+	if o.CompareVersionWith("1.7.0") >= 0 && o.Spec.PXC.RecoveryPolicy != api.RecoveryPolicyDisabled {
 		err = r.recoverFullClusterCrashIfNeeded(o)
 		if err != nil {
 			reqLogger.Error(err, "Failed to check if cluster needs to recover")
 		}
 	}
 
+	if o.CompareVersionWith("1.6.0") >= 0 {
+		err = r.reconcileNonPrimary(o)
+		if err != nil {
+			reqLogger.Error(err, "Failed to check the Primary component")
+		}
+
+		err = r.reconcileReseed(o)
+		if err != nil {
+			reqLogger.Error(err, "Failed to check members for the datadir corruption")
+		}
+	}
+
 	if o.ObjectMeta.DeletionTimestamp != nil {
 		finalizers := []string{}
 		for _, fnlz := range o.GetFinalizers() {
@@ -299,14 +319,33 @@ func (r *ReconcilePerconaXtraDBCluster) Reconcile(request reconcile.Request) (re
 		}
 	}
 
-	err = r.deploy(o)
-	if err != nil {
-		return reconcile.Result{}, err
+	// the volume spec had no effect on existing volumes before 1.9.0,
+	// so older clusters may have volumes different from the spec
+	if o.CompareVersionWith("1.9.0") >= 0 {
+		recreating, err := r.reconcilePersistentVolumes(o)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "reconcile persistent volumes")
+		}
+		if recreating {
+			return rr, nil
+		}
+
+		recreating, err = r.migrateStorageClass(o)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "migrate storage class")
+		}
+		if recreating {
+			return rr, nil
+		}
+
+		if aerr := r.autoscaleVolumes(o); aerr != nil {
+			reqLogger.Error(aerr, "failed to autoscale volumes")
+		}
 	}
 
-	operatorPod, err := k8s.OperatorPod(r.client)
+	err = r.deploy(o)
 	if err != nil {
-		return reconcile.Result{}, errors.Wrap(err, "get operator deployment")
+		return reconcile.Result{}, err
 	}
 
 	crOwnerRef, err := OwnerRef(o, r.scheme)
@@ -316,17 +355,14 @@ func (r *ReconcilePerconaXtraDBCluster) Reconcile(request reconcile.Request) (re
 
 	inits := []corev1.Container{}
 	if o.CompareVersionWith("1.5.0") >= 0 {
-		imageName := operatorPod.Spec.Containers[0].Image
-		if o.CompareVersionWith(version.Version) != 0 {
-			imageName = strings.Split(imageName, ":")[0] + ":" + o.Spec.CRVersion
+		imageName, err := k8s.InitImage(o, r.client)
+		if err != nil {
+			return reconcile.Result{}, err
 		}
 		var initResources *api.PodResources
 		if o.CompareVersionWith("1.6.0") >= 0 {
 			initResources = o.Spec.PXC.Resources
 		}
-		if len(o.Spec.InitImage) > 0 {
-			imageName = o.Spec.InitImage
-		}
 		initC, err := statefulset.EntrypointInitContainer(imageName, initResources, o.Spec.PXC.ContainerSecurityContext, o.Spec.PXC.ImagePullPolicy)
 		if err != nil {
 			return reconcile.Result{}, err
@@ -334,6 +370,14 @@ func (r *ReconcilePerconaXtraDBCluster) Reconcile(request reconcile.Request) (re
 		inits = append(inits, initC)
 	}
 
+	if o.CompareVersionWith("1.9.0") >= 0 {
+		// members have to be removed before the StatefulSet gets the new size
+		err = r.scaleDownPXC(o)
+		if err != nil {
+			return reconcile.Result{}, errors.Wrap(err, "scale down pxc")
+		}
+	}
+
 	pxcSet := statefulset.NewNode(o)
 	pxc.MergeTemplateAnnotations(pxcSet.StatefulSet(), pxcAnnotations)
 	err = r.updatePod(pxcSet, o.Spec.PXC.PodSpec, o, inits)
@@ -510,6 +554,11 @@ func (r *ReconcilePerconaXtraDBCluster) Reconcile(request reconcile.Request) (re
 		}
 	}
 
+	err = r.reconcileArbitrator(o)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "reconcile arbitrator")
+	}
+
 	err = r.reconcileBackups(o)
 	if err != nil {
 		return reconcile.Result{}, err
@@ -534,25 +583,17 @@ func (r *ReconcilePerconaXtraDBCluster) deploy(cr *api.PerconaXtraDBCluster) err
 		return err
 	}
 
-	operatorPod, err := k8s.OperatorPod(r.client)
-	if err != nil {
-		return errors.Wrap(err, "get operator deployment")
-	}
-
 	logger := r.logger(cr.Name, cr.Namespace)
 	inits := []corev1.Container{}
 	if cr.CompareVersionWith("1.5.0") >= 0 {
-		imageName := operatorPod.Spec.Containers[0].Image
-		if cr.CompareVersionWith(version.Version) != 0 {
-			imageName = strings.Split(imageName, ":")[0] + ":" + cr.Spec.CRVersion
+		imageName, err := k8s.InitImage(cr, r.client)
+		if err != nil {
+			return err
 		}
 		var initResources *api.PodResources
 		if cr.CompareVersionWith("1.6.0") >= 0 {
 			initResources = cr.Spec.PXC.Resources
 		}
-		if len(cr.Spec.InitImage) > 0 {
-			imageName = cr.Spec.InitImage
-		}
 		initC, err := statefulset.EntrypointInitContainer(imageName, initResources, cr.Spec.PXC.ContainerSecurityContext, cr.Spec.PXC.ImagePullPolicy)
 		if err != nil {
 			return err
@@ -0,0 +1,45 @@
+package pxc
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestReportSafeConfigOverrides(t *testing.T) {
+	cr := newCR("cr-mock", "pxc")
+	cr.Spec.PXC.Size = 2
+	r := buildFakeClient([]runtime.Object{cr})
+	recorder := record.NewFakeRecorder(10)
+	r.recorder = recorder
+
+	events := func() int {
+		n := 0
+		for {
+			select {
+			case <-recorder.Events:
+				n++
+			default:
+				return n
+			}
+		}
+	}
+
+	r.reportSafeConfigOverrides(cr)
+	if n := events(); n != 1 {
+		t.Fatalf("expected 1 event, got %d", n)
+	}
+
+	// a failed reconcile doesn't update the observed generation, the overrides are reported once anyway
+	r.reportSafeConfigOverrides(cr)
+	if n := events(); n != 0 {
+		t.Fatalf("expected no events for the same overrides, got %d", n)
+	}
+
+	cr.Spec.PXC.Size = 6
+	r.reportSafeConfigOverrides(cr)
+	if n := events(); n != 1 {
+		t.Fatalf("expected 1 event for changed overrides, got %d", n)
+	}
+}
@@ -12,7 +12,9 @@ import (
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var (
@@ -20,8 +22,23 @@ var (
 	logLinesRequired        = int64(8)
 )
 
+// recoveryStateCmd prints the Galera state of the node if it waits for the full crash recovery.
+// The entrypoint keeps the state recovered by mysqld in the recovery file. The file is empty
+// if the entrypoint is of the older version, it reports the seqno only in the logs then.
+const recoveryStateCmd = `[ -f /tmp/recovery-case ] || exit 0
+echo waiting
+cat /tmp/recovery-case`
+
+// logPrefix is the line the entrypoint of the older versions logs the recovered seqno with
 const logPrefix = `#####################################################LAST_LINE`
 
+// recoveryState is the Galera state of the node waiting for the full crash recovery.
+// Seqno is -1 if it's unknown.
+type recoveryState struct {
+	UUID  string
+	Seqno int64
+}
+
 func (r *ReconcilePerconaXtraDBCluster) recoverFullClusterCrashIfNeeded(cr *v1.PerconaXtraDBCluster) error {
 	if cr.Spec.PXC.Size <= 0 {
 		return nil
@@ -35,36 +52,84 @@ func (r *ReconcilePerconaXtraDBCluster) recoverFullClusterCrashIfNeeded(cr *v1.P
 		return err
 	}
 
-	isWaiting, _, err := r.isPodWaitingForRecovery(cr.Namespace, cr.Name+"-pxc-0")
+	isWaiting, err := r.isPodWaitingForRecovery(cr.Namespace, cr.Name+"-pxc-0")
 	if err != nil {
 		return errors.Wrap(err, "failed to check if pxc pod 0 is waiting for recovery")
 	}
 
 	if isWaiting {
-		return r.doFullCrashRecovery(cr.Name, cr.Namespace, int(cr.Spec.PXC.Size))
+		return r.doFullCrashRecovery(cr)
+	}
+
+	if st := cr.Status.CrashRecovery; st != nil && st.State != v1.CrashRecoveryRecovered {
+		st.State = v1.CrashRecoveryRecovered
+		st.Message = ""
+	}
+
+	if cr.Status.IsConditionTrue(v1.ConditionFullCrashRecovery) {
+		cr.Status.SetCondition(v1.ClusterCondition{
+			Type:               v1.ConditionFullCrashRecovery,
+			Status:             v1.ConditionFalse,
+			ObservedGeneration: cr.Generation,
+			Reason:             "Recovered",
+			Message:            "all pods are running after the full crash recovery",
+		})
 	}
 
 	return nil
 }
 
-func (r *ReconcilePerconaXtraDBCluster) isPodWaitingForRecovery(namespace, podName string) (bool, int64, error) {
+func (r *ReconcilePerconaXtraDBCluster) isPodWaitingForRecovery(namespace, podName string) (bool, error) {
+	pod := &corev1.Pod{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: podName}, pod)
+	if err != nil {
+		return false, errors.Wrapf(err, "get pod %s", podName)
+	}
+
+	st, err := r.podRecoveryState(pod)
+	return st != nil, err
+}
+
+// podRecoveryState returns the Galera state of the node or nil if it doesn't wait for the recovery
+func (r *ReconcilePerconaXtraDBCluster) podRecoveryState(pod *corev1.Pod) (*recoveryState, error) {
+	var outb, errb bytes.Buffer
+	err := r.clientcmd.Exec(pod, "pxc", []string{"/bin/sh", "-c", recoveryStateCmd}, nil, &outb, &errb, false)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get recovery state of %s: %s", pod.Name, strings.TrimSpace(errb.String()))
+	}
+
+	st, err := parseRecoveryState(outb.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse recovery state of %s", pod.Name)
+	}
+	if st == nil || st.UUID != "" || st.Seqno >= 0 {
+		return st, nil
+	}
+
+	// the recovery file is empty, it's the older entrypoint
+	st.Seqno, err = r.loggedSeqno(pod)
+	return st, errors.Wrapf(err, "get seqno of %s from logs", pod.Name)
+}
+
+// loggedSeqno returns the seqno the entrypoint of the older versions logs
+// while it waits for the recovery or -1 if it isn't found
+func (r *ReconcilePerconaXtraDBCluster) loggedSeqno(pod *corev1.Pod) (int64, error) {
 	logOpts := &corev1.PodLogOptions{
 		Container: "pxc",
 		TailLines: &logLinesRequired,
 	}
-	logLines, err := r.clientcmd.PodLogs(namespace, podName, logOpts)
+	logLines, err := r.clientcmd.PodLogs(pod.Namespace, pod.Name, logOpts)
 	if err != nil {
-		return false, -1, errors.Wrapf(err, "get logs from %s pod", podName)
+		return -1, errors.Wrap(err, "get logs")
 	}
 
 	for i := len(logLines) - 1; i >= 0; i-- {
 		if strings.HasPrefix(logLines[i], logPrefix) {
-			seq, err := parseSequence(logLines[i])
-			return true, seq, err
+			return parseSequence(logLines[i])
 		}
 	}
 
-	return false, -1, nil
+	return -1, nil
 }
 
 func parseSequence(log string) (int64, error) {
@@ -81,41 +146,130 @@ func parseSequence(log string) (int64, error) {
 	return seq, nil
 }
 
-func (r *ReconcilePerconaXtraDBCluster) doFullCrashRecovery(crName, namespace string, pxcSize int) error {
-	maxSeq := int64(-100)
-	maxSeqPod := ""
+// parseRecoveryState parses the output of recoveryStateCmd.
+// The state has the format of grastate.dat:
+//
+//	uuid:    9f0da5f1-3b37-11eb-a6a3-0e1c0c64b9f5
+//	seqno:   1234
+func parseRecoveryState(out string) (*recoveryState, error) {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if strings.TrimSpace(lines[0]) != "waiting" {
+		return nil, nil
+	}
+
+	st := &recoveryState{Seqno: -1}
+	for _, line := range lines[1:] {
+		kv := strings.SplitN(line, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
 
-	for i := 0; i < pxcSize; i++ {
-		podName := fmt.Sprintf("%s-pxc-%d", crName, i)
-		isPodWaitingForRecovery, seq, err := r.isPodWaitingForRecovery(namespace, podName)
+		val := strings.TrimSpace(kv[1])
+		switch strings.TrimSpace(kv[0]) {
+		case "uuid":
+			st.UUID = val
+		case "seqno":
+			if val == "" {
+				continue
+			}
+			seqno, err := strconv.ParseInt(val, 10, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parse seqno %q", val)
+			}
+			st.Seqno = seqno
+		}
+	}
+
+	return st, nil
+}
+
+func (r *ReconcilePerconaXtraDBCluster) doFullCrashRecovery(cr *v1.PerconaXtraDBCluster) error {
+	var recommended v1.CrashRecoveryMember
+	members := make([]v1.CrashRecoveryMember, 0, cr.Spec.PXC.Size)
+	pods := make(map[string]*corev1.Pod, cr.Spec.PXC.Size)
+
+	for i := 0; i < int(cr.Spec.PXC.Size); i++ {
+		pod := &corev1.Pod{}
+		err := r.client.Get(context.TODO(), types.NamespacedName{
+			Namespace: cr.Namespace,
+			Name:      fmt.Sprintf("%s-pxc-%d", cr.Name, i),
+		}, pod)
 		if err != nil {
-			return errors.Wrapf(err, "parse %s pod logs", podName)
+			return errors.Wrap(err, "get pods defenition")
 		}
 
-		if !isPodWaitingForRecovery {
+		st, err := r.podRecoveryState(pod)
+		if err != nil {
+			return err
+		}
+
+		if st == nil {
 			return nil
 		}
 
-		if seq > maxSeq {
-			maxSeq = seq
-			maxSeqPod = podName
+		m := v1.CrashRecoveryMember{Pod: pod.Name, UUID: st.UUID, Seqno: st.Seqno}
+		members = append(members, m)
+		pods[pod.Name] = pod
+		if i == 0 || m.Seqno > recommended.Seqno {
+			recommended = m
 		}
 	}
-	logger := r.logger(crName, namespace)
-	logger.Info("We are in full cluster crash, starting recovery")
-	logger.Info("Results of scanning sequences", "pod", maxSeqPod, "maxSeq", maxSeq)
+	logger := r.logger(cr.Name, cr.Namespace)
 
-	pod := &corev1.Pod{}
-	err := r.client.Get(context.TODO(), types.NamespacedName{
-		Namespace: namespace,
-		Name:      maxSeqPod,
-	}, pod)
-	if err != nil {
-		return errors.Wrap(err, "get pods defenition")
+	// requested bootstrap pod is removed from annotations before the status is changed,
+	// since the patch changes the cluster object
+	bootstrap, msg := bootstrapPod(cr, members, recommended)
+	if bootstrap != "" && cr.Annotations[v1.AnnotationBootstrapFrom] != "" {
+		err := r.removeBootstrapAnnotations(cr)
+		if err != nil {
+			return errors.Wrap(err, "remove bootstrap annotations")
+		}
+	}
+
+	st := cr.Status.CrashRecovery
+	if st == nil || st.State != v1.CrashRecoveryWaitingForApproval {
+		now := metav1.Now()
+		st = &v1.CrashRecoveryStatus{StartedAt: &now}
+		cr.Status.CrashRecovery = st
+	}
+	st.Members = members
+	st.RecommendedPod = recommended.Pod
+	st.Seqno = recommended.Seqno
+
+	if bootstrap == "" {
+		if st.State != v1.CrashRecoveryWaitingForApproval || st.Message != msg {
+			logger.Info("full crash recovery is waiting for approval", "reason", msg)
+			r.recorder.Event(cr, corev1.EventTypeWarning, "FullCrashRecoveryWaiting", msg)
+		}
+		st.State = v1.CrashRecoveryWaitingForApproval
+		st.Message = msg
+		cr.Status.SetCondition(v1.ClusterCondition{
+			Type:               v1.ConditionFullCrashRecovery,
+			Status:             v1.ConditionTrue,
+			ObservedGeneration: cr.Generation,
+			Reason:             "WaitingForApproval",
+			Message:            msg,
+		})
+		return nil
 	}
 
+	st.State = v1.CrashRecoveryBootstrapping
+	st.BootstrapPod = bootstrap
+	st.Message = ""
+	logger.Info("We are in full cluster crash, starting recovery")
+	logger.Info("Results of scanning sequences", "pod", recommended.Pod, "maxSeq", recommended.Seqno, "bootstrap pod", bootstrap)
+
+	cr.Status.SetCondition(v1.ClusterCondition{
+		Type:               v1.ConditionFullCrashRecovery,
+		Status:             v1.ConditionTrue,
+		ObservedGeneration: cr.Generation,
+		Reason:             "RecoveryStarted",
+		Message:            fmt.Sprintf("recovering from %s, the highest seqno is %d of %s", bootstrap, recommended.Seqno, recommended.Pod),
+	})
+	r.recorder.Eventf(cr, corev1.EventTypeWarning, "FullCrashRecovery", "full cluster crash, bootstrapping the cluster from %s, the highest seqno is %d of %s", bootstrap, recommended.Seqno, recommended.Pod)
+
 	stderrBuf := &bytes.Buffer{}
-	err = r.clientcmd.Exec(pod, "pxc", []string{"/bin/sh", "-c", "kill -s USR1 1"}, nil, nil, stderrBuf, false)
+	err := r.clientcmd.Exec(pods[bootstrap], "pxc", []string{"/bin/sh", "-c", "kill -s USR1 1"}, nil, nil, stderrBuf, false)
 	if err != nil {
 		return errors.Wrap(err, "exec command in pod")
 	}
@@ -131,6 +285,74 @@ func (r *ReconcilePerconaXtraDBCluster) doFullCrashRecovery(crName, namespace st
 	return nil
 }
 
+// bootstrapPod chooses the member to bootstrap the cluster from. It's the member requested
+// with the annotation or the recommended one with the highest seqno if the policy is Auto.
+// If the recovery has to wait for the user, the name is empty and the reason is returned.
+func bootstrapPod(cr *v1.PerconaXtraDBCluster, members []v1.CrashRecoveryMember, recommended v1.CrashRecoveryMember) (string, string) {
+	// seqno is -1 if mysqld couldn't recover the position, the newest member can't be chosen then
+	unknown := []string{}
+	for _, m := range members {
+		if m.Seqno < 0 {
+			unknown = append(unknown, m.Pod)
+		}
+	}
+
+	requested := cr.Annotations[v1.AnnotationBootstrapFrom]
+	if requested == "" && len(unknown) > 0 {
+		return "", fmt.Sprintf("seqno of %s is unknown, set annotation %s to the pod to bootstrap the cluster from and %s=true",
+			strings.Join(unknown, ", "), v1.AnnotationBootstrapFrom, v1.AnnotationBootstrapAcceptDataLoss)
+	}
+	if requested == "" {
+		if cr.Spec.PXC.RecoveryPolicy == v1.RecoveryPolicyManual {
+			return "", fmt.Sprintf("%s has the highest seqno %d, set annotation %s=%s to bootstrap the cluster from it",
+				recommended.Pod, recommended.Seqno, v1.AnnotationBootstrapFrom, recommended.Pod)
+		}
+		return recommended.Pod, ""
+	}
+
+	for _, m := range members {
+		if m.Pod != requested {
+			continue
+		}
+
+		if len(unknown) > 0 && cr.Annotations[v1.AnnotationBootstrapAcceptDataLoss] != "true" {
+			return "", fmt.Sprintf("seqno of %s is unknown, set annotation %s=true to bootstrap from %s and possibly lose the newer transactions",
+				strings.Join(unknown, ", "), v1.AnnotationBootstrapAcceptDataLoss, m.Pod)
+		}
+
+		lossy := m.Seqno < recommended.Seqno || (m.UUID != "" && recommended.UUID != "" && m.UUID != recommended.UUID)
+		if lossy && cr.Annotations[v1.AnnotationBootstrapAcceptDataLoss] != "true" {
+			return "", fmt.Sprintf("%s has seqno %d, but %s has the highest seqno %d, set annotation %s=true to bootstrap from %s and lose the newer transactions",
+				m.Pod, m.Seqno, recommended.Pod, recommended.Seqno, v1.AnnotationBootstrapAcceptDataLoss, m.Pod)
+		}
+		return m.Pod, ""
+	}
+
+	return "", fmt.Sprintf("pod %s from annotation %s isn't a member waiting for the recovery", requested, v1.AnnotationBootstrapFrom)
+}
+
+// removeBootstrapAnnotations removes annotations of the done recovery, so they aren't applied to the next one
+func (r *ReconcilePerconaXtraDBCluster) removeBootstrapAnnotations(cr *v1.PerconaXtraDBCluster) error {
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:null,%q:null}}}`, v1.AnnotationBootstrapFrom, v1.AnnotationBootstrapAcceptDataLoss)
+	patched := &v1.PerconaXtraDBCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cr.Name,
+			Namespace: cr.Namespace,
+		},
+	}
+	err := r.client.Patch(context.TODO(), patched, client.RawPatch(types.MergePatchType, []byte(patch)))
+	if err != nil {
+		return err
+	}
+
+	// the object is reconciled further and its status is written with the new version
+	delete(cr.Annotations, v1.AnnotationBootstrapFrom)
+	delete(cr.Annotations, v1.AnnotationBootstrapAcceptDataLoss)
+	cr.ResourceVersion = patched.ResourceVersion
+
+	return nil
+}
+
 func (r *ReconcilePerconaXtraDBCluster) checkIfPodsRunning(cr *v1.PerconaXtraDBCluster) error {
 	for i := 0; i < int(cr.Spec.PXC.Size); i++ {
 		podName := fmt.Sprintf("%s-pxc-%d", cr.Name, i)
@@ -0,0 +1,133 @@
+package pxc
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+)
+
+func TestParseRecoveryState(t *testing.T) {
+	tests := []struct {
+		name     string
+		out      string
+		expected *recoveryState
+	}{
+		{"not waiting", "", nil},
+		{"recovery file", "waiting\nuuid: 9f0da5f1-3b37-11eb-a6a3-0e1c0c64b9f5\nseqno: 1234\n",
+			&recoveryState{UUID: "9f0da5f1-3b37-11eb-a6a3-0e1c0c64b9f5", Seqno: 1234}},
+		{"grastate", "waiting\n# GALERA saved state\nversion: 2.1\nuuid:    9f0da5f1-3b37-11eb-a6a3-0e1c0c64b9f5\nseqno:   -1\nsafe_to_bootstrap: 0\n",
+			&recoveryState{UUID: "9f0da5f1-3b37-11eb-a6a3-0e1c0c64b9f5", Seqno: -1}},
+		{"no seqno", "waiting\n", &recoveryState{Seqno: -1}},
+	}
+
+	for _, tt := range tests {
+		st, err := parseRecoveryState(tt.out)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if (st == nil) != (tt.expected == nil) || (st != nil && *st != *tt.expected) {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.expected, st)
+		}
+	}
+
+	if _, err := parseRecoveryState("waiting\nseqno: abc\n"); err == nil {
+		t.Error("expected error on wrong seqno")
+	}
+}
+
+func TestBootstrapPod(t *testing.T) {
+	members := []api.CrashRecoveryMember{
+		{Pod: "cluster1-pxc-0", UUID: "uuid", Seqno: 10},
+		{Pod: "cluster1-pxc-1", UUID: "uuid", Seqno: 12},
+		{Pod: "cluster1-pxc-2", UUID: "uuid", Seqno: 12},
+	}
+	recommended := members[1]
+
+	tests := []struct {
+		name        string
+		policy      api.RecoveryPolicy
+		annotations map[string]string
+		expected    string
+	}{
+		{"auto", api.RecoveryPolicyAuto, nil, "cluster1-pxc-1"},
+		{"manual without approval", api.RecoveryPolicyManual, nil, ""},
+		{"manual approved", api.RecoveryPolicyManual, map[string]string{api.AnnotationBootstrapFrom: "cluster1-pxc-2"}, "cluster1-pxc-2"},
+		{"lower seqno", api.RecoveryPolicyAuto, map[string]string{api.AnnotationBootstrapFrom: "cluster1-pxc-0"}, ""},
+		{"lower seqno accepted", api.RecoveryPolicyManual, map[string]string{
+			api.AnnotationBootstrapFrom:           "cluster1-pxc-0",
+			api.AnnotationBootstrapAcceptDataLoss: "true",
+		}, "cluster1-pxc-0"},
+		{"unknown pod", api.RecoveryPolicyManual, map[string]string{api.AnnotationBootstrapFrom: "cluster1-pxc-5"}, ""},
+	}
+
+	for _, tt := range tests {
+		cr := &api.PerconaXtraDBCluster{
+			ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			Spec: api.PerconaXtraDBClusterSpec{
+				PXC: &api.PXCSpec{RecoveryPolicy: tt.policy},
+			},
+		}
+		pod, msg := bootstrapPod(cr, members, recommended)
+		if pod != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, pod)
+		}
+		if pod == "" && msg == "" {
+			t.Errorf("%s: no reason to wait", tt.name)
+		}
+	}
+}
+
+func TestBootstrapPodUnknownSeqno(t *testing.T) {
+	members := []api.CrashRecoveryMember{
+		{Pod: "cluster1-pxc-0", UUID: "uuid", Seqno: 10},
+		{Pod: "cluster1-pxc-1", UUID: "uuid", Seqno: -1},
+		{Pod: "cluster1-pxc-2", UUID: "uuid", Seqno: 12},
+	}
+	recommended := members[2]
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    string
+	}{
+		{"auto", nil, ""},
+		{"requested", map[string]string{api.AnnotationBootstrapFrom: "cluster1-pxc-2"}, ""},
+		{"requested with data loss", map[string]string{
+			api.AnnotationBootstrapFrom:           "cluster1-pxc-2",
+			api.AnnotationBootstrapAcceptDataLoss: "true",
+		}, "cluster1-pxc-2"},
+	}
+
+	for _, tt := range tests {
+		cr := &api.PerconaXtraDBCluster{
+			ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			Spec: api.PerconaXtraDBClusterSpec{
+				PXC: &api.PXCSpec{RecoveryPolicy: api.RecoveryPolicyAuto},
+			},
+		}
+		pod, msg := bootstrapPod(cr, members, recommended)
+		if pod != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, pod)
+		}
+		if pod == "" && msg == "" {
+			t.Errorf("%s: no reason to wait", tt.name)
+		}
+	}
+}
+
+func TestParseSequence(t *testing.T) {
+	seqno, err := parseSequence("#####################################################LAST_LINE:cluster1-pxc-0:1234:#####################################################")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seqno != 1234 {
+		t.Errorf("expected seqno 1234, got %d", seqno)
+	}
+
+	if _, err := parseSequence("#####################################################LAST_LINE:cluster1-pxc-0"); err == nil {
+		t.Error("expected error on wrong log line")
+	}
+}
@@ -0,0 +1,133 @@
+package pxc
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/statefulset"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/queries"
+)
+
+// membersStatusDue reports if the Galera status of members has to be collected again.
+// Every member is queried, so it's done once in pxc.membersStatusIntervalSeconds
+// and not on every reconcile.
+func membersStatusDue(cr *api.PerconaXtraDBCluster) bool {
+	checked := cr.Status.MembersCheckedAt
+	interval := time.Duration(cr.Spec.PXC.MembersStatusIntervalSeconds) * time.Second
+
+	return checked == nil || time.Since(checked.Time) >= interval
+}
+
+// membersStatus collects the Galera status of every PXC pod
+func (r *ReconcilePerconaXtraDBCluster) membersStatus(cr *api.PerconaXtraDBCluster) ([]api.MemberStatus, error) {
+	if cr.CompareVersionWith("1.6.0") < 0 || cr.Spec.Pause {
+		return nil, nil
+	}
+
+	pods := corev1.PodList{}
+	err := r.client.List(context.TODO(),
+		&pods,
+		&client.ListOptions{
+			Namespace:     cr.Namespace,
+			LabelSelector: labels.SelectorFromSet(statefulset.NewNode(cr).Labels()),
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "get pods list")
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+
+	running := 0
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodRunning {
+			running++
+		}
+	}
+
+	// the writer is known only if the proxy is up, otherwise asking it would just wait for the timeout
+	writer := ""
+	proxyReady := (cr.HAProxyEnabled() && cr.Status.HAProxy.Ready > 0) || (cr.ProxySQLEnabled() && cr.Status.ProxySQL.Ready > 0)
+	if running > 0 && proxyReady {
+		writer, err = queries.PrimaryPod(r.client, cr)
+		if err != nil {
+			r.logger(cr.Name, cr.Namespace).Info("failed to get the writer", "error", err.Error())
+		}
+	}
+
+	backupRunning, donors, err := r.backupDonors(cr)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]api.MemberStatus, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		m := api.MemberStatus{
+			Name: pod.Name,
+			Node: pod.Spec.NodeName,
+		}
+
+		if pod.Status.Phase == corev1.PodRunning {
+			st, err := queries.MemberWsrepStatus(r.client, cr, pod.Name)
+			if err != nil {
+				m.Message = err.Error()
+			}
+			m.State = st.LocalStateComment
+			m.ClusterStatus = st.ClusterStatus
+			m.LastCommitted = st.LastCommitted
+			m.FlowControlPaused = st.FlowControlPaused
+		}
+
+		if writer != "" {
+			m.Role = api.MemberRoleReader
+			if writer == pod.Name || writer == pod.Status.PodIP || strings.HasPrefix(writer, pod.Name+".") {
+				m.Role = api.MemberRoleWriter
+			}
+		}
+
+		// the donor of xtrabackup is known only after the backup is finished,
+		// but it's the only member desynced by the running backup
+		m.BackupDonor = donors[pod.Name] || (backupRunning && m.State == "Donor/Desynced")
+
+		members = append(members, m)
+	}
+
+	return members, nil
+}
+
+// backupDonors reports if there is a running backup of the cluster
+// and returns donors reported by running backups
+func (r *ReconcilePerconaXtraDBCluster) backupDonors(cr *api.PerconaXtraDBCluster) (bool, map[string]bool, error) {
+	bcpList := api.PerconaXtraDBClusterBackupList{}
+	err := r.client.List(context.TODO(), &bcpList, &client.ListOptions{Namespace: cr.Namespace})
+	if err != nil {
+		return false, nil, errors.Wrap(err, "get backups list")
+	}
+
+	running := false
+	donors := make(map[string]bool)
+	for _, bcp := range bcpList.Items {
+		if bcp.Spec.PXCCluster != cr.Name {
+			continue
+		}
+		if bcp.Status.State != api.BackupRunning && bcp.Status.State != api.BackupStarting {
+			continue
+		}
+
+		running = true
+		if bcp.Status.Donor != "" {
+			donors[bcp.Status.Donor] = true
+		}
+	}
+
+	return running, donors, nil
+}
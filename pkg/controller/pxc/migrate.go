@@ -0,0 +1,259 @@
+package pxc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/k8s"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/statefulset"
+)
+
+// migrateStorageClass moves datadir volumes of PXC to the storage class set in the volume spec.
+// The StatefulSet is recreated first, so it provisions new volumes with the new class.
+// Then members are moved one at a time: the PVC is replaced with the new one of the new class,
+// which is at least as big as the old volume, the pod is deleted and the new member gets the data with SST. It reports if the StatefulSet is being recreated.
+func (r *ReconcilePerconaXtraDBCluster) migrateStorageClass(cr *api.PerconaXtraDBCluster) (bool, error) {
+	vs := cr.Spec.PXC.VolumeSpec
+	if vs == nil || vs.PersistentVolumeClaim == nil || vs.PersistentVolumeClaim.StorageClassName == nil {
+		return false, nil
+	}
+	class := *vs.PersistentVolumeClaim.StorageClassName
+
+	sfs := statefulset.NewNode(cr)
+	sts := &appsv1.StatefulSet{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: sfs.StatefulSet().Name, Namespace: cr.Namespace}, sts)
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "get statefulset")
+	}
+	if sts.DeletionTimestamp != nil {
+		return true, nil
+	}
+
+	pvcs := corev1.PersistentVolumeClaimList{}
+	err = r.client.List(context.TODO(),
+		&pvcs,
+		&client.ListOptions{
+			Namespace:     cr.Namespace,
+			LabelSelector: labels.SelectorFromSet(sfs.Labels()),
+		},
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "get PVC list")
+	}
+
+	toMigrate, total := migrationMembers(cr, sts.Name, pvcs.Items, class)
+
+	var tmpl *corev1.PersistentVolumeClaim
+	for i := range sts.Spec.VolumeClaimTemplates {
+		if sts.Spec.VolumeClaimTemplates[i].Name == datadirVolume {
+			tmpl = &sts.Spec.VolumeClaimTemplates[i]
+		}
+	}
+	tmplClass := ""
+	if tmpl != nil {
+		tmplClass = storageClass(tmpl.Spec.StorageClassName)
+	}
+
+	if len(toMigrate) == 0 && tmplClass == class {
+		if cr.Status.StorageMigration != nil && cr.Status.StorageMigration.State == api.StorageMigrationInProgress {
+			cr.Status.StorageMigration.State = api.StorageMigrationDone
+			cr.Status.StorageMigration.Current = ""
+			cr.Status.StorageMigration.Message = ""
+			r.recorder.Eventf(cr, corev1.EventTypeNormal, "StorageMigrated", "datadir volumes are moved to storage class %s", class)
+		}
+		return false, nil
+	}
+
+	if cr.Status.StorageMigration == nil || cr.Status.StorageMigration.StorageClass != class {
+		now := metav1.Now()
+		cr.Status.StorageMigration = &api.StorageMigrationStatus{
+			State:        api.StorageMigrationInProgress,
+			StorageClass: class,
+			StartedAt:    &now,
+		}
+	}
+	st := cr.Status.StorageMigration
+	st.Total = total
+
+	// the StatefulSet isn't touched if the data can't be moved
+	if cr.Spec.PXC.Size < 2 {
+		st.State = api.StorageMigrationFailed
+		st.Message = "at least 2 members are needed to move data to the new volume with SST"
+		return false, nil
+	}
+
+	log := r.logger(cr.Name, cr.Namespace)
+
+	if tmpl == nil || tmplClass != class {
+		log.Info("recreating statefulset with the new storage class", "storageClass", class)
+		err = r.client.Delete(context.TODO(), sts,
+			client.PropagationPolicy(metav1.DeletePropagationOrphan),
+			&client.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &sts.UID}},
+		)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return false, errors.Wrap(err, "delete statefulset")
+		}
+		return true, nil
+	}
+
+	msg, err := r.migrationBlocked(cr)
+	if err != nil {
+		return false, err
+	}
+	st.State = api.StorageMigrationInProgress
+	st.Message = msg
+	if msg != "" {
+		return false, nil
+	}
+
+	pvc := &toMigrate[0]
+	podName := strings.TrimPrefix(pvc.Name, datadirVolume+"-")
+	st.Current = podName
+	err = r.writeStatus(cr)
+	if err != nil {
+		return false, errors.Wrap(err, "write status")
+	}
+
+	log.Info("moving member to the new storage class", "pod", podName, "storageClass", class)
+	r.recorder.Eventf(cr, corev1.EventTypeNormal, "StorageMigration", "moving %s to storage class %s", podName, class)
+
+	waitLimit := 2 * 60 * 60 // 2 hours
+	if cr.Spec.PXC.LivenessInitialDelaySeconds != nil {
+		waitLimit = int(*cr.Spec.PXC.LivenessInitialDelaySeconds)
+	}
+
+	err = r.waitVolumeReplaced(cr.Namespace, podName, pvc.UID, migrationPVC(tmpl, pvc), waitLimit)
+	if err != nil {
+		st.State = api.StorageMigrationFailed
+		st.Message = fmt.Sprintf("%s: %v", podName, err)
+		return false, errors.Wrapf(err, "move %s", podName)
+	}
+
+	err = r.waitPXCSynced(cr, podName+"."+cr.Name+"-pxc."+cr.Namespace, waitLimit)
+	if err != nil {
+		st.State = api.StorageMigrationFailed
+		st.Message = fmt.Sprintf("%s: %v", podName, err)
+		return false, errors.Wrap(err, "failed to wait pxc sync")
+	}
+
+	log.Info("member is moved to the new storage class", "pod", podName)
+	st.Migrated = append(st.Migrated, podName)
+	st.Current = ""
+
+	return false, nil
+}
+
+// migrationMembers returns datadir PVCs of the members which aren't in the given storage class yet,
+// members with the highest ordinals go first, the same order as the smart update has.
+// It also returns the number of members the migration covers.
+func migrationMembers(cr *api.PerconaXtraDBCluster, stsName string, pvcs []corev1.PersistentVolumeClaim, class string) ([]corev1.PersistentVolumeClaim, int32) {
+	sort.Slice(pvcs, func(i, j int) bool {
+		return pvcs[i].Name > pvcs[j].Name
+	})
+
+	toMigrate := []corev1.PersistentVolumeClaim{}
+	total := int32(0)
+	for _, pvc := range pvcs {
+		if !strings.HasPrefix(pvc.Name, datadirVolume+"-") {
+			continue
+		}
+		// volumes left after scaling down have no member to get data with SST
+		ord, err := k8s.PodOrderInSts(stsName, strings.TrimPrefix(pvc.Name, datadirVolume+"-"))
+		if err != nil || int32(ord) >= cr.Spec.PXC.Size {
+			continue
+		}
+		total++
+		if storageClass(pvc.Spec.StorageClassName) != class {
+			toMigrate = append(toMigrate, pvc)
+		}
+	}
+
+	return toMigrate, total
+}
+
+// migrationBlocked returns the reason why a member can't be moved to the new volume now.
+// A member is taken out of the cluster only if the rest of it is healthy.
+func (r *ReconcilePerconaXtraDBCluster) migrationBlocked(cr *api.PerconaXtraDBCluster) (string, error) {
+	if cr.Spec.Pause {
+		return "cluster is paused", nil
+	}
+	if cr.Status.PXC.Ready < cr.Spec.PXC.Size {
+		return "waiting for all members to be ready", nil
+	}
+
+	running, err := k8s.IsBackupRunning(r.client, cr)
+	if err != nil {
+		return "", err
+	}
+	if running {
+		return "waiting for the backup to finish", nil
+	}
+	running, err = k8s.IsRestoreRunning(r.client, cr.Name, cr.Namespace)
+	if err != nil {
+		return "", err
+	}
+	if running {
+		return "waiting for the restore to finish", nil
+	}
+
+	return "", nil
+}
+
+// migrationPVC returns the new datadir PVC of the member. It's created from the StatefulSet template,
+// so it has the new storage class, and it's not smaller than the volume it replaces.
+func migrationPVC(tmpl *corev1.PersistentVolumeClaim, old *corev1.PersistentVolumeClaim) *corev1.PersistentVolumeClaim {
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      old.Name,
+			Namespace: old.Namespace,
+			Labels:    old.Labels,
+		},
+		Spec: *tmpl.Spec.DeepCopy(),
+	}
+
+	size := tmpl.Spec.Resources.Requests[corev1.ResourceStorage]
+	for _, q := range []resource.Quantity{old.Spec.Resources.Requests[corev1.ResourceStorage], old.Status.Capacity[corev1.ResourceStorage]} {
+		if q.Cmp(size) > 0 {
+			size = q
+		}
+	}
+	if pvc.Spec.Resources.Requests == nil {
+		pvc.Spec.Resources.Requests = corev1.ResourceList{}
+	}
+	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = size
+
+	return pvc
+}
+
+// waitVolumeReplaced waits until the pod is ready with the new volume.
+func (r *ReconcilePerconaXtraDBCluster) waitVolumeReplaced(namespace, podName string, oldUID types.UID, newPVC *corev1.PersistentVolumeClaim, waitLimit int) error {
+	return retry(time.Second*10, time.Duration(waitLimit)*time.Second,
+		func() (bool, error) {
+			return k8s.ReplaceVolume(r.client, namespace, podName, datadirVolume+"-"+podName, oldUID, newPVC)
+		})
+}
+
+func storageClass(name *string) string {
+	if name == nil {
+		return ""
+	}
+
+	return *name
+}
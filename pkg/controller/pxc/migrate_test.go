@@ -0,0 +1,182 @@
+package pxc
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/k8s"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/statefulset"
+)
+
+func TestMigrationMembers(t *testing.T) {
+	cr := newVolumeCR("6Gi")
+	stsName := statefulset.NewNode(cr).StatefulSet().Name
+	pvcs := []corev1.PersistentVolumeClaim{
+		*newDatadirPVC(cr, "cr-mock-pxc-0", "standard", "6Gi", "6Gi"),
+		*newDatadirPVC(cr, "cr-mock-pxc-1", "fast", "6Gi", "6Gi"),
+		*newDatadirPVC(cr, "cr-mock-pxc-2", "standard", "6Gi", "6Gi"),
+		// left after scaling down
+		*newDatadirPVC(cr, "cr-mock-pxc-3", "standard", "6Gi", "6Gi"),
+		*newDatadirPVC(cr, "cr-mock-proxysql-0", "standard", "6Gi", "6Gi"),
+	}
+	pvcs[4].Name = "proxydata-cr-mock-proxysql-0"
+
+	toMigrate, total := migrationMembers(cr, stsName, pvcs, "fast")
+	if total != 3 {
+		t.Errorf("expected 3 members, got %d", total)
+	}
+	got := []string{}
+	for _, pvc := range toMigrate {
+		got = append(got, pvc.Name)
+	}
+	want := []string{"datadir-cr-mock-pxc-2", "datadir-cr-mock-pxc-0"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestMigrateStorageClassWaitsForReady(t *testing.T) {
+	class := "fast"
+	cr := newVolumeCR("6Gi")
+	cr.Spec.PXC.VolumeSpec.PersistentVolumeClaim.StorageClassName = &class
+	cr.Status.PXC.Ready = 2
+	sts := newVolumeSts(cr, "6Gi")
+	sts.Spec.VolumeClaimTemplates[0].Spec.StorageClassName = &class
+	r := buildFakeClient([]runtime.Object{
+		cr,
+		sts,
+		newDatadirPVC(cr, "cr-mock-pxc-0", "standard", "6Gi", "6Gi"),
+		newDatadirPVC(cr, "cr-mock-pxc-1", "standard", "6Gi", "6Gi"),
+		newDatadirPVC(cr, "cr-mock-pxc-2", "standard", "6Gi", "6Gi"),
+	})
+
+	recreating, err := r.migrateStorageClass(cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recreating {
+		t.Error("statefulset with the new storage class is recreated")
+	}
+	st := cr.Status.StorageMigration
+	if st == nil || st.State != api.StorageMigrationInProgress || st.Total != 3 || st.Current != "" || st.Message != "waiting for all members to be ready" {
+		t.Fatalf("unexpected status %+v", st)
+	}
+	for _, name := range []string{"datadir-cr-mock-pxc-0", "datadir-cr-mock-pxc-1", "datadir-cr-mock-pxc-2"} {
+		pvc := &corev1.PersistentVolumeClaim{}
+		err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: cr.Namespace}, pvc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pvc.DeletionTimestamp != nil {
+			t.Errorf("%s is deleted while not all members are ready", name)
+		}
+	}
+}
+
+func TestMigrateStorageClassSingleMember(t *testing.T) {
+	class := "fast"
+	cr := newVolumeCR("6Gi")
+	cr.Spec.PXC.Size = 1
+	cr.Spec.PXC.VolumeSpec.PersistentVolumeClaim.StorageClassName = &class
+	sts := newVolumeSts(cr, "6Gi")
+	r := buildFakeClient([]runtime.Object{
+		cr,
+		sts,
+		newDatadirPVC(cr, "cr-mock-pxc-0", "standard", "6Gi", "6Gi"),
+	})
+
+	recreating, err := r.migrateStorageClass(cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recreating || !stsExists(t, r, cr) {
+		t.Error("statefulset is recreated while the data can't be moved")
+	}
+	st := cr.Status.StorageMigration
+	if st == nil || st.State != api.StorageMigrationFailed {
+		t.Fatalf("expected the migration to fail, got %+v", st)
+	}
+}
+
+func TestMigrationPVC(t *testing.T) {
+	class := "fast"
+	cr := newVolumeCR("6Gi")
+	tmpl := &newVolumeSts(cr, "6Gi").Spec.VolumeClaimTemplates[0]
+	tmpl.Spec.StorageClassName = &class
+
+	tests := []struct {
+		name     string
+		size     string
+		capacity string
+		want     string
+	}{
+		{"template", "5Gi", "5Gi", "6Gi"},
+		{"request", "8Gi", "8Gi", "8Gi"},
+		{"capacity", "6Gi", "7Gi", "7Gi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := newDatadirPVC(cr, "cr-mock-pxc-0", "standard", tt.size, tt.capacity)
+			pvc := migrationPVC(tmpl, old)
+			size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+			if size.String() != tt.want {
+				t.Errorf("expected size %s, got %s", tt.want, size.String())
+			}
+			if storageClass(pvc.Spec.StorageClassName) != class || pvc.Name != old.Name {
+				t.Errorf("unexpected pvc %s of class %s", pvc.Name, storageClass(pvc.Spec.StorageClassName))
+			}
+		})
+	}
+	if size := tmpl.Spec.Resources.Requests[corev1.ResourceStorage]; size.String() != "6Gi" {
+		t.Errorf("template is changed to %s", size.String())
+	}
+}
+
+func TestReplaceVolume(t *testing.T) {
+	class := "fast"
+	cr := newVolumeCR("6Gi")
+	tmpl := &newVolumeSts(cr, "6Gi").Spec.VolumeClaimTemplates[0]
+	tmpl.Spec.StorageClassName = &class
+	old := newDatadirPVC(cr, "cr-mock-pxc-0", "standard", "8Gi", "8Gi")
+	old.UID = "old"
+	r := buildFakeClient([]runtime.Object{cr, old})
+
+	done, err := k8s.ReplaceVolume(r.client, cr.Namespace, "cr-mock-pxc-0", old.Name, old.UID, migrationPVC(tmpl, old))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done {
+		t.Fatal("volume is replaced while the old one is there")
+	}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: old.Name, Namespace: cr.Namespace}, &corev1.PersistentVolumeClaim{})
+	if !k8serrors.IsNotFound(err) {
+		t.Fatalf("expected the old pvc to be deleted, got %v", err)
+	}
+
+	done, err = k8s.ReplaceVolume(r.client, cr.Namespace, "cr-mock-pxc-0", old.Name, old.UID, migrationPVC(tmpl, old))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done {
+		t.Fatal("volume is replaced without the pod")
+	}
+	pvc := &corev1.PersistentVolumeClaim{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: old.Name, Namespace: cr.Namespace}, pvc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; storageClass(pvc.Spec.StorageClassName) != class || size.String() != "8Gi" {
+		t.Errorf("expected new pvc of class %s and size 8Gi, got %s and %s", class, storageClass(pvc.Spec.StorageClassName), size.String())
+	}
+}
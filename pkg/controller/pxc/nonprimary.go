@@ -0,0 +1,172 @@
+package pxc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/statefulset"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/queries"
+)
+
+const wsrepClusterPrimary = "Primary"
+
+// reconcileNonPrimary reports members out of the Primary component and, if the policy is Auto,
+// bootstraps the Primary component once there is none for the grace period.
+// Members are taken from the status collected by the previous reconcile
+// and are queried again right before the bootstrap.
+func (r *ReconcilePerconaXtraDBCluster) reconcileNonPrimary(cr *api.PerconaXtraDBCluster) error {
+	if cr.Spec.Pause || cr.Spec.PXC.NonPrimaryRecovery == nil {
+		return nil
+	}
+
+	primary, nonPrimary := []string{}, []string{}
+	for _, m := range cr.Status.Members {
+		switch m.ClusterStatus {
+		case "":
+		case wsrepClusterPrimary:
+			primary = append(primary, m.Name)
+		default:
+			nonPrimary = append(nonPrimary, m.Name)
+		}
+	}
+
+	if len(nonPrimary) == 0 {
+		if cr.Status.NonPrimary != nil {
+			r.recorder.Event(cr, corev1.EventTypeNormal, "PrimaryComponentRestored", "all members are in the Primary component")
+			cr.Status.NonPrimary = nil
+		}
+		return nil
+	}
+
+	st := cr.Status.NonPrimary
+	if st == nil {
+		now := metav1.Now()
+		st = &api.NonPrimaryStatus{Since: &now}
+		cr.Status.NonPrimary = st
+	}
+	st.Members = nonPrimary
+
+	setMessage := func(msg string) {
+		if st.Message != msg {
+			r.logger(cr.Name, cr.Namespace).Info(msg)
+			r.recorder.Event(cr, corev1.EventTypeWarning, "NonPrimaryComponent", msg)
+		}
+		st.Message = msg
+	}
+
+	// the minority of the partition rejoins the Primary component by itself
+	if len(primary) > 0 {
+		setMessage(fmt.Sprintf("members %s are out of the Primary component of %s",
+			strings.Join(nonPrimary, ", "), strings.Join(primary, ", ")))
+		return nil
+	}
+
+	setMessage(fmt.Sprintf("there is no Primary component, members %s are in non-Primary components", strings.Join(nonPrimary, ", ")))
+
+	spec := cr.Spec.PXC.NonPrimaryRecovery
+	if spec.Policy != api.NonPrimaryPolicyAuto {
+		return nil
+	}
+
+	grace := time.Duration(spec.GracePeriodSeconds) * time.Second
+	if time.Since(st.Since.Time) < grace || (st.BootstrappedAt != nil && time.Since(st.BootstrappedAt.Time) < grace) {
+		return nil
+	}
+
+	return r.bootstrapPrimaryComponent(cr, st)
+}
+
+// bootstrapPrimaryComponent makes the member with the highest last committed seqno the new Primary component.
+// It's done only if all members and the arbitrator are running, every member is reachable and
+// reports it's out of the Primary component. A member which can't be checked may be in the Primary
+// component, there would be two Primary components with diverged data then, so the bootstrap is refused.
+func (r *ReconcilePerconaXtraDBCluster) bootstrapPrimaryComponent(cr *api.PerconaXtraDBCluster, st *api.NonPrimaryStatus) error {
+	refuse := func(msg string) error {
+		msg = "the Primary component isn't bootstrapped, " + msg
+		if st.Message != msg {
+			r.logger(cr.Name, cr.Namespace).Info(msg)
+			r.recorder.Event(cr, corev1.EventTypeWarning, "NonPrimaryComponent", msg)
+		}
+		st.Message = msg
+		return nil
+	}
+
+	sfs := statefulset.NewNode(cr).StatefulSet()
+	members := make([]string, 0, cr.Spec.PXC.Size)
+	for i := int32(0); i < cr.Spec.PXC.Size; i++ {
+		name := fmt.Sprintf("%s-%d", sfs.Name, i)
+		pod := &corev1.Pod{}
+		err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: cr.Namespace}, pod)
+		if k8serrors.IsNotFound(err) {
+			return refuse(fmt.Sprintf("%s doesn't exist", name))
+		}
+		if err != nil {
+			return errors.Wrapf(err, "get pod %s", name)
+		}
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			return refuse(fmt.Sprintf("%s isn't running", name))
+		}
+		members = append(members, name)
+	}
+
+	if cr.ArbitratorEnabled() {
+		running, err := r.arbitratorRunning(cr)
+		if err != nil {
+			return errors.Wrap(err, "check arbitrator")
+		}
+		if !running {
+			return refuse("the arbitrator isn't running")
+		}
+	}
+
+	best := ""
+	bestSeqno := int64(-1)
+	for _, name := range members {
+		ws, err := queries.MemberWsrepStatus(r.client, cr, name)
+		if err != nil {
+			return refuse(fmt.Sprintf("%s is unreachable: %v", name, err))
+		}
+		switch ws.ClusterStatus {
+		case "":
+			return refuse(fmt.Sprintf("%s doesn't report its cluster status", name))
+		case wsrepClusterPrimary:
+			return refuse(fmt.Sprintf("%s is in the Primary component", name))
+		}
+		if best == "" || ws.LastCommitted > bestSeqno {
+			best = name
+			bestSeqno = ws.LastCommitted
+		}
+	}
+	if best == "" {
+		return nil
+	}
+
+	database, err := queries.New(r.client, cr.Namespace, "internal-"+cr.Name, "operator", best+"."+cr.Name+"-pxc."+cr.Namespace, 33062)
+	if err != nil {
+		return errors.Wrap(err, "failed to access PXC database")
+	}
+	defer database.Close()
+
+	err = database.BootstrapPrimaryComponent()
+	if err != nil {
+		return errors.Wrapf(err, "bootstrap Primary component on %s", best)
+	}
+
+	now := metav1.Now()
+	st.BootstrapPod = best
+	st.BootstrappedAt = &now
+	st.Message = fmt.Sprintf("the Primary component is bootstrapped on %s with the last committed seqno %d", best, bestSeqno)
+	r.logger(cr.Name, cr.Namespace).Info("bootstrapped the Primary component", "pod", best, "seqno", bestSeqno)
+	r.recorder.Event(cr, corev1.EventTypeWarning, "PrimaryComponentBootstrapped", st.Message)
+
+	return nil
+}
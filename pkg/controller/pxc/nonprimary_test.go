@@ -0,0 +1,62 @@
+package pxc
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/statefulset"
+)
+
+func TestBootstrapPrimaryComponentRefused(t *testing.T) {
+	running := podStatusReady
+	running.Phase = corev1.PodRunning
+
+	tests := []struct {
+		name       string
+		pods       int
+		arbitrator bool
+		expected   string
+	}{
+		{"missing member", 2, false, "cr-mock-pxc-2 doesn't exist"},
+		{"arbitrator isn't running", 3, true, "the arbitrator isn't running"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := newCR("cr-mock", "pxc")
+			if tt.arbitrator {
+				cr.Spec.Arbitrator = &api.PodSpec{Enabled: true, Image: "percona/percona-xtradb-cluster:8.0"}
+			}
+			objs := []runtime.Object{cr}
+			for i := 0; i < tt.pods; i++ {
+				objs = append(objs, newMockPod(fmt.Sprintf("cr-mock-pxc-%d", i), cr.Namespace, statefulset.NewNode(cr).Labels(), running))
+			}
+			r := buildFakeClient(objs)
+			recorder := record.NewFakeRecorder(10)
+			r.recorder = recorder
+
+			now := metav1.Now()
+			st := &api.NonPrimaryStatus{Since: &now}
+			err := r.bootstrapPrimaryComponent(cr, st)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if st.BootstrappedAt != nil || st.BootstrapPod != "" {
+				t.Fatalf("the Primary component is bootstrapped on %s", st.BootstrapPod)
+			}
+			if !strings.HasSuffix(st.Message, tt.expected) {
+				t.Errorf("expected message %q, got %q", tt.expected, st.Message)
+			}
+			if len(recorder.Events) != 1 {
+				t.Errorf("expected the refusal to be alerted with an event, got %d events", len(recorder.Events))
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/k8s"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -18,7 +19,7 @@ func (r *ReconcilePerconaXtraDBCluster) ensurePxcPodServices(cr *api.PerconaXtra
 		return nil
 	}
 
-	isBackupRunning, err := r.isBackupRunning(cr)
+	isBackupRunning, err := k8s.IsBackupRunning(r.client, cr)
 	if err != nil {
 		return errors.Wrap(err, "failed to check if backup is running")
 	}
@@ -27,7 +28,7 @@ func (r *ReconcilePerconaXtraDBCluster) ensurePxcPodServices(cr *api.PerconaXtra
 		return nil
 	}
 
-	isRestoreRunning, err := r.isRestoreRunning(cr.Name, cr.Namespace)
+	isRestoreRunning, err := k8s.IsRestoreRunning(r.client, cr.Name, cr.Namespace)
 	if err != nil {
 		return errors.Wrap(err, "failed to check if restore is running")
 	}
@@ -0,0 +1,304 @@
+package pxc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/k8s"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/statefulset"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/queries"
+)
+
+// corruptionSignatures are log messages of mysqld and Galera which mean
+// the datadir can't be used anymore and the member has to get the data with SST
+var corruptionSignatures = []struct {
+	name    string
+	pattern string
+}{
+	{"InnoDBAssertion", "InnoDB: Assertion failure"},
+	{"InnoDBAssertion", "[InnoDB] Assertion failure"},
+	{"InnoDBPageCorruption", "Database page corruption"},
+	{"InnoDBCorruptedPage", "[InnoDB] Corrupt"},
+	{"InnoDBLSNInFuture", "is in the future!"},
+	{"InnoDBSystemTablespace", "Could not open or create the system tablespace"},
+	{"GaleraGCacheCorruption", "Corrupt buffer header"},
+	{"GaleraStateCorruption", "Failed to parse grastate"},
+	{"GaleraStateCorruption", "Corrupt grastate"},
+}
+
+// crashLogLines is the number of the last lines of the crashed container logs checked for the corruption
+var crashLogLines int64 = 300
+
+// reconcileReseed finds members crash looping on the corrupted datadir and, if the policy is Auto,
+// re-seeds them one at a time: the datadir volume and the pod are deleted and the new member
+// gets the data with SST. It's done only if the rest of the cluster has the quorum.
+func (r *ReconcilePerconaXtraDBCluster) reconcileReseed(cr *api.PerconaXtraDBCluster) error {
+	if cr.Spec.Pause || cr.Spec.PXC.Reseed == nil {
+		return nil
+	}
+
+	if cr.Status.Reseed != nil && cr.Status.Reseed.Pod != "" {
+		return r.checkReseed(cr)
+	}
+
+	pods := corev1.PodList{}
+	err := r.client.List(context.TODO(),
+		&pods,
+		&client.ListOptions{
+			Namespace:     cr.Namespace,
+			LabelSelector: labels.SelectorFromSet(statefulset.NewNode(cr).Labels()),
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "get pods list")
+	}
+
+	known := map[string]bool{}
+	if cr.Status.Reseed != nil {
+		for _, m := range cr.Status.Reseed.Corrupted {
+			known[m.Pod] = true
+		}
+	}
+
+	corrupted := []api.CorruptedMember{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		restarts, crashLooping := pxcCrashLoop(pod)
+		if !crashLooping || restarts < cr.Spec.PXC.Reseed.RestartThreshold {
+			continue
+		}
+
+		logs, err := r.clientcmd.PodLogs(pod.Namespace, pod.Name, &corev1.PodLogOptions{
+			Container: "pxc",
+			Previous:  true,
+			TailLines: &crashLogLines,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "get logs of %s", pod.Name)
+		}
+
+		signature, line := corruptionSignature(logs)
+		if signature == "" {
+			continue
+		}
+
+		m := api.CorruptedMember{Pod: pod.Name, Restarts: restarts, Signature: signature, LogLine: line}
+		corrupted = append(corrupted, m)
+		if !known[pod.Name] {
+			r.logger(cr.Name, cr.Namespace).Info("member crash loops on the corrupted datadir", "pod", pod.Name, "signature", signature)
+			r.recorder.Eventf(cr, corev1.EventTypeWarning, "DatadirCorrupted",
+				"%s crash loops after %d restarts with %s in the logs: %s", pod.Name, restarts, signature, line)
+		}
+	}
+
+	if len(corrupted) == 0 {
+		if cr.Status.Reseed == nil || cr.Status.Reseed.Failed == "" || reseedRecovered(pods.Items, cr.Status.Reseed.Failed) {
+			cr.Status.Reseed = nil
+			return nil
+		}
+		// the failure is kept until the member is ready
+		cr.Status.Reseed.Corrupted = nil
+		return nil
+	}
+
+	if cr.Status.Reseed == nil {
+		cr.Status.Reseed = &api.ReseedStatus{}
+	}
+	st := cr.Status.Reseed
+	st.Corrupted = corrupted
+
+	setMessage := func(msg string) {
+		if st.Message != msg {
+			r.recorder.Event(cr, corev1.EventTypeWarning, "ReseedPostponed", msg)
+		}
+		st.Message = msg
+	}
+
+	if cr.Spec.PXC.Reseed.Policy != api.ReseedPolicyAuto {
+		st.Message = "set pxc.reseed.policy to Auto or delete the datadir volume and the pod of the member to re-seed it"
+		return nil
+	}
+
+	var victim *api.CorruptedMember
+	for i := range corrupted {
+		if corrupted[i].Pod != st.Failed {
+			victim = &corrupted[i]
+			break
+		}
+	}
+	if victim == nil {
+		st.Message = fmt.Sprintf("re-seed of %s is failed, delete the datadir volume and the pod of the member to re-seed it again", st.Failed)
+		return nil
+	}
+
+	running, err := k8s.IsBackupRunning(r.client, cr)
+	if err != nil {
+		return err
+	}
+	if running {
+		setMessage(fmt.Sprintf("%s isn't re-seeded while the backup is running", victim.Pod))
+		return nil
+	}
+	running, err = k8s.IsRestoreRunning(r.client, cr.Name, cr.Namespace)
+	if err != nil {
+		return err
+	}
+	if running {
+		setMessage(fmt.Sprintf("%s isn't re-seeded while the restore is running", victim.Pod))
+		return nil
+	}
+
+	synced := []string{}
+	for _, pod := range pods.Items {
+		if pod.Name == victim.Pod || !k8s.IsPodReady(&pod) {
+			continue
+		}
+		ws, err := queries.MemberWsrepStatus(r.client, cr, pod.Name)
+		if err != nil {
+			continue
+		}
+		if ws.ClusterStatus == wsrepClusterPrimary && ws.LocalStateComment == "Synced" {
+			synced = append(synced, pod.Name)
+		}
+	}
+
+	arbitratorRunning := false
+	if cr.ArbitratorEnabled() {
+		arbitratorRunning, err = r.arbitratorRunning(cr)
+		if err != nil {
+			return err
+		}
+	}
+	votes, total := quorumVotes(cr, len(synced), arbitratorRunning)
+	if votes*2 <= total {
+		setMessage(fmt.Sprintf("%s isn't re-seeded, only %d of %d votes are in the Primary component", victim.Pod, votes, total))
+		return nil
+	}
+
+	pvcName := datadirVolume + "-" + victim.Pod
+	pvc := &corev1.PersistentVolumeClaim{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: pvcName, Namespace: cr.Namespace}, pvc)
+	if err != nil {
+		return errors.Wrapf(err, "get pvc %s", pvcName)
+	}
+
+	now := metav1.Now()
+	st.Pod = victim.Pod
+	st.PVCUID = pvc.UID
+	st.StartedAt = &now
+	st.Message = fmt.Sprintf("%s is re-seeded with SST from %s", victim.Pod, strings.Join(synced, ", "))
+	// the status has to be saved before the volume is gone, the next reconcile continues from it
+	err = r.writeStatus(cr)
+	if err != nil {
+		return errors.Wrap(err, "write status")
+	}
+
+	_, err = k8s.ReplaceVolume(r.client, cr.Namespace, victim.Pod, pvcName, pvc.UID, nil)
+	if err != nil {
+		return errors.Wrapf(err, "replace datadir volume of %s", victim.Pod)
+	}
+
+	r.logger(cr.Name, cr.Namespace).Info("re-seeding member", "pod", victim.Pod, "signature", victim.Signature)
+	r.recorder.Eventf(cr, corev1.EventTypeWarning, "MemberReseed", "datadir volume of %s is deleted, the member gets the data with SST", victim.Pod)
+
+	return nil
+}
+
+// checkReseed follows the member being re-seeded until it's synced with the new volume.
+// The re-seed is failed if it takes more than pxc.reseed.timeoutSeconds.
+func (r *ReconcilePerconaXtraDBCluster) checkReseed(cr *api.PerconaXtraDBCluster) error {
+	st := cr.Status.Reseed
+
+	timeout := time.Duration(cr.Spec.PXC.Reseed.TimeoutSeconds) * time.Second
+	if st.StartedAt != nil && time.Since(st.StartedAt.Time) > timeout {
+		msg := fmt.Sprintf("%s isn't synced %s after the datadir volume is deleted, the re-seed is failed", st.Pod, timeout)
+		r.logger(cr.Name, cr.Namespace).Info("re-seed failed", "pod", st.Pod, "timeout", timeout)
+		r.recorder.Event(cr, corev1.EventTypeWarning, "ReseedFailed", msg)
+		st.Failed = st.Pod
+		st.Pod = ""
+		st.PVCUID = ""
+		st.StartedAt = nil
+		st.Message = msg
+		return nil
+	}
+
+	ready, err := k8s.ReplaceVolume(r.client, cr.Namespace, st.Pod, datadirVolume+"-"+st.Pod, st.PVCUID, nil)
+	if err != nil {
+		return errors.Wrapf(err, "replace datadir volume of %s", st.Pod)
+	}
+	if !ready {
+		return nil
+	}
+
+	ws, err := queries.MemberWsrepStatus(r.client, cr, st.Pod)
+	if err != nil || ws.LocalStateComment != "Synced" {
+		return nil
+	}
+
+	r.logger(cr.Name, cr.Namespace).Info("member is re-seeded", "pod", st.Pod)
+	r.recorder.Eventf(cr, corev1.EventTypeNormal, "MemberReseeded", "%s got the data with SST and is synced", st.Pod)
+	cr.Status.Reseed = nil
+
+	return nil
+}
+
+// quorumVotes returns the votes in the Primary component and the total of votes.
+// garbd is a vote too.
+func quorumVotes(cr *api.PerconaXtraDBCluster, synced int, arbitratorRunning bool) (int32, int32) {
+	votes, total := int32(synced), cr.Spec.PXC.Size
+	if cr.ArbitratorEnabled() {
+		total++
+		if arbitratorRunning {
+			votes++
+		}
+	}
+
+	return votes, total
+}
+
+// reseedRecovered reports if the member which failed the re-seed is ready now
+func reseedRecovered(pods []corev1.Pod, name string) bool {
+	for i := range pods {
+		if pods[i].Name == name {
+			return k8s.IsPodReady(&pods[i])
+		}
+	}
+
+	return false
+}
+
+// pxcCrashLoop returns restarts of the pxc container and reports if it's crash looping
+func pxcCrashLoop(pod *corev1.Pod) (int32, bool) {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name != "pxc" {
+			continue
+		}
+		crashLooping := cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff"
+		return cs.RestartCount, crashLooping
+	}
+
+	return 0, false
+}
+
+// corruptionSignature returns the name of the first corruption signature found in the logs and the matched line
+func corruptionSignature(logs []string) (string, string) {
+	for _, line := range logs {
+		for _, s := range corruptionSignatures {
+			if strings.Contains(line, s.pattern) {
+				return s.name, strings.TrimSpace(line)
+			}
+		}
+	}
+
+	return "", ""
+}
@@ -0,0 +1,113 @@
+package pxc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+)
+
+func TestCorruptionSignature(t *testing.T) {
+	tests := []struct {
+		name      string
+		logs      []string
+		signature string
+	}{
+		{"clean", []string{"[Note] [MY-000000] [Galera] Shifting OPEN -> PRIMARY", "[Warning] [MY-000000] [Galera] Member 1 requested state transfer"}, ""},
+		{"innodb 8.0", []string{"2021-01-12T10:00:00.000000Z 0 [ERROR] [MY-013183] [InnoDB] Assertion failure: fil0fil.cc:8765:ib::fatal triggered thread 140"}, "InnoDBAssertion"},
+		{"innodb 5.7", []string{"2021-01-12 10:00:00 0x7f InnoDB: Assertion failure in thread 140 in file buf0buf.cc line 5023"}, "InnoDBAssertion"},
+		{"page", []string{"[ERROR] InnoDB: Database page corruption on disk or a failed file read of page [page id: space=0, page number=5]"}, "InnoDBPageCorruption"},
+		{"gcache", []string{"[ERROR] WSREP: Corrupt buffer header: addr: 0x7f, seqno: 123, size: 0, ctx: 0x0, flags: 0."}, "GaleraGCacheCorruption"},
+	}
+
+	for _, tt := range tests {
+		signature, line := corruptionSignature(tt.logs)
+		if signature != tt.signature {
+			t.Errorf("%s: expected signature %q, got %q", tt.name, tt.signature, signature)
+		}
+		if signature != "" && line == "" {
+			t.Errorf("%s: matched line is empty", tt.name)
+		}
+	}
+}
+
+func TestQuorumVotes(t *testing.T) {
+	tests := []struct {
+		name              string
+		size              int32
+		arbitrator        bool
+		arbitratorRunning bool
+		synced            int
+		quorum            bool
+	}{
+		{"majority", 3, false, false, 2, true},
+		{"half", 4, false, false, 2, false},
+		{"with arbitrator", 2, true, true, 1, true},
+		{"arbitrator isn't running", 2, true, false, 1, false},
+		{"arbitrator breaks the tie", 4, true, true, 2, true},
+	}
+
+	for _, tt := range tests {
+		cr := newCR("cr-mock", "pxc")
+		cr.Spec.PXC.Size = tt.size
+		if tt.arbitrator {
+			cr.Spec.Arbitrator = &api.PodSpec{Enabled: true}
+		}
+		votes, total := quorumVotes(cr, tt.synced, tt.arbitratorRunning)
+		if quorum := votes*2 > total; quorum != tt.quorum {
+			t.Errorf("%s: expected quorum %t, got %d of %d votes", tt.name, tt.quorum, votes, total)
+		}
+	}
+}
+
+func TestCheckReseed(t *testing.T) {
+	cr := newCR("cr-mock", "pxc")
+	cr.Spec.PXC.Reseed = &api.ReseedSpec{Policy: api.ReseedPolicyAuto, TimeoutSeconds: 600}
+	pod := newMockPod("cr-mock-pxc-1", cr.Namespace, nil, podStatusReady)
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "datadir-cr-mock-pxc-1", Namespace: cr.Namespace, UID: "old"},
+	}
+	r := buildFakeClient([]runtime.Object{cr, pod, pvc})
+	recorder := record.NewFakeRecorder(10)
+	r.recorder = recorder
+
+	started := metav1.NewTime(time.Now().Add(-time.Minute))
+	cr.Status.Reseed = &api.ReseedStatus{Pod: pod.Name, PVCUID: pvc.UID, StartedAt: &started}
+
+	err := r.checkReseed(cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cr.Status.Reseed == nil || cr.Status.Reseed.Pod != pod.Name {
+		t.Fatalf("re-seed is finished with the old volume: %+v", cr.Status.Reseed)
+	}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: pvc.Name, Namespace: cr.Namespace}, &corev1.PersistentVolumeClaim{})
+	if !k8serrors.IsNotFound(err) {
+		t.Errorf("expected the old pvc to be deleted, got %v", err)
+	}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: pod.Name, Namespace: cr.Namespace}, &corev1.Pod{})
+	if !k8serrors.IsNotFound(err) {
+		t.Errorf("expected the pod to be deleted, got %v", err)
+	}
+
+	started = metav1.NewTime(time.Now().Add(-time.Hour))
+	err = r.checkReseed(cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := cr.Status.Reseed
+	if st == nil || st.Pod != "" || st.Failed != pod.Name || st.Message == "" {
+		t.Fatalf("expected re-seed of %s to fail, got %+v", pod.Name, st)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("expected the failure to be alerted with an event, got %d events", len(recorder.Events))
+	}
+}
@@ -0,0 +1,160 @@
+package pxc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/k8s"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/statefulset"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/queries"
+)
+
+// scaleDownPXC removes members above the new size one at a time, starting from the highest ordinal.
+// The member is drained first, then the StatefulSet is scaled down by one and the pod is deleted,
+// so mysqld gets SIGTERM, shuts down cleanly and leaves the cluster gracefully.
+// Problems with draining are reported and don't stop the scale down.
+func (r *ReconcilePerconaXtraDBCluster) scaleDownPXC(cr *api.PerconaXtraDBCluster) error {
+	if cr.Spec.Pause || cr.DeletionTimestamp != nil || cr.Spec.PXC.ScaleDownPolicy == nil {
+		return nil
+	}
+
+	sts := statefulset.NewNode(cr).StatefulSet()
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: sts.Name, Namespace: sts.Namespace}, sts)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "get statefulset")
+	}
+
+	for sts.Spec.Replicas != nil && *sts.Spec.Replicas > cr.Spec.PXC.Size {
+		err = r.removeMember(cr, sts, fmt.Sprintf("%s-%d", sts.Name, *sts.Spec.Replicas-1))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *ReconcilePerconaXtraDBCluster) removeMember(cr *api.PerconaXtraDBCluster, sts *appsv1.StatefulSet, podName string) error {
+	log := r.logger(cr.Name, cr.Namespace)
+	policy := cr.Spec.PXC.ScaleDownPolicy
+
+	pod := &corev1.Pod{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: podName, Namespace: cr.Namespace}, pod)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Wrapf(err, "get pod %s", podName)
+	}
+
+	if err == nil && k8s.IsPodReady(pod) {
+		log.Info("draining member", "pod", podName)
+		r.recorder.Eventf(cr, corev1.EventTypeNormal, "MemberDraining", "%s is put into maintenance and drained before the removal", podName)
+		err = r.drainMember(cr, pod, time.Duration(policy.DrainTimeoutSeconds)*time.Second)
+		if err != nil {
+			log.Info("member isn't drained", "pod", podName, "error", err.Error())
+			r.recorder.Eventf(cr, corev1.EventTypeWarning, "MemberDrainFailed", "%s is removed without draining: %v", podName, err)
+		}
+	}
+
+	replicas := *sts.Spec.Replicas - 1
+	sts.Spec.Replicas = &replicas
+	err = r.client.Update(context.TODO(), sts)
+	if err != nil {
+		return errors.Wrap(err, "update statefulset replicas")
+	}
+
+	waitLimit := 10 * time.Minute
+	if cr.Spec.PXC.TerminationGracePeriodSeconds != nil {
+		waitLimit += time.Duration(*cr.Spec.PXC.TerminationGracePeriodSeconds) * time.Second
+	}
+	err = retry(time.Second*5, waitLimit, func() (bool, error) {
+		err := r.client.Get(context.TODO(), types.NamespacedName{Name: podName, Namespace: cr.Namespace}, &corev1.Pod{})
+		if k8serrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, errors.Wrapf(err, "get pod %s", podName)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "wait for %s to be removed", podName)
+	}
+
+	msg := fmt.Sprintf("%s left the cluster", podName)
+	if policy.Volumes == api.ScaleDownVolumesDelete {
+		pvc := &corev1.PersistentVolumeClaim{}
+		pvc.Name = datadirVolume + "-" + podName
+		pvc.Namespace = cr.Namespace
+		err = r.client.Delete(context.TODO(), pvc)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return errors.Wrapf(err, "delete pvc %s", pvc.Name)
+		}
+		msg += fmt.Sprintf(", its volume %s is deleted", pvc.Name)
+	}
+
+	log.Info("member is removed", "pod", podName)
+	r.recorder.Event(cr, corev1.EventTypeNormal, "MemberRemoved", msg)
+
+	return nil
+}
+
+// drainMember puts the member into the maintenance mode, so the proxies stop sending new queries to it
+// and move the writer to another member. ProxySQL is told about it directly.
+// Then it waits until the member isn't the writer and has no client connections.
+func (r *ReconcilePerconaXtraDBCluster) drainMember(cr *api.PerconaXtraDBCluster, pod *corev1.Pod, timeout time.Duration) error {
+	database, err := queries.New(r.client, cr.Namespace, internalPrefix+cr.Name, "operator", pod.Name+"."+cr.Name+"-pxc."+cr.Namespace, 33062)
+	if err != nil {
+		return errors.Wrap(err, "failed to access PXC database")
+	}
+	defer database.Close()
+
+	err = database.Exec("SET GLOBAL pxc_maint_mode=MAINTENANCE")
+	if err != nil {
+		return errors.Wrap(err, "set maintenance mode")
+	}
+
+	proxyReady := (cr.HAProxyEnabled() && cr.Status.HAProxy.Ready > 0) || (cr.ProxySQLEnabled() && cr.Status.ProxySQL.Ready > 0)
+	if cr.ProxySQLEnabled() && proxyReady {
+		proxy, err := queries.NewProxy(r.client, cr)
+		if err != nil {
+			return errors.Wrap(err, "failed to get proxySQL db")
+		}
+		err = proxy.SetOfflineSoft(pod.Name+".", pod.Status.PodIP)
+		proxy.Close()
+		if err != nil {
+			return errors.Wrap(err, "set OFFLINE_SOFT in ProxySQL")
+		}
+	}
+
+	connections := 0
+	writer := ""
+	err = retry(time.Second*5, timeout, func() (bool, error) {
+		if proxyReady {
+			// the writer can't be checked while the proxy switches it
+			writer, _ = queries.PrimaryPod(r.client, cr)
+			if writer == pod.Name || writer == pod.Status.PodIP || strings.HasPrefix(writer, pod.Name+".") {
+				return false, nil
+			}
+		}
+
+		n, err := database.ClientConnections()
+		if err != nil {
+			return false, errors.Wrap(err, "get client connections")
+		}
+		connections = n
+		return connections == 0, nil
+	})
+	if err == errWaitLimit {
+		return errors.Errorf("%d client connections are left after %s, the writer is %q", connections, timeout, writer)
+	}
+
+	return err
+}
@@ -0,0 +1,206 @@
+package pxc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/k8s"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/statefulset"
+)
+
+// stsClient deletes pods above the replicas on the StatefulSet update like the StatefulSet controller does
+// and records the replicas and the pods deleted on every update
+type stsClient struct {
+	client.Client
+	log []string
+}
+
+func (c *stsClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	err := c.Client.Update(ctx, obj, opts...)
+	sts, ok := obj.(*appsv1.StatefulSet)
+	if err != nil || !ok {
+		return err
+	}
+
+	c.log = append(c.log, fmt.Sprintf("replicas=%d", *sts.Spec.Replicas))
+	pods := corev1.PodList{}
+	err = c.Client.List(ctx, &pods, &client.ListOptions{Namespace: sts.Namespace})
+	if err != nil {
+		return err
+	}
+	for i := range pods.Items {
+		ord, err := k8s.PodOrderInSts(sts.Name, pods.Items[i].Name)
+		if err != nil || ord < int(*sts.Spec.Replicas) {
+			continue
+		}
+		err = c.Client.Delete(ctx, &pods.Items[i])
+		if err != nil {
+			return err
+		}
+		c.log = append(c.log, "deleted "+pods.Items[i].Name)
+	}
+
+	return nil
+}
+
+func newScaleDownCluster(size int32, volumes api.ScaleDownVolumes, podStatus corev1.PodStatus) (*ReconcilePerconaXtraDBCluster, *stsClient, *record.FakeRecorder, *api.PerconaXtraDBCluster) {
+	cr := newCR("cr-mock", "pxc")
+	cr.Spec.PXC.Size = size
+	cr.Spec.PXC.ScaleDownPolicy = &api.ScaleDownPolicy{Volumes: volumes, DrainTimeoutSeconds: 1}
+
+	sts := statefulset.NewNode(cr).StatefulSet()
+	replicas := int32(3)
+	sts.Spec.Replicas = &replicas
+
+	objs := []runtime.Object{cr, sts}
+	for i := 0; i < 3; i++ {
+		pod := fmt.Sprintf("%s-%d", sts.Name, i)
+		objs = append(objs,
+			newMockPod(pod, cr.Namespace, statefulset.NewNode(cr).Labels(), podStatus),
+			newDatadirPVC(cr, pod, "standard", "6Gi", "6Gi"),
+		)
+	}
+
+	r := buildFakeClient(objs)
+	cl := &stsClient{Client: r.client}
+	r.client = cl
+	recorder := record.NewFakeRecorder(10)
+	r.recorder = recorder
+
+	return r, cl, recorder, cr
+}
+
+func pvcExists(t *testing.T, r *ReconcilePerconaXtraDBCluster, cr *api.PerconaXtraDBCluster, pod string) bool {
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: datadirVolume + "-" + pod, Namespace: cr.Namespace}, &corev1.PersistentVolumeClaim{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		t.Fatal(err)
+	}
+
+	return err == nil
+}
+
+func TestScaleDownPXCOneAtATime(t *testing.T) {
+	r, cl, _, cr := newScaleDownCluster(1, api.ScaleDownVolumesRetain, corev1.PodStatus{})
+
+	err := r.scaleDownPXC(cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"replicas=2", "deleted cr-mock-pxc-2",
+		"replicas=1", "deleted cr-mock-pxc-1",
+	}
+	if strings.Join(cl.log, ",") != strings.Join(want, ",") {
+		t.Errorf("expected members to be removed one at a time %v, got %v", want, cl.log)
+	}
+	for _, pod := range []string{"cr-mock-pxc-0", "cr-mock-pxc-1", "cr-mock-pxc-2"} {
+		if !pvcExists(t, r, cr, pod) {
+			t.Errorf("expected volume of %s to be retained", pod)
+		}
+	}
+}
+
+func TestScaleDownPXCDeleteVolumes(t *testing.T) {
+	r, _, _, cr := newScaleDownCluster(2, api.ScaleDownVolumesDelete, corev1.PodStatus{})
+
+	err := r.scaleDownPXC(cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pvcExists(t, r, cr, "cr-mock-pxc-2") {
+		t.Error("expected volume of the removed member to be deleted")
+	}
+	for _, pod := range []string{"cr-mock-pxc-0", "cr-mock-pxc-1"} {
+		if !pvcExists(t, r, cr, pod) {
+			t.Errorf("expected volume of %s to be kept", pod)
+		}
+	}
+}
+
+func TestScaleDownPXCWithoutPolicy(t *testing.T) {
+	r, cl, _, cr := newScaleDownCluster(1, api.ScaleDownVolumesRetain, corev1.PodStatus{})
+	cr.Spec.PXC.ScaleDownPolicy = nil
+
+	err := r.scaleDownPXC(cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cl.log) != 0 {
+		t.Errorf("expected the StatefulSet to be left to the reconcile, got %v", cl.log)
+	}
+}
+
+func TestRemoveMemberDrainFailed(t *testing.T) {
+	r, cl, recorder, cr := newScaleDownCluster(2, api.ScaleDownVolumesRetain, podStatusReady)
+
+	sts := statefulset.NewNode(cr).StatefulSet()
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: sts.Name, Namespace: sts.Namespace}, sts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the members aren't reachable, so the ready member can't be drained
+	err = r.removeMember(cr, sts, "cr-mock-pxc-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(cl.log, ",") != "replicas=2,deleted cr-mock-pxc-2" {
+		t.Errorf("expected the member to be removed anyway, got %v", cl.log)
+	}
+	events := []string{}
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	if len(events) != 3 ||
+		!strings.Contains(events[0], "MemberDraining") ||
+		!strings.Contains(events[1], "MemberDrainFailed") ||
+		!strings.Contains(events[2], "MemberRemoved") {
+		t.Errorf("unexpected events %v", events)
+	}
+}
+
+func TestDrainMemberUnreachable(t *testing.T) {
+	r, _, _, cr := newScaleDownCluster(2, api.ScaleDownVolumesRetain, podStatusReady)
+
+	pod := newMockPod("cr-mock-pxc-2", cr.Namespace, nil, podStatusReady)
+	err := r.drainMember(cr, pod, time.Second)
+	if err == nil || !strings.Contains(err.Error(), "failed to access PXC database") {
+		t.Errorf("expected the unreachable member to fail the drain, got %v", err)
+	}
+}
+
+func TestRetryWaitLimit(t *testing.T) {
+	calls := 0
+	err := retry(time.Millisecond, 20*time.Millisecond, func() (bool, error) {
+		calls++
+		return false, nil
+	})
+	if err != errWaitLimit {
+		t.Errorf("expected %v, got %v", errWaitLimit, err)
+	}
+	if calls < 2 {
+		t.Errorf("expected f to be retried, got %d calls", calls)
+	}
+
+	err = retry(time.Millisecond, time.Second, func() (bool, error) {
+		return true, nil
+	})
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
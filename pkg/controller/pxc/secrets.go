@@ -2,13 +2,10 @@ package pxc
 
 import (
 	"context"
-	"crypto/rand"
 	"fmt"
-	"math/big"
-	mrand "math/rand"
-	"time"
 
 	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/users"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	k8serror "k8s.io/apimachinery/pkg/api/errors"
@@ -32,31 +29,31 @@ func (r *ReconcilePerconaXtraDBCluster) reconcileUsersSecret(cr *api.PerconaXtra
 	}
 
 	data := make(map[string][]byte)
-	data["root"], err = generatePass()
+	data["root"], err = users.GeneratePass()
 	if err != nil {
 		return errors.Wrap(err, "create root users password")
 	}
-	data["xtrabackup"], err = generatePass()
+	data["xtrabackup"], err = users.GeneratePass()
 	if err != nil {
 		return errors.Wrap(err, "create xtrabackup users password")
 	}
-	data["monitor"], err = generatePass()
+	data["monitor"], err = users.GeneratePass()
 	if err != nil {
 		return errors.Wrap(err, "create monitor users password")
 	}
-	data["clustercheck"], err = generatePass()
+	data["clustercheck"], err = users.GeneratePass()
 	if err != nil {
 		return errors.Wrap(err, "create clustercheck users password")
 	}
-	data["proxyadmin"], err = generatePass()
+	data["proxyadmin"], err = users.GeneratePass()
 	if err != nil {
 		return errors.Wrap(err, "create proxyadmin users password")
 	}
-	data["operator"], err = generatePass()
+	data["operator"], err = users.GeneratePass()
 	if err != nil {
 		return errors.Wrap(err, "create operator users password")
 	}
-	data["replication"], err = generatePass()
+	data["replication"], err = users.GeneratePass()
 	if err != nil {
 		return errors.Wrap(err, "generate replication password")
 	}
@@ -75,27 +72,3 @@ func (r *ReconcilePerconaXtraDBCluster) reconcileUsersSecret(cr *api.PerconaXtra
 	}
 	return nil
 }
-
-const (
-	passwordMaxLen = 20
-	passwordMinLen = 16
-	passSymbols    = "ABCDEFGHIJKLMNOPQRSTUVWXYZ" +
-		"abcdefghijklmnopqrstuvwxyz" +
-		"0123456789"
-)
-
-//generatePass generate random password
-func generatePass() ([]byte, error) {
-	mrand.Seed(time.Now().UnixNano())
-	ln := mrand.Intn(passwordMaxLen-passwordMinLen) + passwordMinLen
-	b := make([]byte, ln)
-	for i := 0; i < ln; i++ {
-		randInt, err := rand.Int(rand.Reader, big.NewInt(int64(len(passSymbols))))
-		if err != nil {
-			return nil, errors.Wrap(err, "get rand int")
-		}
-		b[i] = passSymbols[randInt.Int64()]
-	}
-
-	return b, nil
-}
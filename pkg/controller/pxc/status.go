@@ -17,26 +17,12 @@ import (
 )
 
 func (r *ReconcilePerconaXtraDBCluster) updateStatus(cr *api.PerconaXtraDBCluster, reconcileErr error) (err error) {
-	clusterCondition := api.ClusterCondition{
-		Status:             api.ConditionTrue,
-		Type:               api.AppStateInit,
-		LastTransitionTime: metav1.NewTime(time.Now()),
-	}
-
 	if reconcileErr != nil {
 		if cr.Status.Status != api.AppStateError {
-			clusterCondition := api.ClusterCondition{
-				Status:             api.ConditionTrue,
-				Type:               api.AppStateError,
-				Message:            reconcileErr.Error(),
-				Reason:             "ErrorReconcile",
-				LastTransitionTime: metav1.NewTime(time.Now()),
-			}
-			cr.Status.AddCondition(clusterCondition)
-
 			cr.Status.Messages = append(cr.Status.Messages, "Error: "+reconcileErr.Error())
 			cr.Status.Status = api.AppStateError
 		}
+		setReconcileError(cr, reconcileErr)
 
 		return r.writeStatus(cr)
 	}
@@ -116,9 +102,24 @@ func (r *ReconcilePerconaXtraDBCluster) updateStatus(cr *api.PerconaXtraDBCluste
 		}
 	}
 
+	// members status is informational, the cluster state doesn't depend on it
+	if membersStatusDue(cr) {
+		members, err := r.membersStatus(cr)
+		if err != nil {
+			cr.Status.Messages = append(cr.Status.Messages, "pxc: failed to get members status: "+err.Error())
+		} else {
+			now := metav1.Now()
+			cr.Status.Members = members
+			cr.Status.MembersCheckedAt = &now
+		}
+	}
+
+	if cr.Status.PVCResize != nil && cr.Status.PVCResize.State == api.PVCResizeFailed {
+		cr.Status.Messages = append(cr.Status.Messages, "pxc: volume expansion failed: "+cr.Status.PVCResize.Message)
+	}
+
 	cr.Status.Status = cr.Status.ClusterStatus(inProgress, cr.ObjectMeta.DeletionTimestamp != nil)
-	clusterCondition.Type = cr.Status.Status
-	cr.Status.AddCondition(clusterCondition)
+	r.updateConditions(cr, inProgress)
 	cr.Status.ObservedGeneration = cr.ObjectMeta.Generation
 
 	return r.writeStatus(cr)
@@ -194,12 +195,12 @@ func (r *ReconcilePerconaXtraDBCluster) appStatus(app api.StatefulApp, namespace
 					continue
 				}
 
-				isPodWaitingForRecovery, _, err := r.isPodWaitingForRecovery(namespace, pod.Name)
+				recovery, err := r.podRecoveryState(&pod)
 				if err != nil {
-					return api.AppStatus{}, errors.Wrapf(err, "parse %s pod logs", pod.Name)
+					return api.AppStatus{}, errors.Wrapf(err, "check if %s waits for recovery", pod.Name)
 				}
 
-				if !isPodWaitingForRecovery && pod.ObjectMeta.Labels["controller-revision-hash"] == sfs.Status.UpdateRevision {
+				if recovery == nil && pod.ObjectMeta.Labels["controller-revision-hash"] == sfs.Status.UpdateRevision {
 					status.Ready++
 				}
 			case corev1.PodScheduled:
@@ -2,7 +2,9 @@ package pxc
 
 import (
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
 	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/statefulset"
@@ -14,6 +16,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake" // nolint
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 var podStatusReady = corev1.PodStatus{
@@ -75,7 +78,7 @@ func buildFakeClient(objs []runtime.Object) *ReconcilePerconaXtraDBCluster {
 
 	cl := fake.NewFakeClientWithScheme(s, objs...)
 
-	return &ReconcilePerconaXtraDBCluster{client: cl, scheme: s}
+	return &ReconcilePerconaXtraDBCluster{client: cl, apiReader: cl, scheme: s, log: logf.NullLogger{}, reportedOverrides: new(sync.Map)}
 }
 
 func TestAppStatusInit(t *testing.T) {
@@ -179,6 +182,10 @@ func TestUpdateStatusReady(t *testing.T) {
 	if cr.Status.Status != api.AppStateReady {
 		t.Errorf("cr.Status.Status got %#v, want %#v", cr.Status.Status, api.AppStateReady)
 	}
+
+	if !cr.Status.IsConditionTrue(api.ConditionReconciled) {
+		t.Errorf("unexpected Reconciled condition %+v", cr.Status.FindCondition(api.ConditionReconciled))
+	}
 }
 
 func TestUpdateStatusError(t *testing.T) {
@@ -199,6 +206,11 @@ func TestUpdateStatusError(t *testing.T) {
 	if cr.Status.Status != api.AppStateError {
 		t.Errorf("cr.Status.Status got %#v, want %#v", cr.Status.Status, api.AppStateError)
 	}
+
+	c := cr.Status.FindCondition(api.ConditionReconciled)
+	if c == nil || c.Status != api.ConditionFalse || c.Reason != "ReconcileError" || c.Message != "mock error" {
+		t.Errorf("unexpected Reconciled condition %+v", c)
+	}
 }
 
 func TestAppHostNoLoadBalancer(t *testing.T) {
@@ -377,7 +389,7 @@ func TestClusterStatus(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(tt *testing.T) {
-			got := test.status.ClusterStatus(false)
+			got := test.status.ClusterStatus(false, false)
 
 			if got != test.want {
 				t.Errorf("AppState got %#v, want %#v", got, test.want)
@@ -385,3 +397,24 @@ func TestClusterStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestMembersStatusDue(t *testing.T) {
+	cr := newCR("cr-mock", "pxc")
+	cr.Spec.PXC.MembersStatusIntervalSeconds = 30
+
+	if !membersStatusDue(cr) {
+		t.Error("members status is never collected")
+	}
+
+	checked := metav1.NewTime(time.Now().Add(-10 * time.Second))
+	cr.Status.MembersCheckedAt = &checked
+	if membersStatusDue(cr) {
+		t.Error("members status is collected again before the interval")
+	}
+
+	checked = metav1.NewTime(time.Now().Add(-time.Minute))
+	cr.Status.MembersCheckedAt = &checked
+	if !membersStatusDue(cr) {
+		t.Error("members status isn't collected after the interval")
+	}
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
 	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/k8s"
 	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc"
 	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/queries"
 	appsv1 "k8s.io/api/apps/v1"
@@ -23,7 +24,6 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"strconv"
 )
 
 func (r *ReconcilePerconaXtraDBCluster) updatePod(sfs api.StatefulApp, podSpec *api.PodSpec, cr *api.PerconaXtraDBCluster, initContainers []corev1.Container) error {
@@ -238,7 +238,7 @@ func (r *ReconcilePerconaXtraDBCluster) smartUpdate(sfs api.StatefulApp, cr *api
 
 	logger.Info("statefulSet was changed, run smart update")
 
-	running, err := r.isBackupRunning(cr)
+	running, err := k8s.IsBackupRunning(r.client, cr)
 	if err != nil {
 		logger.Error(err, "can't start 'SmartUpdate'")
 		return nil
@@ -264,7 +264,7 @@ func (r *ReconcilePerconaXtraDBCluster) smartUpdate(sfs api.StatefulApp, cr *api
 		return errors.Wrap(err, "get pod list")
 	}
 
-	primary, err := r.getPrimaryPod(cr)
+	primary, err := queries.PrimaryPod(r.client, cr)
 	if err != nil {
 		return errors.Wrap(err, "get primary pod")
 	}
@@ -294,6 +294,7 @@ func (r *ReconcilePerconaXtraDBCluster) smartUpdate(sfs api.StatefulApp, cr *api
 		} else {
 			logger.Info("apply changes to secondary pod", "pod name", pod.Name)
 			if err := r.applyNWait(cr, currentSet, &pod, waitLimit); err != nil {
+				r.recorder.Eventf(cr, corev1.EventTypeWarning, "SmartUpdateFailed", "failed to update pod %s: %v", pod.Name, err)
 				return errors.Wrap(err, "failed to apply changes")
 			}
 		}
@@ -301,10 +302,12 @@ func (r *ReconcilePerconaXtraDBCluster) smartUpdate(sfs api.StatefulApp, cr *api
 
 	logger.Info("apply changes to primary pod", "pod name", primaryPod.Name)
 	if err := r.applyNWait(cr, currentSet, &primaryPod, waitLimit); err != nil {
+		r.recorder.Eventf(cr, corev1.EventTypeWarning, "SmartUpdateFailed", "failed to update primary pod %s: %v", primaryPod.Name, err)
 		return errors.Wrap(err, "failed to apply changes")
 	}
 
 	logger.Info("smart update finished")
+	r.recorder.Eventf(cr, corev1.EventTypeNormal, "SmartUpdateFinished", "all pods of %s are updated to revision %s", currentSet.Name, currentSet.Status.UpdateRevision)
 
 	return nil
 }
@@ -312,15 +315,17 @@ func (r *ReconcilePerconaXtraDBCluster) smartUpdate(sfs api.StatefulApp, cr *api
 func (r *ReconcilePerconaXtraDBCluster) applyNWait(cr *api.PerconaXtraDBCluster, sfs *appsv1.StatefulSet, pod *corev1.Pod, waitLimit int) error {
 	logger := r.logger(cr.Name, cr.Namespace)
 
-	if pod.ObjectMeta.Labels["controller-revision-hash"] == sfs.Status.UpdateRevision {
+	updated := pod.ObjectMeta.Labels["controller-revision-hash"] == sfs.Status.UpdateRevision
+	if updated {
 		logger.Info("pod is already updated", "pod name", pod.Name)
 	} else {
+		r.recorder.Eventf(cr, corev1.EventTypeNormal, "SmartUpdatePodStarted", "restarting pod %s with revision %s", pod.Name, sfs.Status.UpdateRevision)
 		if err := r.client.Delete(context.TODO(), pod); err != nil {
 			return errors.Wrap(err, "failed to delete pod")
 		}
 	}
 
-	orderInSts, err := getPodOrderInSts(sfs.Name, pod.Name)
+	orderInSts, err := k8s.PodOrderInSts(sfs.Name, pod.Name)
 	if err != nil {
 		return errors.Errorf("compute pod order err, sfs name: %s, pod name: %s", sfs.Name, pod.Name)
 	}
@@ -341,11 +346,11 @@ func (r *ReconcilePerconaXtraDBCluster) applyNWait(cr *api.PerconaXtraDBCluster,
 		return errors.Wrap(err, "failed to wait pxc status")
 	}
 
-	return nil
-}
+	if !updated {
+		r.recorder.Eventf(cr, corev1.EventTypeNormal, "SmartUpdatePodFinished", "pod %s is updated and online", pod.Name)
+	}
 
-func getPodOrderInSts(stsName string, podName string) (int, error) {
-	return strconv.Atoi(podName[len(stsName)+1:])
+	return nil
 }
 
 func (r *ReconcilePerconaXtraDBCluster) waitUntilOnline(cr *api.PerconaXtraDBCluster, sfsName string, pod *corev1.Pod, waitLimit int, logger logr.Logger) error {
@@ -354,7 +359,7 @@ func (r *ReconcilePerconaXtraDBCluster) waitUntilOnline(cr *api.PerconaXtraDBClu
 		return nil
 	}
 
-	database, err := r.proxyDB(cr)
+	database, err := queries.NewProxy(r.client, cr)
 	if err != nil {
 		return errors.Wrap(err, "failed to get proxySQL db")
 	}
@@ -381,6 +386,9 @@ func (r *ReconcilePerconaXtraDBCluster) waitUntilOnline(cr *api.PerconaXtraDBClu
 		})
 }
 
+// errWaitLimit is returned by retry if the limit is reached before f is done
+var errWaitLimit = errors.New("reach pod wait limit")
+
 // retry runs func "f" every "in" time until "limit" is reached
 // it also doesn't have an extra tail wait after the limit is reached
 // and f func runs first time instantly
@@ -401,7 +409,7 @@ func retry(in, limit time.Duration, f func() (bool, error)) error {
 	for {
 		select {
 		case <-done.C:
-			return errors.New("reach pod wait limit")
+			return errWaitLimit
 		case <-tk.C:
 			fdone, err := f()
 			if err != nil {
@@ -414,73 +422,6 @@ func retry(in, limit time.Duration, f func() (bool, error)) error {
 	}
 }
 
-func (r *ReconcilePerconaXtraDBCluster) proxyDB(cr *api.PerconaXtraDBCluster) (queries.Database, error) {
-	var database queries.Database
-	var user, host string
-	var port, proxySize int32
-
-	if cr.Spec.ProxySQL != nil && cr.Spec.ProxySQL.Enabled {
-		user = "proxyadmin"
-		host = fmt.Sprintf("%s-proxysql-unready.%s", cr.ObjectMeta.Name, cr.Namespace)
-		proxySize = cr.Spec.ProxySQL.Size
-		port = 6032
-	} else if cr.Spec.HAProxy != nil && cr.Spec.HAProxy.Enabled {
-		user = "monitor"
-		host = fmt.Sprintf("%s-haproxy.%s", cr.Name, cr.Namespace)
-		proxySize = cr.Spec.HAProxy.Size
-
-		hasKey, err := cr.ConfigHasKey("mysqld", "proxy_protocol_networks")
-		if err != nil {
-			return database, errors.Wrap(err, "check if config has proxy_protocol_networks key")
-		}
-
-		if hasKey && cr.CompareVersionWith("1.6.0") >= 0 {
-			port = 33062
-		} else {
-			port = 3306
-		}
-	} else {
-		return database, errors.New("can't detect enabled proxy, please enable HAProxy or ProxySQL")
-	}
-	secrets := cr.Spec.SecretsName
-	if cr.CompareVersionWith("1.6.0") >= 0 {
-		secrets = "internal-" + cr.Name
-	}
-	for i := 0; ; i++ {
-		db, err := queries.New(r.client, cr.Namespace, secrets, user, host, port)
-		if err != nil && i < int(proxySize) {
-			time.Sleep(time.Second)
-		} else if err != nil && i == int(proxySize) {
-			return database, err
-		} else {
-			database = db
-			break
-		}
-	}
-
-	return database, nil
-}
-
-func (r *ReconcilePerconaXtraDBCluster) getPrimaryPod(cr *api.PerconaXtraDBCluster) (string, error) {
-	database, err := r.proxyDB(cr)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to get proxySQL db")
-	}
-
-	defer database.Close()
-
-	if cr.Spec.HAProxy != nil && cr.Spec.HAProxy.Enabled {
-		host, err := database.Hostname()
-		if err != nil {
-			return "", err
-		}
-
-		return host, nil
-	}
-
-	return database.PrimaryHost()
-}
-
 func (r *ReconcilePerconaXtraDBCluster) waitPXCSynced(cr *api.PerconaXtraDBCluster, host string, waitLimit int) error {
 	user := "root"
 	secrets := cr.Spec.SecretsName
@@ -557,52 +498,6 @@ func isProxySQL(sfs api.StatefulApp) bool {
 	return sfs.Labels()["app.kubernetes.io/component"] == "proxysql"
 }
 
-func (r *ReconcilePerconaXtraDBCluster) isBackupRunning(cr *api.PerconaXtraDBCluster) (bool, error) {
-	bcpList := api.PerconaXtraDBClusterBackupList{}
-	if err := r.client.List(context.TODO(), &bcpList, &client.ListOptions{Namespace: cr.Namespace}); err != nil {
-		if k8serrors.IsNotFound(err) {
-			return false, nil
-		}
-		return false, errors.Wrap(err, "failed to get backup object")
-	}
-
-	for _, bcp := range bcpList.Items {
-		if bcp.Spec.PXCCluster != cr.Name {
-			continue
-		}
-
-		if bcp.Status.State == api.BackupRunning || bcp.Status.State == api.BackupStarting {
-			return true, nil
-		}
-	}
-
-	return false, nil
-}
-
-func (r *ReconcilePerconaXtraDBCluster) isRestoreRunning(clusterName, namespace string) (bool, error) {
-	restoreList := api.PerconaXtraDBClusterRestoreList{}
-
-	err := r.client.List(context.TODO(), &restoreList, &client.ListOptions{
-		Namespace: namespace,
-	})
-	if err != nil {
-		return false, errors.Wrap(err, "failed to get restore list")
-	}
-
-	for _, v := range restoreList.Items {
-		if v.Spec.PXCCluster != clusterName {
-			continue
-		}
-
-		switch v.Status.State {
-		case api.RestoreStarting, api.RestoreStopCluster, api.RestoreRestore,
-			api.RestoreStartCluster, api.RestorePITR:
-			return true, nil
-		}
-	}
-	return false, nil
-}
-
 func (r *ReconcilePerconaXtraDBCluster) getConfigHash(cr *api.PerconaXtraDBCluster, sfs api.StatefulApp) string {
 	configString := cr.Spec.PXC.Configuration
 	if sfs.Labels()["app.kubernetes.io/component"] == "haproxy" {
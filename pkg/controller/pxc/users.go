@@ -6,7 +6,9 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 
 	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
 	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/users"
@@ -110,15 +112,30 @@ func (r *ReconcilePerconaXtraDBCluster) reconcileUsers(cr *api.PerconaXtraDBClus
 
 	restartPXC, restartProxy, err := r.manageSysUsers(cr, &sysUsersSecretObj, &internalSysSecretObj)
 	if err != nil {
+		r.recorder.Eventf(cr, corev1.EventTypeWarning, "UsersUpdateFailed", "failed to apply changes of secret %s: %v", cr.Spec.SecretsName, err)
 		return nil, nil, errors.Wrap(err, "manage sys users")
 	}
 
+	changedUsers := []string{}
+	for name, pass := range sysUsersSecretObj.Data {
+		if !bytes.Equal(pass, internalSysSecretObj.Data[name]) {
+			changedUsers = append(changedUsers, name)
+		}
+	}
+	sort.Strings(changedUsers)
+
 	internalSysSecretObj.Data = sysUsersSecretObj.Data
 	err = r.client.Update(context.TODO(), &internalSysSecretObj)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "update internal sys users secret")
 	}
 
+	msg := fmt.Sprintf("passwords of users %s from secret %s are applied", strings.Join(changedUsers, ", "), cr.Spec.SecretsName)
+	if restartPXC || restartProxy {
+		msg += ", pods are restarted to pick them up"
+	}
+	r.recorder.Event(cr, corev1.EventTypeNormal, "UsersUpdated", msg)
+
 	if restartProxy {
 		proxysqlAnnotations = make(map[string]string)
 		proxysqlAnnotations["last-applied-secret"] = newSecretDataHash
@@ -434,7 +451,7 @@ func (r *ReconcilePerconaXtraDBCluster) manageOperatorAdminUser(cr *api.PerconaX
 		return nil
 	}
 
-	pass, err := generatePass()
+	pass, err := users.GeneratePass()
 	if err != nil {
 		return errors.Wrap(err, "generate password")
 	}
@@ -482,7 +499,7 @@ func (r *ReconcilePerconaXtraDBCluster) manageReplicationUser(cr *api.PerconaXtr
 		return nil
 	}
 
-	pass, err := generatePass()
+	pass, err := users.GeneratePass()
 	if err != nil {
 		return errors.Wrap(err, "generate password")
 	}
@@ -59,10 +59,10 @@ func (r *ReconcilePerconaXtraDBCluster) sheduleEnsurePXCVersion(cr *apiv1.Percon
 
 	logger.Info("add new job", "schedule", cr.Spec.UpgradeOptions.Schedule)
 	id, err := r.crons.crons.AddFunc(cr.Spec.UpgradeOptions.Schedule, func() {
-		l.statusMutex.Lock()
-		defer l.statusMutex.Unlock()
+		l.StatusMutex.Lock()
+		defer l.StatusMutex.Unlock()
 
-		if !atomic.CompareAndSwapInt32(l.updateSync, updateDone, updateWait) {
+		if !atomic.CompareAndSwapInt32(l.UpdateSync, updateDone, updateWait) {
 			return
 		}
 
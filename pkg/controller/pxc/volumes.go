@@ -0,0 +1,288 @@
+package pxc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/k8s"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/statefulset"
+)
+
+const datadirVolume = "datadir"
+
+// reconcilePersistentVolumes expands datadir PVCs of PXC if the requested storage
+// size grows. StatefulSet volumeClaimTemplates are immutable, so existing PVCs are
+// patched one by one and, once the filesystems are resized, the StatefulSet is
+// deleted with orphaned pods to be recreated with the new template. Members which
+// filesystem is resized only on the pod restart are restarted one at a time.
+// It reports if the StatefulSet is being recreated.
+func (r *ReconcilePerconaXtraDBCluster) reconcilePersistentVolumes(cr *api.PerconaXtraDBCluster) (bool, error) {
+	if cr.Spec.PXC.VolumeSpec == nil || cr.Spec.PXC.VolumeSpec.PersistentVolumeClaim == nil {
+		return false, nil
+	}
+	requested, ok := cr.Spec.PXC.VolumeSpec.PersistentVolumeClaim.Resources.Requests[corev1.ResourceStorage]
+	if !ok {
+		return false, nil
+	}
+
+	sfs := statefulset.NewNode(cr)
+	sts := &appsv1.StatefulSet{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: sfs.StatefulSet().Name, Namespace: cr.Namespace}, sts)
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "get statefulset")
+	}
+	if sts.DeletionTimestamp != nil {
+		// the previous reconcile deleted it, wait until it's gone
+		return true, nil
+	}
+
+	current, ok := volumeClaimSize(sts, datadirVolume)
+	if !ok || requested.Cmp(current) <= 0 {
+		return false, nil
+	}
+
+	if cr.Status.PVCResize == nil || cr.Status.PVCResize.Size != requested.String() {
+		now := metav1.Now()
+		cr.Status.PVCResize = &api.PVCResizeStatus{
+			State:     api.PVCResizeInProgress,
+			Size:      requested.String(),
+			StartedAt: &now,
+		}
+	}
+	st := cr.Status.PVCResize
+
+	pvcs := corev1.PersistentVolumeClaimList{}
+	err = r.client.List(context.TODO(),
+		&pvcs,
+		&client.ListOptions{
+			Namespace:     cr.Namespace,
+			LabelSelector: labels.SelectorFromSet(sfs.Labels()),
+		},
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "get PVC list")
+	}
+
+	log := r.logger(cr.Name, cr.Namespace)
+	fail := func(pvc string, err error) {
+		// the storage class doesn't allow expansion or the PVC isn't dynamically provisioned,
+		// there is nothing to retry until the spec is changed
+		if st.State != api.PVCResizeFailed {
+			log.Error(err, "failed to expand pvc", "pvc", pvc)
+		}
+		st.State = api.PVCResizeFailed
+		st.Message = err.Error()
+	}
+
+	datadirs := []*corev1.PersistentVolumeClaim{}
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		if !strings.HasPrefix(pvc.Name, datadirVolume+"-") {
+			continue
+		}
+		datadirs = append(datadirs, pvc)
+
+		// none of the PVCs is touched if any of them can't be expanded
+		size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if size.Cmp(requested) < 0 {
+			err = r.checkVolumeExpansion(pvc)
+			if err != nil {
+				fail(pvc.Name, err)
+				return false, nil
+			}
+		}
+	}
+
+	st.Resized, st.Total = 0, int32(len(datadirs))
+	var resizePending []*corev1.PersistentVolumeClaim
+	for _, pvc := range datadirs {
+		size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if size.Cmp(requested) < 0 {
+			log.Info("expanding pvc", "pvc", pvc.Name, "from", size.String(), "to", requested.String())
+			err = r.expandPVC(pvc, requested)
+			if err != nil {
+				fail(pvc.Name, err)
+				return false, nil
+			}
+			continue
+		}
+
+		capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+		switch {
+		case capacity.Cmp(requested) >= 0:
+			st.Resized++
+		case fileSystemResizePending(pvc):
+			resizePending = append(resizePending, pvc)
+		}
+	}
+
+	st.State = api.PVCResizeInProgress
+	st.Message = ""
+	if len(resizePending) > 0 {
+		return false, r.restartResizePending(cr, sts, resizePending[0])
+	}
+	if st.Resized < st.Total {
+		// capacity is updated once the volume and the filesystem on it are resized
+		return false, nil
+	}
+
+	log.Info("datadir volumes are expanded, recreating statefulset", "size", requested.String())
+	err = r.client.Delete(context.TODO(), sts,
+		client.PropagationPolicy(metav1.DeletePropagationOrphan),
+		&client.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &sts.UID}},
+	)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return false, errors.Wrap(err, "delete statefulset")
+	}
+	st.State = api.PVCResizeDone
+
+	return true, nil
+}
+
+// restartResizePending restarts the member which volume is expanded, but the filesystem on it
+// is resized only when the pod is restarted. It's done the same way the smart update restarts
+// members: one at a time, only if all members are ready and no backup is running,
+// and the member has to be synced and online before the next one is restarted.
+func (r *ReconcilePerconaXtraDBCluster) restartResizePending(cr *api.PerconaXtraDBCluster, sts *appsv1.StatefulSet, pvc *corev1.PersistentVolumeClaim) error {
+	st := cr.Status.PVCResize
+	podName := strings.TrimPrefix(pvc.Name, datadirVolume+"-")
+
+	if sts.Status.ReadyReplicas < sts.Status.Replicas {
+		st.Message = fmt.Sprintf("%s has to be restarted to resize the filesystem, waiting for all members to be ready", podName)
+		return nil
+	}
+	running, err := k8s.IsBackupRunning(r.client, cr)
+	if err != nil {
+		return err
+	}
+	if running {
+		st.Message = fmt.Sprintf("%s has to be restarted to resize the filesystem, waiting for the backup to finish", podName)
+		return nil
+	}
+
+	pod := &corev1.Pod{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: podName, Namespace: cr.Namespace}, pod)
+	if err != nil {
+		return errors.Wrapf(err, "get pod %s", podName)
+	}
+
+	st.Message = fmt.Sprintf("restarting %s to resize the filesystem", podName)
+	err = r.writeStatus(cr)
+	if err != nil {
+		return errors.Wrap(err, "write status")
+	}
+
+	r.logger(cr.Name, cr.Namespace).Info("restarting member to resize the filesystem", "pod", podName, "pvc", pvc.Name)
+	r.recorder.Eventf(cr, corev1.EventTypeNormal, "PVCResizeRestart", "restarting %s to resize the filesystem of %s", podName, pvc.Name)
+	err = r.client.Delete(context.TODO(), pod, &client.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &pod.UID}})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Wrapf(err, "delete pod %s", podName)
+	}
+
+	waitLimit := 2 * 60 * 60 // 2 hours
+	if cr.Spec.PXC.LivenessInitialDelaySeconds != nil {
+		waitLimit = int(*cr.Spec.PXC.LivenessInitialDelaySeconds)
+	}
+
+	oldUID := pod.UID
+	err = retry(time.Second*10, time.Duration(waitLimit)*time.Second,
+		func() (bool, error) {
+			err := r.client.Get(context.TODO(), types.NamespacedName{Name: podName, Namespace: cr.Namespace}, pod)
+			if k8serrors.IsNotFound(err) {
+				return false, nil
+			}
+			if err != nil {
+				return false, errors.Wrapf(err, "get pod %s", podName)
+			}
+			return pod.UID != oldUID && pod.DeletionTimestamp == nil && k8s.IsPodReady(pod), nil
+		})
+	if err != nil {
+		return errors.Wrapf(err, "wait for %s to restart", podName)
+	}
+
+	err = r.waitPXCSynced(cr, podName+"."+cr.Name+"-pxc."+cr.Namespace, waitLimit)
+	if err != nil {
+		return errors.Wrap(err, "failed to wait pxc sync")
+	}
+
+	err = r.waitUntilOnline(cr, sts.Name, pod, waitLimit, r.logger(cr.Name, cr.Namespace))
+	if err != nil {
+		return errors.Wrap(err, "failed to wait pxc status")
+	}
+	st.Message = ""
+
+	return nil
+}
+
+// fileSystemResizePending checks if the volume is expanded and the filesystem
+// on it waits for the pod restart to be resized
+func fileSystemResizePending(pvc *corev1.PersistentVolumeClaim) bool {
+	for _, cond := range pvc.Status.Conditions {
+		if cond.Type == corev1.PersistentVolumeClaimFileSystemResizePending && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkVolumeExpansion checks if the storage class of the PVC allows volume expansion.
+// If the operator isn't allowed to read storage classes, the check is left to the API server.
+func (r *ReconcilePerconaXtraDBCluster) checkVolumeExpansion(pvc *corev1.PersistentVolumeClaim) error {
+	if pvc.Spec.StorageClassName == nil {
+		return nil
+	}
+	if *pvc.Spec.StorageClassName == "" {
+		return errors.Errorf("pvc %s has no storage class, it can't be expanded", pvc.Name)
+	}
+
+	sc := &storagev1.StorageClass{}
+	err := r.apiReader.Get(context.TODO(), types.NamespacedName{Name: *pvc.Spec.StorageClassName}, sc)
+	if k8serrors.IsForbidden(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "get storage class %s", *pvc.Spec.StorageClassName)
+	}
+	if sc.AllowVolumeExpansion == nil || !*sc.AllowVolumeExpansion {
+		return errors.Errorf("storage class %s of pvc %s doesn't allow volume expansion", sc.Name, pvc.Name)
+	}
+
+	return nil
+}
+
+// expandPVC requests the new size of the volume. The API server rejects it
+// if the storage class doesn't allow volume expansion.
+func (r *ReconcilePerconaXtraDBCluster) expandPVC(pvc *corev1.PersistentVolumeClaim, size resource.Quantity) error {
+	orig := pvc.DeepCopy()
+	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = size
+	return r.client.Patch(context.TODO(), pvc, client.MergeFrom(orig))
+}
+
+func volumeClaimSize(sts *appsv1.StatefulSet, name string) (resource.Quantity, bool) {
+	for _, tmpl := range sts.Spec.VolumeClaimTemplates {
+		if tmpl.Name == name {
+			size, ok := tmpl.Spec.Resources.Requests[corev1.ResourceStorage]
+			return size, ok
+		}
+	}
+
+	return resource.Quantity{}, false
+}
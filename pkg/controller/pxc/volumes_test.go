@@ -0,0 +1,233 @@
+package pxc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/statefulset"
+)
+
+func newVolumeCR(size string) *api.PerconaXtraDBCluster {
+	cr := newCR("cr-mock", "pxc")
+	cr.Spec.PXC.VolumeSpec = &api.VolumeSpec{
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimSpec{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+			},
+		},
+	}
+	return cr
+}
+
+func newVolumeSts(cr *api.PerconaXtraDBCluster, size string) *appsv1.StatefulSet {
+	sts := statefulset.NewNode(cr).StatefulSet()
+	sts.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: datadirVolume},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+				},
+			},
+		},
+	}
+	return sts
+}
+
+func newDatadirPVC(cr *api.PerconaXtraDBCluster, pod, class, size, capacity string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      datadirVolume + "-" + pod,
+			Namespace: cr.Namespace,
+			Labels:    statefulset.NewNode(cr).Labels(),
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &class,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(capacity)},
+		},
+	}
+}
+
+func newStorageClass(name string, expansion bool) *storagev1.StorageClass {
+	return &storagev1.StorageClass{
+		ObjectMeta:           metav1.ObjectMeta{Name: name},
+		AllowVolumeExpansion: &expansion,
+	}
+}
+
+func pvcSize(t *testing.T, r *ReconcilePerconaXtraDBCluster, cr *api.PerconaXtraDBCluster, name string) string {
+	pvc := &corev1.PersistentVolumeClaim{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: cr.Namespace}, pvc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	return size.String()
+}
+
+func stsExists(t *testing.T, r *ReconcilePerconaXtraDBCluster, cr *api.PerconaXtraDBCluster) bool {
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: statefulset.NewNode(cr).StatefulSet().Name, Namespace: cr.Namespace}, &appsv1.StatefulSet{})
+	if k8serrors.IsNotFound(err) {
+		return false
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return true
+}
+
+func TestReconcilePersistentVolumesNoChange(t *testing.T) {
+	cr := newVolumeCR("6Gi")
+	r := buildFakeClient([]runtime.Object{cr, newVolumeSts(cr, "6Gi"), newDatadirPVC(cr, "cr-mock-pxc-0", "standard", "6Gi", "6Gi")})
+
+	recreating, err := r.reconcilePersistentVolumes(cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recreating || cr.Status.PVCResize != nil {
+		t.Errorf("expected nothing to do, got recreating=%t, status %+v", recreating, cr.Status.PVCResize)
+	}
+}
+
+func TestReconcilePersistentVolumesExpand(t *testing.T) {
+	cr := newVolumeCR("10Gi")
+	r := buildFakeClient([]runtime.Object{
+		cr,
+		newVolumeSts(cr, "6Gi"),
+		newStorageClass("standard", true),
+		newDatadirPVC(cr, "cr-mock-pxc-0", "standard", "6Gi", "6Gi"),
+		newDatadirPVC(cr, "cr-mock-pxc-1", "standard", "10Gi", "10Gi"),
+	})
+
+	recreating, err := r.reconcilePersistentVolumes(cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recreating {
+		t.Error("statefulset is recreated before volumes are resized")
+	}
+	st := cr.Status.PVCResize
+	if st == nil || st.State != api.PVCResizeInProgress || st.Size != "10Gi" || st.Resized != 1 || st.Total != 2 {
+		t.Fatalf("unexpected status %+v", st)
+	}
+	if size := pvcSize(t, r, cr, "datadir-cr-mock-pxc-0"); size != "10Gi" {
+		t.Errorf("expected pvc to be expanded to 10Gi, got %s", size)
+	}
+	if !stsExists(t, r, cr) {
+		t.Error("statefulset is deleted before volumes are resized")
+	}
+}
+
+func TestReconcilePersistentVolumesDone(t *testing.T) {
+	cr := newVolumeCR("10Gi")
+	r := buildFakeClient([]runtime.Object{
+		cr,
+		newVolumeSts(cr, "6Gi"),
+		newStorageClass("standard", true),
+		newDatadirPVC(cr, "cr-mock-pxc-0", "standard", "10Gi", "10Gi"),
+		newDatadirPVC(cr, "cr-mock-pxc-1", "standard", "10Gi", "10Gi"),
+	})
+
+	recreating, err := r.reconcilePersistentVolumes(cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !recreating {
+		t.Error("expected statefulset to be recreated")
+	}
+	if st := cr.Status.PVCResize; st == nil || st.State != api.PVCResizeDone || st.Resized != 2 {
+		t.Errorf("unexpected status %+v", st)
+	}
+	if stsExists(t, r, cr) {
+		t.Error("expected statefulset to be deleted with orphaned pods")
+	}
+}
+
+func TestReconcilePersistentVolumesNoExpansion(t *testing.T) {
+	cr := newVolumeCR("10Gi")
+	r := buildFakeClient([]runtime.Object{
+		cr,
+		newVolumeSts(cr, "6Gi"),
+		newStorageClass("standard", true),
+		newStorageClass("fixed", false),
+		newDatadirPVC(cr, "cr-mock-pxc-0", "standard", "6Gi", "6Gi"),
+		newDatadirPVC(cr, "cr-mock-pxc-1", "fixed", "6Gi", "6Gi"),
+	})
+
+	recreating, err := r.reconcilePersistentVolumes(cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recreating {
+		t.Error("statefulset is recreated after failed expansion")
+	}
+	if st := cr.Status.PVCResize; st == nil || st.State != api.PVCResizeFailed || st.Message == "" {
+		t.Errorf("unexpected status %+v", st)
+	}
+	// none of the volumes is expanded if any of them can't be
+	for _, pvc := range []string{"datadir-cr-mock-pxc-0", "datadir-cr-mock-pxc-1"} {
+		if size := pvcSize(t, r, cr, pvc); size != "6Gi" {
+			t.Errorf("expected %s to stay 6Gi, got %s", pvc, size)
+		}
+	}
+	if !stsExists(t, r, cr) {
+		t.Error("statefulset is deleted after failed expansion")
+	}
+}
+
+func TestReconcilePersistentVolumesResizePending(t *testing.T) {
+	cr := newVolumeCR("8Gi")
+	sts := newVolumeSts(cr, "6Gi")
+	sts.Status.Replicas = 2
+	sts.Status.ReadyReplicas = 1
+
+	pending := newDatadirPVC(cr, "cr-mock-pxc-0", "standard", "8Gi", "6Gi")
+	pending.Status.Conditions = []corev1.PersistentVolumeClaimCondition{
+		{Type: corev1.PersistentVolumeClaimFileSystemResizePending, Status: corev1.ConditionTrue},
+	}
+	r := buildFakeClient([]runtime.Object{
+		cr,
+		sts,
+		newStorageClass("standard", true),
+		pending,
+		newDatadirPVC(cr, "cr-mock-pxc-1", "standard", "8Gi", "8Gi"),
+		newMockPod("cr-mock-pxc-0", cr.Namespace, nil, podStatusReady),
+	})
+
+	recreating, err := r.reconcilePersistentVolumes(cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recreating {
+		t.Error("expected statefulset not to be recreated")
+	}
+	st := cr.Status.PVCResize
+	if st == nil || st.State != api.PVCResizeInProgress || st.Resized != 1 {
+		t.Fatalf("unexpected status %+v", st)
+	}
+	if !strings.Contains(st.Message, "cr-mock-pxc-0 has to be restarted to resize the filesystem") {
+		t.Errorf("unexpected message %q", st.Message)
+	}
+
+	pod := &corev1.Pod{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: "cr-mock-pxc-0", Namespace: cr.Namespace}, pod)
+	if err != nil {
+		t.Errorf("expected pod not to be restarted while members aren't ready: %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package pxcbackup
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"reflect"
 	"strconv"
@@ -12,8 +13,9 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/go-logr/zapr"
 	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/percona/percona-xtradb-cluster-operator/clientcmd"
 	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/k8s"
 	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/backup"
 	"github.com/percona/percona-xtradb-cluster-operator/version"
 	"github.com/pkg/errors"
@@ -21,9 +23,12 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -69,13 +74,22 @@ func newReconciler(mgr manager.Manager) (reconcile.Reconciler, error) {
 		return nil, errors.Wrap(err, "failed to create logger")
 	}
 
+	cli, err := clientcmd.NewClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "create clientcmd")
+	}
+
 	return &ReconcilePerconaXtraDBClusterBackup{
 		client:              mgr.GetClient(),
 		scheme:              mgr.GetScheme(),
 		serverVersion:       sv,
+		clientcmd:           cli,
 		chLimit:             make(chan struct{}, limit),
 		bcpDeleteInProgress: new(sync.Map),
+		snapshotLocks:       new(sync.Map),
+		restMapper:          mgr.GetRESTMapper(),
 		log:                 zapr.NewLogger(zapLog),
+		recorder:            mgr.GetEventRecorderFor("pxcbackup-controller"),
 	}, nil
 }
 
@@ -106,9 +120,14 @@ type ReconcilePerconaXtraDBClusterBackup struct {
 	scheme *runtime.Scheme
 
 	serverVersion       *version.ServerVersion
+	clientcmd           *clientcmd.Client
 	chLimit             chan struct{}
 	bcpDeleteInProgress *sync.Map
-	log                 logr.Logger
+	// snapshotLocks are backup locks held on donors of snapshot backups
+	snapshotLocks *sync.Map
+	restMapper    meta.RESTMapper
+	log           logr.Logger
+	recorder      record.EventRecorder
 }
 
 func (r *ReconcilePerconaXtraDBClusterBackup) logger(name, namespace string) logr.Logger {
@@ -136,6 +155,10 @@ func (r *ReconcilePerconaXtraDBClusterBackup) Reconcile(request reconcile.Reques
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			cr.Name, cr.Namespace = request.Name, request.Namespace
+			if err := r.releaseSnapshotLock(cr); err != nil {
+				logger.Error(err, "release backup lock")
+			}
 			return rr, nil
 		}
 		// Error reading the object - requeue the request.
@@ -147,6 +170,11 @@ func (r *ReconcilePerconaXtraDBClusterBackup) Reconcile(request reconcile.Reques
 		return reconcile.Result{}, errors.Wrap(err, "failed to run finalizers")
 	}
 
+	// imported backups are read-only, they are managed by the catalog controller
+	if cr.Labels[api.LabelBackupCatalog] == "true" {
+		return reconcile.Result{}, nil
+	}
+
 	if cr.Status.State == api.BackupSucceeded ||
 		cr.Status.State == api.BackupFailed {
 		if len(cr.GetFinalizers()) > 0 {
@@ -156,6 +184,15 @@ func (r *ReconcilePerconaXtraDBClusterBackup) Reconcile(request reconcile.Reques
 	}
 
 	if cr.DeletionTimestamp != nil {
+		if err := r.releaseSnapshotLock(cr); err != nil {
+			logger.Error(err, "release backup lock")
+		}
+		return rr, nil
+	}
+
+	err = cr.CheckNsetDefaults()
+	if err != nil {
+		logger.Error(err, "invalid backup options")
 		return rr, nil
 	}
 
@@ -178,14 +215,33 @@ func (r *ReconcilePerconaXtraDBClusterBackup) Reconcile(request reconcile.Reques
 		return rr, errors.Wrap(err, "failed to run backup")
 	}
 
+	if cr.Spec.Type == api.BackupTypeLogical && cluster.CompareVersionWith("1.9.0") < 0 {
+		return rr, errors.New("logical backups require crVersion 1.9.0 or newer")
+	}
+
 	bcpStorage, ok := cluster.Spec.Backup.Storages[cr.Spec.StorageName]
 	if !ok {
 		return rr, errors.Errorf("bcpStorage %s doesn't exist", cr.Spec.StorageName)
 	}
+	if bcpStorage.Type == api.BackupStorageS3 && cr.Spec.Type == api.BackupTypePhysical {
+		err = bcpStorage.S3.CheckPhysical()
+		if err != nil {
+			return rr, err
+		}
+	}
+
+	// the backup scripts are shipped with the operator since 1.9.0
+	initImage := ""
+	if cluster.CompareVersionWith("1.9.0") >= 0 {
+		initImage, err = k8s.InitImage(cluster, r.client)
+		if err != nil {
+			return rr, err
+		}
+	}
 
 	bcp := backup.New(cluster)
 	job := bcp.Job(cr, cluster)
-	job.Spec, err = bcp.JobSpec(cr.Spec, cluster.Spec, job)
+	job.Spec, err = bcp.JobSpec(cr.Spec, cluster.Spec, job, initImage)
 	if err != nil {
 		return rr, errors.Wrap(err, "can't create job spec")
 	}
@@ -222,7 +278,11 @@ func (r *ReconcilePerconaXtraDBClusterBackup) Reconcile(request reconcile.Reques
 			return rr, errors.Wrap(err, "set storage FS")
 		}
 	case api.BackupStorageS3:
-		destination = bcpStorage.S3.Bucket + "/" + cr.Spec.PXCCluster + "-" + cr.CreationTimestamp.Time.Format("2006-01-02-15:04:05") + "-full"
+		suffix := "-full"
+		if cr.Spec.Type == api.BackupTypeLogical {
+			suffix = "-logical"
+		}
+		destination = bcpStorage.S3.Bucket + "/" + cr.Spec.PXCCluster + "-" + cr.CreationTimestamp.Time.Format("2006-01-02-15:04:05") + suffix
 		if !strings.HasPrefix(bcpStorage.S3.Bucket, "s3://") {
 			destination = "s3://" + destination
 		}
@@ -240,14 +300,41 @@ func (r *ReconcilePerconaXtraDBClusterBackup) Reconcile(request reconcile.Reques
 		return rr, errors.Wrap(err, "job/setControllerReference")
 	}
 
+	hooks := bcpStorage.Hooks.Merge(cr.Spec.Hooks)
+	if hooks != nil && cr.Status.State == api.BackupNew {
+		executed := len(cr.Status.Hooks)
+		err = r.runHooks(cr, cluster, api.BackupHookPre, hooks.Pre)
+		if err != nil {
+			r.stateEvent(cr, api.BackupFailed, err.Error())
+			cr.Status.State = api.BackupFailed
+			cr.Status.Destination = destination
+			cr.Status.StorageName = cr.Spec.StorageName
+			cr.Status.S3 = s3status
+		}
+		if err != nil || len(cr.Status.Hooks) != executed {
+			if werr := r.writeStatus(cr); werr != nil {
+				return rr, werr
+			}
+		}
+		if err != nil {
+			logger.Error(err, "backup is failed")
+			return rr, nil
+		}
+	}
+
+	if bcpStorage.Type == api.BackupStorageSnapshot {
+		return rr, r.reconcileSnapshot(cr, cluster, bcpStorage, hooks)
+	}
+
 	err = r.client.Create(context.TODO(), job)
 	if err != nil && !k8sErrors.IsAlreadyExists(err) {
 		return rr, errors.Wrap(err, "create backup job")
 	} else if err == nil {
 		logger.Info("Created a new backup job", "Namespace", job.Namespace, "Name", job.Name)
+		r.recorder.Eventf(cr, corev1.EventTypeNormal, "BackupJobCreated", "created job %s to back up cluster %s to %s", job.Name, cr.Spec.PXCCluster, destination)
 	}
 
-	err = r.updateJobStatus(cr, job, destination, cr.Spec.StorageName, s3status)
+	err = r.updateJobStatus(cr, job, cluster, hooks, destination, cr.Spec.StorageName, s3status)
 
 	return rr, err
 }
@@ -410,39 +497,11 @@ func (r *ReconcilePerconaXtraDBClusterBackup) getClusterConfig(cr *api.PerconaXt
 }
 
 func (r *ReconcilePerconaXtraDBClusterBackup) s3cli(cr *api.PerconaXtraDBClusterBackup) (*minio.Client, error) {
-	sec := corev1.Secret{}
-	err := r.client.Get(context.Background(),
-		types.NamespacedName{Name: cr.Status.S3.CredentialsSecret, Namespace: cr.Namespace}, &sec)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get secret")
-	}
-
-	accessKeyID := string(sec.Data["AWS_ACCESS_KEY_ID"])
-	secretAccessKey := string(sec.Data["AWS_SECRET_ACCESS_KEY"])
-
-	secure := true
-	if strings.HasPrefix(cr.Status.S3.EndpointURL, "http://") {
-		secure = false
-	}
-
-	ep := cr.Status.S3.EndpointURL
-	if len(ep) == 0 {
-		ep = "s3.amazonaws.com"
-	}
-
-	ep = strings.TrimPrefix(ep, "https://")
-	ep = strings.TrimPrefix(ep, "http://")
-	ep = strings.TrimSuffix(ep, "/")
-
-	return minio.New(ep, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
-		Secure: secure,
-		Region: cr.Status.S3.Region,
-	})
+	return backup.NewS3Client(r.client, cr.Namespace, *cr.Status.S3)
 }
 
 func (r *ReconcilePerconaXtraDBClusterBackup) updateJobStatus(bcp *api.PerconaXtraDBClusterBackup, job *batchv1.Job,
-	destination, storageName string, s3 *api.BackupStorageS3Spec) error {
+	cluster *api.PerconaXtraDBCluster, hooks *api.BackupHooks, destination, storageName string, s3 *api.BackupStorageS3Spec) error {
 	err := r.client.Get(context.TODO(), types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, job)
 
 	if err != nil {
@@ -455,9 +514,12 @@ func (r *ReconcilePerconaXtraDBClusterBackup) updateJobStatus(bcp *api.PerconaXt
 
 	status := api.PXCBackupStatus{
 		State:       api.BackupStarting,
+		Type:        bcp.Spec.Type,
+		StartedAt:   job.Status.StartTime,
 		Destination: destination,
 		StorageName: storageName,
 		S3:          s3,
+		Hooks:       bcp.Status.Hooks,
 	}
 
 	switch {
@@ -466,18 +528,115 @@ func (r *ReconcilePerconaXtraDBClusterBackup) updateJobStatus(bcp *api.PerconaXt
 	case job.Status.Succeeded == 1:
 		status.State = api.BackupSucceeded
 		status.CompletedAt = job.Status.CompletionTime
+
+		info, err := r.jobInfo(job)
+		if err != nil {
+			r.logger(bcp.Name, bcp.Namespace).Error(err, "failed to get backup info")
+		}
+		// backup job can't get the size of the backup uploaded to S3
+		if s3 != nil && info["backup_size"] == "" {
+			err = r.setS3BackupSize(info, s3, destination, bcp.Namespace)
+			if err != nil {
+				r.logger(bcp.Name, bcp.Namespace).Error(err, "failed to get backup size")
+			}
+		}
+		status.Size = info.Size()
+		status.XtrabackupVersion = info.XtrabackupVersion()
+		status.ServerVersion = info.ServerVersion()
+		status.LastGTIDSet = info.LastGTIDSet()
+		status.LSN = info.LSN()
+		status.Donor = info.Donor()
+		status.PITRReady = s3 != nil && status.LastGTIDSet != "" &&
+			cluster.Spec.Backup != nil && cluster.Spec.Backup.PITR.Enabled
 	case job.Status.Failed >= 1:
 		status.State = api.BackupFailed
 	}
 
+	if hooks != nil && (status.State == api.BackupSucceeded || status.State == api.BackupFailed) {
+		// post hooks are run regardless of the backup result
+		// so they can revert whatever was done by pre hooks
+		err = r.runHooks(bcp, cluster, api.BackupHookPost, hooks.Post)
+		status.Hooks = bcp.Status.Hooks
+		if err != nil {
+			r.logger(bcp.Name, bcp.Namespace).Error(err, "backup is failed")
+			status.State = api.BackupFailed
+		}
+	}
+
 	// don't update the status if there aren't any changes.
 	if reflect.DeepEqual(bcp.Status, status) {
 		return nil
 	}
 
+	r.stateEvent(bcp, status.State, "")
 	bcp.Status = status
 
-	err = r.client.Status().Update(context.TODO(), bcp)
+	return r.writeStatus(bcp)
+}
+
+// jobInfo returns the backup summary reported by the succeeded job pod
+func (r *ReconcilePerconaXtraDBClusterBackup) jobInfo(job *batchv1.Job) (backup.Info, error) {
+	pods := corev1.PodList{}
+	err := r.client.List(context.TODO(),
+		&pods,
+		&client.ListOptions{
+			Namespace:     job.Namespace,
+			LabelSelector: labels.SelectorFromSet(map[string]string{"job-name": job.Name}),
+		},
+	)
+	if err != nil {
+		return backup.Info{}, errors.Wrap(err, "get job pods")
+	}
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodSucceeded {
+			continue
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.State.Terminated != nil && cs.State.Terminated.Message != "" {
+				return backup.ParseInfo(cs.State.Terminated.Message), nil
+			}
+		}
+	}
+
+	return backup.Info{}, errors.Errorf("no backup info in job %s", job.Name)
+}
+
+func (r *ReconcilePerconaXtraDBClusterBackup) setS3BackupSize(info backup.Info, s3 *api.BackupStorageS3Spec, destination, namespace string) error {
+	cli, err := backup.NewS3Client(r.client, namespace, *s3)
+	if err != nil {
+		return errors.Wrap(err, "create s3 client")
+	}
+
+	size, err := backup.S3BackupSize(cli, destination)
+	if err != nil {
+		return err
+	}
+	info["backup_size"] = strconv.FormatInt(size, 10)
+
+	return nil
+}
+
+// stateEvent emits the event if the backup is finished with the given state
+func (r *ReconcilePerconaXtraDBClusterBackup) stateEvent(cr *api.PerconaXtraDBClusterBackup, state api.PXCBackupState, reason string) {
+	if state == cr.Status.State {
+		return
+	}
+
+	switch state {
+	case api.BackupSucceeded:
+		r.recorder.Eventf(cr, corev1.EventTypeNormal, "BackupSucceeded", "backup of cluster %s succeeded", cr.Spec.PXCCluster)
+	case api.BackupFailed:
+		msg := fmt.Sprintf("backup of cluster %s failed", cr.Spec.PXCCluster)
+		if reason != "" {
+			msg += ": " + reason
+		}
+		r.recorder.Event(cr, corev1.EventTypeWarning, "BackupFailed", msg)
+	}
+}
+
+func (r *ReconcilePerconaXtraDBClusterBackup) writeStatus(bcp *api.PerconaXtraDBClusterBackup) error {
+	err := r.client.Status().Update(context.TODO(), bcp)
 	if err != nil {
 		// may be it's k8s v1.10 and erlier (e.g. oc3.9) that doesn't support status updates
 		// so try to update whole CR
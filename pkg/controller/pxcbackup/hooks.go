@@ -0,0 +1,117 @@
+package pxcbackup
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"time"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/statefulset"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/queries"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// runHooks executes hooks that weren't executed yet on the given stage and records
+// results into the backup status. It returns an error if any hook with
+// the "fail" policy has failed.
+func (r *ReconcilePerconaXtraDBClusterBackup) runHooks(cr *api.PerconaXtraDBClusterBackup, cluster *api.PerconaXtraDBCluster,
+	stage api.BackupHookStage, hooks []api.BackupHook) error {
+	logger := r.logger(cr.Name, cr.Namespace)
+
+	for _, hook := range hooks {
+		if cr.Status.HookExecuted(stage, hook.Name) {
+			continue
+		}
+
+		logger.Info("running backup hook", "stage", stage, "hook", hook.Name)
+
+		var err error
+		if hook.Exec != nil {
+			err = r.runExecHook(cluster, hook.Exec)
+		} else {
+			err = r.runSQLHook(cluster, hook.SQL)
+		}
+
+		status := api.BackupHookStatus{
+			Name:     hook.Name,
+			Stage:    stage,
+			State:    api.BackupHookSucceeded,
+			Executed: &metav1.Time{Time: time.Now()},
+		}
+		if err != nil {
+			status.State = api.BackupHookFailed
+			status.Message = err.Error()
+			logger.Error(err, "backup hook failed", "stage", stage, "hook", hook.Name, "failurePolicy", hook.FailurePolicy)
+		}
+		cr.Status.Hooks = append(cr.Status.Hooks, status)
+
+		if err != nil && hook.FailurePolicy != api.BackupHookContinue {
+			return errors.Wrapf(err, "%s-backup hook %s", stage, hook.Name)
+		}
+	}
+
+	return nil
+}
+
+func (r *ReconcilePerconaXtraDBClusterBackup) runSQLHook(cluster *api.PerconaXtraDBCluster, stmts []string) error {
+	user := "root"
+	secrets := cluster.Spec.SecretsName
+	port := int32(3306)
+	if cluster.CompareVersionWith("1.6.0") >= 0 {
+		user = "operator"
+		secrets = "internal-" + cluster.Name
+		port = int32(33062)
+	}
+
+	database, err := queries.New(r.client, cluster.Namespace, secrets, user, cluster.Name+"-pxc."+cluster.Namespace, port)
+	if err != nil {
+		return errors.Wrap(err, "failed to access PXC database")
+	}
+	defer database.Close()
+
+	return database.Exec(stmts...)
+}
+
+func (r *ReconcilePerconaXtraDBClusterBackup) runExecHook(cluster *api.PerconaXtraDBCluster, hook *api.BackupHookExec) error {
+	pod, err := r.readyPXCPod(cluster)
+	if err != nil {
+		return err
+	}
+
+	var outb, errb bytes.Buffer
+	err = r.clientcmd.Exec(pod, hook.Container, hook.Command, nil, &outb, &errb, false)
+	if err != nil {
+		return errors.Wrapf(err, "exec in %s/%s: %s", pod.Name, hook.Container, strings.TrimSpace(errb.String()))
+	}
+
+	return nil
+}
+
+func (r *ReconcilePerconaXtraDBClusterBackup) readyPXCPod(cluster *api.PerconaXtraDBCluster) (*corev1.Pod, error) {
+	pods := corev1.PodList{}
+	err := r.client.List(context.TODO(),
+		&pods,
+		&client.ListOptions{
+			Namespace:     cluster.Namespace,
+			LabelSelector: labels.SelectorFromSet(statefulset.NewNode(cluster).Labels()),
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "get pods list")
+	}
+
+	for i, pod := range pods.Items {
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.ContainersReady && cond.Status == corev1.ConditionTrue {
+				return &pods.Items[i], nil
+			}
+		}
+	}
+
+	return nil, errors.New("no ready PXC pods")
+}
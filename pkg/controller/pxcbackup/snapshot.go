@@ -0,0 +1,274 @@
+package pxcbackup
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/statefulset"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/backup"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/queries"
+)
+
+// snapshotCreateLimit is how long the member is kept desynced waiting
+// for the storage to cut the snapshot
+const snapshotCreateLimit = 5 * time.Minute
+
+// snapshotLock is the backup lock held on the donor until its snapshot is cut
+type snapshotLock struct {
+	database queries.Database
+	lock     *queries.BackupLock
+	started  time.Time
+}
+
+func (l *snapshotLock) release() error {
+	defer l.database.Close()
+	return l.lock.Release()
+}
+
+// reconcileSnapshot takes the CSI snapshot of the datadir of one of the members.
+// The member is desynced and locked for backup only until the snapshot is cut.
+// Neither cutting nor uploading of the snapshot blocks the reconcile, they are
+// tracked by the following reconciles.
+func (r *ReconcilePerconaXtraDBClusterBackup) reconcileSnapshot(cr *api.PerconaXtraDBClusterBackup, cluster *api.PerconaXtraDBCluster,
+	strg *api.BackupStorageSpec, hooks *api.BackupHooks) error {
+	logger := r.logger(cr.Name, cr.Namespace)
+
+	gvk, err := backup.VolumeSnapshotGVK(r.restMapper)
+	if err != nil {
+		return err
+	}
+
+	snap := backup.NewVolumeSnapshot(gvk)
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: backup.GenName63(cr), Namespace: cr.Namespace}, snap)
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return errors.Wrap(err, "get volume snapshot")
+	}
+
+	status := cr.Status.DeepCopy()
+	status.Type = cr.Spec.Type
+	status.StorageName = cr.Spec.StorageName
+	status.Destination = backup.SnapshotDestinationPrefix + backup.GenName63(cr)
+
+	if k8sErrors.IsNotFound(err) {
+		pod, err := r.snapshotDonor(cluster)
+		if err != nil {
+			return err
+		}
+
+		className := ""
+		if strg.Snapshot != nil {
+			className = strg.Snapshot.VolumeSnapshotClassName
+		}
+		snap = backup.VolumeSnapshot(gvk, cr, statefulset.DataVolumeName+"-"+pod.Name, className)
+		if err := setControllerReference(cr, snap, r.scheme); err != nil {
+			return errors.Wrap(err, "snapshot/setControllerReference")
+		}
+
+		status.StartedAt = &metav1.Time{Time: time.Now()}
+		status.Donor = pod.Name
+		err = r.takeSnapshot(cr, cluster, pod, snap)
+		if err != nil {
+			logger.Error(err, "backup is failed")
+			status.State = api.BackupFailed
+		} else {
+			logger.Info("Created a new volume snapshot", "Namespace", snap.GetNamespace(), "Name", snap.GetName(), "donor", pod.Name)
+			r.recorder.Eventf(cr, corev1.EventTypeNormal, "VolumeSnapshotCreated", "created volume snapshot %s of %s", snap.GetName(), pod.Name)
+			status.State = api.BackupRunning
+		}
+	} else {
+		st := backup.GetSnapshotStatus(snap)
+		switch {
+		case st.ErrorMessage != "":
+			logger.Error(errors.New(st.ErrorMessage), "volume snapshot is failed")
+			status.State = api.BackupFailed
+		case st.ReadyToUse:
+			status.State = api.BackupSucceeded
+			status.CompletedAt = &metav1.Time{Time: time.Now()}
+			status.Size = st.RestoreSize
+		}
+
+		err := r.checkSnapshotLock(cr, cluster, st)
+		if err != nil {
+			logger.Error(err, "backup is failed")
+			status.State = api.BackupFailed
+		}
+	}
+
+	if status.State == api.BackupFailed {
+		if err := r.releaseSnapshotLock(cr); err != nil {
+			logger.Error(err, "release backup lock")
+		}
+	}
+
+	if hooks != nil && (status.State == api.BackupSucceeded || status.State == api.BackupFailed) {
+		err = r.runHooks(cr, cluster, api.BackupHookPost, hooks.Post)
+		status.Hooks = cr.Status.Hooks
+		if err != nil {
+			logger.Error(err, "backup is failed")
+			status.State = api.BackupFailed
+		}
+	}
+
+	if status.State == cr.Status.State && status.Donor == cr.Status.Donor && len(status.Hooks) == len(cr.Status.Hooks) {
+		return nil
+	}
+
+	r.stateEvent(cr, status.State, "")
+	cr.Status = *status
+
+	return r.writeStatus(cr)
+}
+
+// takeSnapshot desyncs the donor, takes the backup lock on it and creates
+// the snapshot, so the datadir is consistent on the volume. The lock is kept
+// until the snapshot is cut (see checkSnapshotLock).
+func (r *ReconcilePerconaXtraDBClusterBackup) takeSnapshot(cr *api.PerconaXtraDBClusterBackup, cluster *api.PerconaXtraDBCluster,
+	pod *corev1.Pod, snap *unstructured.Unstructured) error {
+	database, err := r.donorDatabase(cluster, pod.Name)
+	if err != nil {
+		return err
+	}
+
+	lock, err := database.AcquireBackupLock()
+	if err != nil {
+		database.Close()
+		return errors.Wrapf(err, "lock %s for backup", pod.Name)
+	}
+	held := &snapshotLock{database: database, lock: lock, started: time.Now()}
+
+	err = r.client.Create(context.TODO(), snap)
+	if err != nil && !k8sErrors.IsAlreadyExists(err) {
+		if err := held.release(); err != nil {
+			r.logger(cr.Name, cr.Namespace).Error(err, "release backup lock")
+		}
+		return errors.Wrap(err, "create volume snapshot")
+	}
+
+	r.snapshotLocks.Store(lockKey(cr), held)
+
+	return nil
+}
+
+// checkSnapshotLock releases the backup lock as soon as the snapshot is cut.
+// The backup fails if the snapshot isn't cut in snapshotCreateLimit or if the
+// lock was lost (e.g. the operator was restarted) before it was cut.
+func (r *ReconcilePerconaXtraDBClusterBackup) checkSnapshotLock(cr *api.PerconaXtraDBClusterBackup, cluster *api.PerconaXtraDBCluster,
+	st backup.SnapshotStatus) error {
+	v, ok := r.snapshotLocks.Load(lockKey(cr))
+	if !ok {
+		if st.Created || cr.Status.State != api.BackupRunning {
+			return nil
+		}
+
+		// the session of the lock is gone, but the donor stays desynced
+		database, err := r.donorDatabase(cluster, cr.Status.Donor)
+		if err != nil {
+			return errors.Wrap(err, "backup lock is lost")
+		}
+		defer database.Close()
+		if err := database.Resync(); err != nil {
+			return errors.Wrap(err, "backup lock is lost")
+		}
+
+		return errors.New("backup lock is lost before the volume snapshot was cut")
+	}
+
+	held := v.(*snapshotLock)
+	if !st.Created && st.ErrorMessage == "" && time.Since(held.started) < snapshotCreateLimit {
+		return nil
+	}
+
+	if err := r.releaseSnapshotLock(cr); err != nil {
+		return err
+	}
+	if !st.Created && st.ErrorMessage == "" {
+		return errors.Errorf("volume snapshot wasn't cut in %s", snapshotCreateLimit)
+	}
+
+	return nil
+}
+
+func (r *ReconcilePerconaXtraDBClusterBackup) releaseSnapshotLock(cr *api.PerconaXtraDBClusterBackup) error {
+	v, ok := r.snapshotLocks.Load(lockKey(cr))
+	if !ok {
+		return nil
+	}
+	r.snapshotLocks.Delete(lockKey(cr))
+
+	return errors.Wrap(v.(*snapshotLock).release(), "release backup lock")
+}
+
+func (r *ReconcilePerconaXtraDBClusterBackup) donorDatabase(cluster *api.PerconaXtraDBCluster, pod string) (queries.Database, error) {
+	host := pod + "." + cluster.Name + "-pxc." + cluster.Namespace
+	database, err := queries.New(r.client, cluster.Namespace, "internal-"+cluster.Name, "operator", host, 33062)
+	if err != nil {
+		return database, errors.Wrapf(err, "failed to access %s", pod)
+	}
+
+	return database, nil
+}
+
+func lockKey(cr *api.PerconaXtraDBClusterBackup) string {
+	return cr.Namespace + "/" + cr.Name
+}
+
+// snapshotDonor returns the ready member with the highest ordinal,
+// the one that is the least likely to be a writer
+func (r *ReconcilePerconaXtraDBClusterBackup) snapshotDonor(cluster *api.PerconaXtraDBCluster) (*corev1.Pod, error) {
+	if cluster.CompareVersionWith("1.6.0") < 0 {
+		return nil, errors.New("snapshot backups require crVersion 1.6.0 or higher")
+	}
+
+	pods := corev1.PodList{}
+	err := r.client.List(context.TODO(),
+		&pods,
+		&client.ListOptions{
+			Namespace:     cluster.Namespace,
+			LabelSelector: labels.SelectorFromSet(statefulset.NewNode(cluster).Labels()),
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "get pods list")
+	}
+
+	ready := []corev1.Pod{}
+	for _, pod := range pods.Items {
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.ContainersReady && cond.Status == corev1.ConditionTrue {
+				ready = append(ready, pod)
+			}
+		}
+	}
+	if len(ready) == 0 {
+		return nil, errors.New("no ready PXC pods")
+	}
+
+	sort.Slice(ready, func(i, j int) bool {
+		return podOrdinal(ready[i].Name) > podOrdinal(ready[j].Name)
+	})
+
+	return &ready[0], nil
+}
+
+func podOrdinal(name string) int {
+	idx := strings.LastIndex(name, "-")
+	n, err := strconv.Atoi(name[idx+1:])
+	if err != nil {
+		return -1
+	}
+
+	return n
+}
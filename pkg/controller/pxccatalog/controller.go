@@ -0,0 +1,331 @@
+package pxccatalog
+
+import (
+	"context"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/zapr"
+	"github.com/minio/minio-go/v7"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/backup"
+)
+
+const defaultIntervalSeconds = 300
+
+// Add creates a new backup catalog Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	r, err := newReconciler(mgr)
+	if err != nil {
+		return err
+	}
+
+	return add(mgr, r)
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) (reconcile.Reconciler, error) {
+	zapLog, err := zap.NewProduction()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create logger")
+	}
+
+	return &ReconcilePerconaXtraDBClusterCatalog{
+		client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		lastSync: new(sync.Map),
+		log:      zapr.NewLogger(zapLog),
+	}, nil
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("perconaxtradbclustercatalog-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to clusters, catalog is configured in the backup storages of the cluster
+	err = c.Watch(&source.Kind{Type: &api.PerconaXtraDBCluster{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcilePerconaXtraDBClusterCatalog{}
+
+// ReconcilePerconaXtraDBClusterCatalog imports backups found on the cluster storages
+type ReconcilePerconaXtraDBClusterCatalog struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client client.Client
+	scheme *runtime.Scheme
+
+	// lastSync keeps time of the last scan for each <namespace>/<cluster>/<storage>
+	lastSync *sync.Map
+	log      logr.Logger
+}
+
+func (r *ReconcilePerconaXtraDBClusterCatalog) logger(name, namespace string) logr.Logger {
+	return log.NewDelegatingLogger(r.log).WithName("perconaxtradbclustercatalog").
+		WithValues("cluster", name, "namespace", namespace)
+}
+
+// Reconcile scans storages with enabled catalog and creates read-only
+// PerconaXtraDBClusterBackup objects for the backups the operator doesn't know about
+func (r *ReconcilePerconaXtraDBClusterCatalog) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	logger := r.logger(request.Name, request.Namespace)
+	rr := reconcile.Result{}
+
+	cr := &api.PerconaXtraDBCluster{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, cr)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return rr, nil
+		}
+		return rr, err
+	}
+
+	if cr.Spec.Backup == nil || cr.DeletionTimestamp != nil {
+		return rr, nil
+	}
+
+	for name, strg := range cr.Spec.Backup.Storages {
+		if strg == nil || strg.Catalog == nil || !strg.Catalog.Enabled {
+			continue
+		}
+		if strg.Type != api.BackupStorageS3 {
+			logger.Info("backup catalog is supported only for s3 storages", "storage", name)
+			continue
+		}
+
+		interval := time.Duration(strg.Catalog.IntervalSeconds) * time.Second
+		if interval <= 0 {
+			interval = defaultIntervalSeconds * time.Second
+		}
+		if rr.RequeueAfter == 0 || interval < rr.RequeueAfter {
+			rr.RequeueAfter = interval
+		}
+
+		key := cr.Namespace + "/" + cr.Name + "/" + name
+		if last, ok := r.lastSync.Load(key); ok && time.Since(last.(time.Time)) < interval {
+			continue
+		}
+
+		err = r.syncStorage(cr, name, strg)
+		if err != nil {
+			logger.Error(err, "failed to sync backup catalog", "storage", name)
+			continue
+		}
+		r.lastSync.Store(key, time.Now())
+	}
+
+	return rr, nil
+}
+
+// backupDirRegex matches backup directories created by the operator: <cluster>-<creation time>-<full|logical>
+var backupDirRegex = regexp.MustCompile(`^([a-z0-9]([-a-z0-9]*[a-z0-9])?)-(\d{4}-\d{2}-\d{2}-\d{2}:\d{2}:\d{2})-(full|logical)$`)
+
+type catalogEntry struct {
+	dir        string
+	cluster    string
+	created    time.Time
+	backupType api.BackupType
+}
+
+func parseBackupDir(dir string) (catalogEntry, bool) {
+	m := backupDirRegex.FindStringSubmatch(dir)
+	if m == nil {
+		return catalogEntry{}, false
+	}
+
+	created, err := time.Parse("2006-01-02-15:04:05", m[3])
+	if err != nil {
+		return catalogEntry{}, false
+	}
+
+	e := catalogEntry{
+		dir:        dir,
+		cluster:    m[1],
+		created:    created,
+		backupType: api.BackupTypePhysical,
+	}
+	if m[4] == "logical" {
+		e.backupType = api.BackupTypeLogical
+	}
+
+	return e, true
+}
+
+func (r *ReconcilePerconaXtraDBClusterCatalog) syncStorage(cr *api.PerconaXtraDBCluster, storageName string, strg *api.BackupStorageSpec) error {
+	logger := r.logger(cr.Name, cr.Namespace)
+
+	cli, err := backup.NewS3Client(r.client, cr.Namespace, strg.S3)
+	if err != nil {
+		return errors.Wrap(err, "create s3 client")
+	}
+
+	bucketPath := strings.Trim(strings.TrimPrefix(strg.S3.Bucket, "s3://"), "/")
+	spl := strings.SplitN(bucketPath, "/", 2)
+	bucket := spl[0]
+	prefix := ""
+	if len(spl) == 2 {
+		prefix = spl[1]
+	}
+	if strg.Catalog.Prefix != "" {
+		prefix = path.Join(prefix, strg.Catalog.Prefix)
+	}
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	known, err := r.knownDestinations(cr.Namespace)
+	if err != nil {
+		return err
+	}
+
+	for obj := range cli.ListObjects(context.TODO(), bucket, minio.ListObjectsOptions{Prefix: prefix}) {
+		if obj.Err != nil {
+			return errors.Wrap(obj.Err, "list objects")
+		}
+		// backups are stored in directories, everything else (md5 sums, binlogs, etc.) is skipped
+		if !strings.HasSuffix(obj.Key, "/") {
+			continue
+		}
+
+		entry, ok := parseBackupDir(strings.TrimSuffix(strings.TrimPrefix(obj.Key, prefix), "/"))
+		if !ok {
+			continue
+		}
+
+		dest := normalizeDestination("s3://" + bucket + "/" + prefix + entry.dir)
+		if _, ok := known[dest]; ok {
+			continue
+		}
+
+		size, err := backup.S3BackupSize(cli, dest)
+		if err != nil {
+			return errors.Wrapf(err, "get size of %s", dest)
+		}
+
+		err = r.importBackup(cr, storageName, strg, dest, entry, size)
+		if err != nil {
+			return errors.Wrapf(err, "import %s", dest)
+		}
+		logger.Info("backup imported from storage", "storage", storageName, "destination", dest)
+	}
+
+	return nil
+}
+
+// knownDestinations returns destinations of all backups in the namespace
+func (r *ReconcilePerconaXtraDBClusterCatalog) knownDestinations(namespace string) (map[string]struct{}, error) {
+	bcpList := api.PerconaXtraDBClusterBackupList{}
+	err := r.client.List(context.TODO(),
+		&bcpList,
+		&client.ListOptions{
+			Namespace: namespace,
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "get backups list")
+	}
+
+	known := make(map[string]struct{}, len(bcpList.Items))
+	for _, bcp := range bcpList.Items {
+		if bcp.Status.Destination != "" {
+			known[normalizeDestination(bcp.Status.Destination)] = struct{}{}
+		}
+	}
+
+	return known, nil
+}
+
+// normalizeDestination makes destinations of the same backup equal,
+// e.g. the bucket of the storage can be set with or without the s3:// prefix and the trailing slash
+func normalizeDestination(dest string) string {
+	if !strings.HasPrefix(dest, "s3://") {
+		return dest
+	}
+
+	return "s3://" + strings.Trim(path.Clean(strings.TrimPrefix(dest, "s3://")), "/")
+}
+
+func (r *ReconcilePerconaXtraDBClusterCatalog) importBackup(cr *api.PerconaXtraDBCluster, storageName string, strg *api.BackupStorageSpec,
+	dest string, entry catalogEntry, size int64) error {
+	bcp := &api.PerconaXtraDBClusterBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "catalog-" + strings.ReplaceAll(entry.dir, ":", ""),
+			Namespace: cr.Namespace,
+			Labels: map[string]string{
+				"cluster":              entry.cluster,
+				api.LabelBackupCatalog: "true",
+			},
+		},
+		Spec: api.PXCBackupSpec{
+			PXCCluster:  entry.cluster,
+			StorageName: storageName,
+			Type:        entry.backupType,
+		},
+	}
+
+	err := r.client.Create(context.TODO(), bcp)
+	if k8serrors.IsAlreadyExists(err) {
+		// the status of the backup created earlier may be not written yet
+		err = r.client.Get(context.TODO(), types.NamespacedName{Name: bcp.Name, Namespace: bcp.Namespace}, bcp)
+		if err != nil {
+			return errors.Wrap(err, "get backup")
+		}
+		if bcp.Status.State != "" {
+			return nil
+		}
+	} else if err != nil {
+		return errors.Wrap(err, "create backup")
+	}
+
+	s3 := strg.S3
+	bcp.Status = api.PXCBackupStatus{
+		State:       api.BackupSucceeded,
+		Type:        entry.backupType,
+		StartedAt:   &metav1.Time{Time: entry.created},
+		CompletedAt: &metav1.Time{Time: entry.created},
+		Destination: dest,
+		StorageName: storageName,
+		S3:          &s3,
+		Size:        backup.HumanSize(size),
+	}
+
+	err = r.client.Status().Update(context.TODO(), bcp)
+	if err != nil {
+		// may be it's k8s v1.10 and erlier (e.g. oc3.9) that doesn't support status updates
+		// so try to update whole CR
+		err := r.client.Update(context.TODO(), bcp)
+		if err != nil {
+			return errors.Wrap(err, "send update")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package pxccatalog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake" // nolint
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+)
+
+func TestParseBackupDir(t *testing.T) {
+	created := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	cases := []struct {
+		dir     string
+		ok      bool
+		cluster string
+		bcpType api.BackupType
+	}{
+		{"cluster1-2021-03-04-05:06:07-full", true, "cluster1", api.BackupTypePhysical},
+		{"my-cluster-2021-03-04-05:06:07-logical", true, "my-cluster", api.BackupTypeLogical},
+		{"cluster1-2021-03-04-05:06:07-full.sst_info", false, "", ""},
+		{"cluster1-2021-03-04-05:06:07-incremental", false, "", ""},
+		{"cluster1-2021-13-04-05:06:07-full", false, "", ""},
+		{"Cluster1-2021-03-04-05:06:07-full", false, "", ""},
+		{"2021-03-04-05:06:07-full", false, "", ""},
+		{"binlog_1614834367_f0c4b1a8", false, "", ""},
+	}
+
+	for _, c := range cases {
+		e, ok := parseBackupDir(c.dir)
+		if ok != c.ok {
+			t.Errorf("%s: expected ok=%t, got %t", c.dir, c.ok, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if e.dir != c.dir || e.cluster != c.cluster || e.backupType != c.bcpType || !e.created.Equal(created) {
+			t.Errorf("%s: unexpected entry %+v", c.dir, e)
+		}
+	}
+}
+
+func TestNormalizeDestination(t *testing.T) {
+	cases := []struct {
+		dest     string
+		expected string
+	}{
+		{"s3://bucket/cluster1-2021-03-04-05:06:07-full", "s3://bucket/cluster1-2021-03-04-05:06:07-full"},
+		{"s3://bucket/cluster1-2021-03-04-05:06:07-full/", "s3://bucket/cluster1-2021-03-04-05:06:07-full"},
+		{"s3://bucket//prefix/cluster1-2021-03-04-05:06:07-full", "s3://bucket/prefix/cluster1-2021-03-04-05:06:07-full"},
+		{"s3://bucket/prefix/./cluster1-2021-03-04-05:06:07-full", "s3://bucket/prefix/cluster1-2021-03-04-05:06:07-full"},
+		{"pvc/xb-backup1", "pvc/xb-backup1"},
+	}
+
+	for _, c := range cases {
+		if got := normalizeDestination(c.dest); got != c.expected {
+			t.Errorf("%s: expected %s, got %s", c.dest, c.expected, got)
+		}
+	}
+}
+
+func TestImportBackupWithoutStatus(t *testing.T) {
+	created := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	cr := &api.PerconaXtraDBCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Namespace: "pxc"}}
+	strg := &api.BackupStorageSpec{Type: api.BackupStorageS3, S3: api.BackupStorageS3Spec{Bucket: "bucket"}}
+	entry := catalogEntry{dir: "cluster1-2021-03-04-05:06:07-full", cluster: "cluster1", backupType: api.BackupTypePhysical, created: created}
+	dest := "s3://bucket/" + entry.dir
+
+	// the backup is created by the previous sync, but its status isn't written
+	existing := &api.PerconaXtraDBClusterBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "catalog-cluster1-2021-03-04-050607-full", Namespace: "pxc"},
+		Spec:       api.PXCBackupSpec{PXCCluster: "cluster1", StorageName: "s3-us-west"},
+	}
+	s := scheme.Scheme
+	s.AddKnownTypes(api.SchemeGroupVersion, &api.PerconaXtraDBClusterBackup{}, &api.PerconaXtraDBClusterBackupList{})
+	r := &ReconcilePerconaXtraDBClusterCatalog{client: fake.NewFakeClientWithScheme(s, existing), scheme: s, log: logf.NullLogger{}}
+
+	err := r.importBackup(cr, "s3-us-west", strg, dest, entry, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bcp := &api.PerconaXtraDBClusterBackup{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: existing.Name, Namespace: existing.Namespace}, bcp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bcp.Status.State != api.BackupSucceeded || bcp.Status.Destination != dest || bcp.Status.S3 == nil {
+		t.Errorf("status of the imported backup isn't filled in: %+v", bcp.Status)
+	}
+}
@@ -0,0 +1,455 @@
+package pxcoperation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/zapr"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/percona/percona-xtradb-cluster-operator/clientcmd"
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/controller/clusterlock"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/k8s"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/statefulset"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/queries"
+	"github.com/percona/percona-xtradb-cluster-operator/version"
+)
+
+// Add creates a new PerconaXtraDBClusterOperation Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+// The lock store has to be the one of the cluster controller.
+func Add(mgr manager.Manager, locks clusterlock.Store) error {
+	r, err := newReconciler(mgr, locks)
+	if err != nil {
+		return err
+	}
+	return add(mgr, r)
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, locks clusterlock.Store) (reconcile.Reconciler, error) {
+	sv, err := version.Server()
+	if err != nil {
+		return nil, errors.Wrap(err, "get version")
+	}
+
+	zapLog, err := zap.NewProduction()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create logger")
+	}
+
+	cli, err := clientcmd.NewClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "create clientcmd")
+	}
+
+	return &ReconcilePerconaXtraDBClusterOperation{
+		client:        mgr.GetClient(),
+		scheme:        mgr.GetScheme(),
+		serverVersion: sv,
+		clientcmd:     cli,
+		lockers:       locks,
+		log:           zapr.NewLogger(zapLog),
+		recorder:      mgr.GetEventRecorderFor("pxcoperation-controller"),
+	}, nil
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	c, err := controller.New("perconaxtradbclusteroperation-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	return c.Watch(&source.Kind{Type: &api.PerconaXtraDBClusterOperation{}}, &handler.EnqueueRequestForObject{})
+}
+
+const (
+	internalPrefix = "internal-"
+	datadirVolume  = "datadir"
+)
+
+var _ reconcile.Reconciler = &ReconcilePerconaXtraDBClusterOperation{}
+
+// ReconcilePerconaXtraDBClusterOperation runs day-2 operations on the clusters. It shares the locks
+// of the cluster controller, so a step of the operation never runs together with the
+// reconcile of the cluster or the smart update. Every step is short, the operation
+// is requeued until it's finished.
+type ReconcilePerconaXtraDBClusterOperation struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client client.Client
+	scheme *runtime.Scheme
+
+	serverVersion *version.ServerVersion
+	clientcmd     *clientcmd.Client
+	lockers       clusterlock.Store
+	log           logr.Logger
+	recorder      record.EventRecorder
+}
+
+func (r *ReconcilePerconaXtraDBClusterOperation) logger(name, namespace string) logr.Logger {
+	return log.NewDelegatingLogger(r.log).WithName("perconaxtradbclusteroperation").
+		WithValues("cluster", name, "namespace", namespace)
+}
+
+// Reconcile runs the next step of the PerconaXtraDBClusterOperation
+func (r *ReconcilePerconaXtraDBClusterOperation) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	rr := reconcile.Result{
+		RequeueAfter: time.Second * 5,
+	}
+
+	op := &api.PerconaXtraDBClusterOperation{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, op)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if op.Status.State.Finished() {
+		return reconcile.Result{}, nil
+	}
+
+	log := r.logger(op.Spec.PXCCluster, op.Namespace).WithValues("operation", op.Name, "type", op.Spec.Type)
+
+	err = op.CheckNsetDefaults()
+	if err != nil {
+		return reconcile.Result{}, r.finishOperation(op, errors.Wrap(err, "invalid operation"))
+	}
+
+	l := r.lockers.LoadOrCreate(types.NamespacedName{Namespace: op.Namespace, Name: op.Spec.PXCCluster}.String())
+	l.StatusMutex.Lock()
+	defer l.StatusMutex.Unlock()
+
+	cr := &api.PerconaXtraDBCluster{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Namespace: op.Namespace, Name: op.Spec.PXCCluster}, cr)
+	if k8serrors.IsNotFound(err) {
+		return reconcile.Result{}, r.finishOperation(op, errors.Errorf("cluster %s not found", op.Spec.PXCCluster))
+	}
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "get cluster")
+	}
+	_, err = cr.CheckNSetDefaults(r.serverVersion, log)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "wrong PXC options")
+	}
+
+	if op.Status.State == api.OperationNew || op.Status.State == api.OperationPending {
+		msg, err := r.operationBlocked(op, cr)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if msg != "" {
+			op.Status.State = api.OperationPending
+			op.Status.Message = msg
+			return rr, r.writeOperationStatus(op)
+		}
+
+		// other operations of the cluster have to see this one running before it does anything
+		now := metav1.Now()
+		op.Status.State = api.OperationRunning
+		op.Status.Message = ""
+		op.Status.StartedAt = &now
+		err = r.writeOperationStatus(op)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		log.Info("operation started")
+		r.recorder.Eventf(op, corev1.EventTypeNormal, "OperationStarted", "%s is started on cluster %s", op.Spec.Type, cr.Name)
+	}
+
+	done, err := r.runOperation(op, cr)
+	if err != nil {
+		log.Error(err, "operation failed")
+		return reconcile.Result{}, r.finishOperation(op, err)
+	}
+	if done {
+		log.Info("operation succeeded")
+		return reconcile.Result{}, r.finishOperation(op, nil)
+	}
+
+	if r.operationTimedOut(op, cr) {
+		return reconcile.Result{}, r.finishOperation(op, errors.Errorf("timed out: %s", op.Status.Message))
+	}
+
+	return rr, r.writeOperationStatus(op)
+}
+
+func (r *ReconcilePerconaXtraDBClusterOperation) runOperation(op *api.PerconaXtraDBClusterOperation, cr *api.PerconaXtraDBCluster) (bool, error) {
+	switch op.Spec.Type {
+	case api.OperationRestartMember:
+		return r.restartMember(op, cr, false)
+	case api.OperationForceSST:
+		return r.restartMember(op, cr, true)
+	case api.OperationRotatePasswords:
+		return r.rotatePasswords(op, cr)
+	case api.OperationAnalyzeTables:
+		return true, r.maintainTables(op, cr, "ANALYZE")
+	case api.OperationOptimizeTables:
+		return true, r.maintainTables(op, cr, "OPTIMIZE")
+	case api.OperationFlushLogs:
+		return true, r.onEveryMember(op, cr, func(db *queries.Database) (string, error) {
+			return "", db.Exec("FLUSH LOGS")
+		})
+	case api.OperationKillQueries:
+		return true, r.onEveryMember(op, cr, func(db *queries.Database) (string, error) {
+			ids, err := db.KillLongQueries(op.Spec.QueryTimeSeconds)
+			if len(ids) == 0 {
+				return "", err
+			}
+			return fmt.Sprintf("killed queries of sessions %s", joinIDs(ids)), err
+		})
+	case api.OperationReevaluateWriter:
+		return true, r.reevaluateWriter(op, cr)
+	}
+
+	return false, errors.Errorf("unknown operation type %s", op.Spec.Type)
+}
+
+// operationBlocked returns the reason the operation can't be started now.
+// Operations of the cluster run one at a time in the order they are created.
+// Operations taking a member down wait for the whole cluster to be ready.
+func (r *ReconcilePerconaXtraDBClusterOperation) operationBlocked(op *api.PerconaXtraDBClusterOperation, cr *api.PerconaXtraDBCluster) (string, error) {
+	if cr.DeletionTimestamp != nil {
+		return "cluster is being deleted", nil
+	}
+	if cr.Spec.Pause {
+		return "cluster is paused", nil
+	}
+
+	ops := api.PerconaXtraDBClusterOperationList{}
+	err := r.client.List(context.TODO(), &ops, &client.ListOptions{Namespace: op.Namespace})
+	if err != nil {
+		return "", errors.Wrap(err, "get operations list")
+	}
+	for _, o := range ops.Items {
+		if o.Spec.PXCCluster != op.Spec.PXCCluster || o.Name == op.Name || o.Status.State.Finished() {
+			continue
+		}
+		if o.Status.State == api.OperationRunning || o.CreationTimestamp.Before(&op.CreationTimestamp) ||
+			(o.CreationTimestamp.Equal(&op.CreationTimestamp) && o.Name < op.Name) {
+			return fmt.Sprintf("waiting for operation %s to finish", o.Name), nil
+		}
+	}
+
+	running, err := k8s.IsRestoreRunning(r.client, cr.Name, cr.Namespace)
+	if err != nil {
+		return "", err
+	}
+	if running {
+		return "waiting for the restore to finish", nil
+	}
+
+	switch op.Spec.Type {
+	case api.OperationRestartMember, api.OperationForceSST, api.OperationRotatePasswords:
+		if cr.Status.Status != api.AppStateReady {
+			return "waiting for the cluster to be ready", nil
+		}
+		if cr.Status.IsConditionTrue(api.ConditionUpgradeInProgress) {
+			return "waiting for the update of the cluster to finish", nil
+		}
+		running, err := k8s.IsBackupRunning(r.client, cr)
+		if err != nil {
+			return "", err
+		}
+		if running {
+			return "waiting for the backup to finish", nil
+		}
+	default:
+		if cr.Status.PXC.Ready < 1 {
+			return "waiting for PXC members to be ready", nil
+		}
+	}
+
+	return "", nil
+}
+
+// maintainTables runs ANALYZE or OPTIMIZE TABLE. Both statements are replicated,
+// so they are run on a single member.
+func (r *ReconcilePerconaXtraDBClusterOperation) maintainTables(op *api.PerconaXtraDBClusterOperation, cr *api.PerconaXtraDBCluster, stmt string) error {
+	database, err := queries.New(r.client, cr.Namespace, internalPrefix+cr.Name, "operator", cr.Name+"-pxc."+cr.Namespace, 33062)
+	if err != nil {
+		return errors.Wrap(err, "failed to access PXC database")
+	}
+	defer database.Close()
+
+	op.Status.Results = nil
+	for _, t := range op.Spec.Tables {
+		msgs, err := database.MaintainTable(stmt, t)
+		if err != nil {
+			return err
+		}
+		op.Status.Results = append(op.Status.Results, api.OperationResult{Target: t, Message: strings.Join(msgs, "; ")})
+	}
+
+	return nil
+}
+
+// onEveryMember runs fn on every ready PXC member and keeps its outcome in the results
+func (r *ReconcilePerconaXtraDBClusterOperation) onEveryMember(op *api.PerconaXtraDBClusterOperation, cr *api.PerconaXtraDBCluster, fn func(*queries.Database) (string, error)) error {
+	pods := corev1.PodList{}
+	err := r.client.List(context.TODO(),
+		&pods,
+		&client.ListOptions{
+			Namespace:     cr.Namespace,
+			LabelSelector: labels.SelectorFromSet(statefulset.NewNode(cr).Labels()),
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "get pods list")
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+
+	op.Status.Results = nil
+	var failed []string
+	for _, pod := range pods.Items {
+		if !k8s.IsPodReady(&pod) {
+			continue
+		}
+
+		msg, err := func() (string, error) {
+			database, err := queries.New(r.client, cr.Namespace, internalPrefix+cr.Name, "operator", pod.Name+"."+cr.Name+"-pxc."+cr.Namespace, 33062)
+			if err != nil {
+				return "", errors.Wrap(err, "failed to access PXC database")
+			}
+			defer database.Close()
+
+			return fn(&database)
+		}()
+		if err != nil {
+			failed = append(failed, pod.Name)
+			msg = strings.TrimPrefix(msg+"; "+err.Error(), "; ")
+		}
+		op.Status.Results = append(op.Status.Results, api.OperationResult{Target: pod.Name, Message: msg})
+	}
+
+	if len(failed) > 0 {
+		return errors.Errorf("failed on %s", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// reevaluateWriter makes the proxies check the members and configure the writer again,
+// the same way they do when the members of the cluster change
+func (r *ReconcilePerconaXtraDBClusterOperation) reevaluateWriter(op *api.PerconaXtraDBClusterOperation, cr *api.PerconaXtraDBCluster) error {
+	var lbls map[string]string
+	switch {
+	case cr.HAProxyEnabled():
+		lbls = statefulset.NewHAProxy(cr).Labels()
+	case cr.ProxySQLEnabled():
+		lbls = statefulset.NewProxy(cr).Labels()
+	default:
+		return errors.New("cluster has neither HAProxy nor ProxySQL")
+	}
+
+	pods := corev1.PodList{}
+	err := r.client.List(context.TODO(),
+		&pods,
+		&client.ListOptions{
+			Namespace:     cr.Namespace,
+			LabelSelector: labels.SelectorFromSet(lbls),
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "get proxy pods list")
+	}
+
+	op.Status.Results = nil
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		var errb bytes.Buffer
+		err := r.clientcmd.Exec(pod, "pxc-monit", []string{"/bin/sh", "-c", "/usr/bin/peer-list -on-start=/usr/bin/add_pxc_nodes.sh -service=$PXC_SERVICE"}, nil, nil, &errb, false)
+		if err != nil {
+			return errors.Wrapf(err, "reconfigure %s: %s", pod.Name, strings.TrimSpace(errb.String()))
+		}
+		op.Status.Results = append(op.Status.Results, api.OperationResult{Target: pod.Name, Message: "reconfigured"})
+	}
+	if len(op.Status.Results) == 0 {
+		return errors.New("no running proxy pods")
+	}
+
+	op.Status.Writer, err = queries.PrimaryPod(r.client, cr)
+	return errors.Wrap(err, "get the writer")
+}
+
+// operationTimedOut checks if the operation waits for the member longer than the smart update would
+func (r *ReconcilePerconaXtraDBClusterOperation) operationTimedOut(op *api.PerconaXtraDBClusterOperation, cr *api.PerconaXtraDBCluster) bool {
+	if op.Status.StartedAt == nil {
+		return false
+	}
+
+	waitLimit := 2 * 60 * 60 // 2 hours
+	if cr.Spec.PXC.LivenessInitialDelaySeconds != nil {
+		waitLimit = int(*cr.Spec.PXC.LivenessInitialDelaySeconds)
+	}
+
+	return time.Since(op.Status.StartedAt.Time) > time.Duration(waitLimit)*time.Second
+}
+
+func (r *ReconcilePerconaXtraDBClusterOperation) finishOperation(op *api.PerconaXtraDBClusterOperation, opErr error) error {
+	now := metav1.Now()
+	op.Status.CompletedAt = &now
+	if opErr != nil {
+		op.Status.State = api.OperationFailed
+		op.Status.Message = opErr.Error()
+		r.recorder.Eventf(op, corev1.EventTypeWarning, "OperationFailed", "%s failed: %v", op.Spec.Type, opErr)
+	} else {
+		op.Status.State = api.OperationSucceeded
+		op.Status.Message = ""
+		r.recorder.Eventf(op, corev1.EventTypeNormal, "OperationSucceeded", "%s succeeded", op.Spec.Type)
+	}
+
+	return r.writeOperationStatus(op)
+}
+
+func (r *ReconcilePerconaXtraDBClusterOperation) writeOperationStatus(op *api.PerconaXtraDBClusterOperation) error {
+	err := r.client.Status().Update(context.TODO(), op)
+	if err != nil {
+		// may be it's k8s v1.10 and erlier (e.g. oc3.9) that doesn't support status updates
+		// so try to update whole CR
+		err := r.client.Update(context.TODO(), op)
+		if err != nil {
+			return errors.Wrap(err, "send update")
+		}
+	}
+
+	return nil
+}
+
+func joinIDs(ids []int64) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = fmt.Sprint(id)
+	}
+
+	return strings.Join(s, ", ")
+}
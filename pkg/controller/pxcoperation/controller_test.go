@@ -0,0 +1,307 @@
+package pxcoperation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake" // nolint
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/controller/clusterlock"
+)
+
+func buildFakeClient(objs ...runtime.Object) *ReconcilePerconaXtraDBClusterOperation {
+	s := scheme.Scheme
+	s.AddKnownTypes(api.SchemeGroupVersion,
+		&api.PerconaXtraDBCluster{}, &api.PerconaXtraDBClusterList{},
+		&api.PerconaXtraDBClusterOperation{}, &api.PerconaXtraDBClusterOperationList{},
+		&api.PerconaXtraDBClusterBackup{}, &api.PerconaXtraDBClusterBackupList{},
+		&api.PerconaXtraDBClusterRestore{}, &api.PerconaXtraDBClusterRestoreList{},
+	)
+
+	return &ReconcilePerconaXtraDBClusterOperation{
+		client:   fake.NewFakeClientWithScheme(s, objs...),
+		scheme:   s,
+		lockers:  clusterlock.NewStore(),
+		log:      logf.NullLogger{},
+		recorder: record.NewFakeRecorder(10),
+	}
+}
+
+func newCluster() *api.PerconaXtraDBCluster {
+	return &api.PerconaXtraDBCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Namespace: "pxc"},
+		Spec: api.PerconaXtraDBClusterSpec{
+			SecretsName: "my-cluster-secrets",
+			PXC: &api.PXCSpec{
+				PodSpec: &api.PodSpec{Enabled: true, Size: 3},
+			},
+		},
+		Status: api.PerconaXtraDBClusterStatus{Status: api.AppStateReady},
+	}
+}
+
+func newOperation(name string, opType api.OperationType, created time.Time) *api.PerconaXtraDBClusterOperation {
+	return &api.PerconaXtraDBClusterOperation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "pxc",
+			UID:               types.UID(name + "-uid"),
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: api.PerconaXtraDBClusterOperationSpec{
+			PXCCluster: "cluster1",
+			Type:       opType,
+		},
+	}
+}
+
+func getOperation(t *testing.T, r *ReconcilePerconaXtraDBClusterOperation, name string) *api.PerconaXtraDBClusterOperation {
+	op := &api.PerconaXtraDBClusterOperation{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "pxc"}, op)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return op
+}
+
+func TestOperationBlocked(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		cluster  func(*api.PerconaXtraDBCluster)
+		others   []*api.PerconaXtraDBClusterOperation
+		expected string
+	}{
+		{"free", nil, nil, ""},
+		{"paused", func(cr *api.PerconaXtraDBCluster) { cr.Spec.Pause = true }, nil, "cluster is paused"},
+		{"not ready", func(cr *api.PerconaXtraDBCluster) { cr.Status.Status = api.AppStateInit }, nil, "waiting for the cluster to be ready"},
+		{"older operation", nil, []*api.PerconaXtraDBClusterOperation{
+			newOperation("older", api.OperationFlushLogs, now.Add(-time.Minute)),
+		}, "waiting for operation older to finish"},
+		{"newer running operation", nil, []*api.PerconaXtraDBClusterOperation{
+			func() *api.PerconaXtraDBClusterOperation {
+				o := newOperation("newer", api.OperationFlushLogs, now.Add(time.Minute))
+				o.Status.State = api.OperationRunning
+				return o
+			}(),
+		}, "waiting for operation newer to finish"},
+		{"finished operation", nil, []*api.PerconaXtraDBClusterOperation{
+			func() *api.PerconaXtraDBClusterOperation {
+				o := newOperation("older", api.OperationFlushLogs, now.Add(-time.Minute))
+				o.Status.State = api.OperationSucceeded
+				return o
+			}(),
+		}, ""},
+		{"operation of another cluster", nil, []*api.PerconaXtraDBClusterOperation{
+			func() *api.PerconaXtraDBClusterOperation {
+				o := newOperation("older", api.OperationFlushLogs, now.Add(-time.Minute))
+				o.Spec.PXCCluster = "cluster2"
+				return o
+			}(),
+		}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := newCluster()
+			if tt.cluster != nil {
+				tt.cluster(cr)
+			}
+			op := newOperation("op", api.OperationRotatePasswords, now)
+			objs := []runtime.Object{cr, op}
+			for _, o := range tt.others {
+				objs = append(objs, o)
+			}
+			r := buildFakeClient(objs...)
+
+			msg, err := r.operationBlocked(op, cr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, msg)
+			}
+		})
+	}
+}
+
+func TestReconcileClusterNotFound(t *testing.T) {
+	op := newOperation("op", api.OperationFlushLogs, time.Now())
+	r := buildFakeClient(op)
+
+	_, err := r.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: op.Name, Namespace: op.Namespace}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	op = getOperation(t, r, op.Name)
+	if op.Status.State != api.OperationFailed || op.Status.Message != "cluster cluster1 not found" {
+		t.Errorf("unexpected status %+v", op.Status)
+	}
+}
+
+func TestRotatePasswords(t *testing.T) {
+	cr := newCluster()
+	op := newOperation("op", api.OperationRotatePasswords, time.Now())
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: cr.Spec.SecretsName, Namespace: cr.Namespace},
+		Data: map[string][]byte{
+			"root":      []byte("root-pass"),
+			"operator":  []byte("operator-pass"),
+			"pmmserver": []byte("pmm-pass"),
+		},
+	}
+	internal := secret.DeepCopy()
+	internal.Name = internalPrefix + cr.Name
+	r := buildFakeClient(cr, op, secret, internal)
+
+	getSecret := func(name string) *corev1.Secret {
+		s := &corev1.Secret{}
+		err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: cr.Namespace}, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	done, err := r.rotatePasswords(op, cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done {
+		t.Fatal("passwords are rotated before they are applied")
+	}
+
+	// the status write after the secret update is lost, the operation continues from the saved marker
+	saved := getOperation(t, r, op.Name)
+	if len(saved.Status.Results) != 2 {
+		t.Fatalf("expected users to rotate to be saved before the secret update, got %+v", saved.Status.Results)
+	}
+	rotated := getSecret(cr.Spec.SecretsName)
+	if rotated.Annotations[annotationRotatedBy] != string(op.UID) {
+		t.Errorf("secret isn't marked by the operation: %v", rotated.Annotations)
+	}
+	for _, user := range []string{"root", "operator"} {
+		if string(rotated.Data[user]) == string(secret.Data[user]) {
+			t.Errorf("password of %s isn't rotated", user)
+		}
+	}
+	if string(rotated.Data["pmmserver"]) != "pmm-pass" {
+		t.Error("password of pmmserver is rotated")
+	}
+
+	done, err = r.rotatePasswords(saved, cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done {
+		t.Fatal("passwords are rotated before they are applied")
+	}
+	again := getSecret(cr.Spec.SecretsName)
+	if string(again.Data["root"]) != string(rotated.Data["root"]) {
+		t.Error("passwords are generated twice")
+	}
+
+	// the cluster reconcile applies the new passwords
+	internal = getSecret(internalPrefix + cr.Name)
+	internal.Data = again.Data
+	err = r.client.Update(context.TODO(), internal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done, err = r.rotatePasswords(saved, cr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !done {
+		t.Errorf("passwords aren't rotated after they are applied: %s", saved.Status.Message)
+	}
+}
+
+func TestRestartMemberForceSST(t *testing.T) {
+	cr := newCluster()
+	op := newOperation("op", api.OperationForceSST, time.Now())
+	op.Spec.Member = "cluster1-pxc-1"
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: op.Spec.Member, Namespace: cr.Namespace, UID: "old-pod"}}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: datadirVolume + "-" + op.Spec.Member, Namespace: cr.Namespace, UID: "old-pvc"},
+	}
+	r := buildFakeClient(cr, op, pod, pvc)
+
+	done, err := r.restartMember(op, cr, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done {
+		t.Fatal("member is restarted before the volume is replaced")
+	}
+	saved := getOperation(t, r, op.Name)
+	if saved.Status.PVCUID != pvc.UID || saved.Status.PodUID != pod.UID {
+		t.Fatalf("deleted pvc and pod aren't saved before the deletion: %+v", saved.Status)
+	}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: pvc.Name, Namespace: cr.Namespace}, &corev1.PersistentVolumeClaim{})
+	if !k8serrors.IsNotFound(err) {
+		t.Fatalf("expected the pvc to be deleted, got %v", err)
+	}
+
+	done, err = r.restartMember(op, cr, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done || op.Status.Message != "waiting for the pod with the new volume to be ready" {
+		t.Fatalf("member is restarted without the new volume: %s", op.Status.Message)
+	}
+
+	// the StatefulSet creates the new pod together with the new volume
+	newPVC := pvc.DeepCopy()
+	newPVC.UID = "new-pvc"
+	newPVC.ResourceVersion = ""
+	newPod := pod.DeepCopy()
+	newPod.UID = "new-pod"
+	newPod.ResourceVersion = ""
+	newPod.Status.Conditions = []corev1.PodCondition{{Type: corev1.ContainersReady, Status: corev1.ConditionTrue}}
+	for _, obj := range []runtime.Object{newPVC, newPod} {
+		err = r.client.Create(context.TODO(), obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	done, err = r.restartMember(op, cr, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done || op.Status.Message != "waiting for the member to be synced" {
+		t.Errorf("expected to wait for the member to be synced, got %s", op.Status.Message)
+	}
+}
+
+func TestIsMember(t *testing.T) {
+	cr := newCluster()
+
+	tests := map[string]bool{
+		"cluster1-pxc-0": true,
+		"cluster1-pxc-2": true,
+		"cluster1-pxc-3": false,
+		"cluster1-pxc-a": false,
+		"cluster1":       false,
+		"other-pxc-0":    false,
+	}
+	for pod, expected := range tests {
+		if got := isMember(cr, pod); got != expected {
+			t.Errorf("%s: expected %t, got %t", pod, expected, got)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package pxcoperation
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/k8s"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/statefulset"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/queries"
+)
+
+// restartMember deletes the member pod and waits until the new one is synced with the cluster.
+// With forceSST the datadir volume is deleted too, so the member gets the data with SST.
+func (r *ReconcilePerconaXtraDBClusterOperation) restartMember(op *api.PerconaXtraDBClusterOperation, cr *api.PerconaXtraDBCluster, forceSST bool) (bool, error) {
+	podName := op.Spec.Member
+	pvcName := datadirVolume + "-" + podName
+
+	pod := &corev1.Pod{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: podName, Namespace: cr.Namespace}, pod)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return false, errors.Wrap(err, "get pod")
+	}
+	podExists := err == nil
+
+	if op.Status.PodUID == "" {
+		if !isMember(cr, podName) || !podExists {
+			return false, errors.Errorf("%s isn't a member of cluster %s", podName, cr.Name)
+		}
+
+		if forceSST {
+			if cr.Spec.PXC.Size < 2 {
+				return false, errors.New("at least 2 members are needed to get data with SST")
+			}
+
+			pvc := &corev1.PersistentVolumeClaim{}
+			err = r.client.Get(context.TODO(), types.NamespacedName{Name: pvcName, Namespace: cr.Namespace}, pvc)
+			if err != nil {
+				return false, errors.Wrapf(err, "get pvc %s", pvcName)
+			}
+			op.Status.PodUID = pod.UID
+			op.Status.PVCUID = pvc.UID
+			// the status has to be saved before the volume is gone, the next reconcile continues from it
+			err = r.writeOperationStatus(op)
+			if err != nil {
+				return false, errors.Wrap(err, "write operation status")
+			}
+			_, err = k8s.ReplaceVolume(r.client, cr.Namespace, podName, pvcName, pvc.UID, nil)
+			if err != nil {
+				return false, errors.Wrapf(err, "replace datadir volume of %s", podName)
+			}
+			r.recorder.Eventf(cr, corev1.EventTypeNormal, "ForceSST", "datadir volume of %s is deleted by operation %s", podName, op.Name)
+			op.Status.Message = "datadir volume and pod are deleted"
+
+			return false, nil
+		}
+
+		op.Status.PodUID = pod.UID
+		err = r.client.Delete(context.TODO(), pod, &client.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &pod.UID}})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return false, errors.Wrap(err, "delete pod")
+		}
+		r.recorder.Eventf(cr, corev1.EventTypeNormal, "MemberRestart", "%s is restarted by operation %s", podName, op.Name)
+		op.Status.Message = "pod is deleted"
+
+		return false, nil
+	}
+
+	if forceSST {
+		ready, err := k8s.ReplaceVolume(r.client, cr.Namespace, podName, pvcName, op.Status.PVCUID, nil)
+		if err != nil {
+			return false, errors.Wrapf(err, "replace datadir volume of %s", podName)
+		}
+		if !ready {
+			op.Status.Message = "waiting for the pod with the new volume to be ready"
+			return false, nil
+		}
+	}
+
+	if !podExists || pod.UID == op.Status.PodUID || !k8s.IsPodReady(pod) {
+		op.Status.Message = "waiting for the pod to be ready"
+		return false, nil
+	}
+
+	st, err := queries.MemberWsrepStatus(r.client, cr, podName)
+	if err != nil || st.LocalStateComment != "Synced" {
+		op.Status.Message = "waiting for the member to be synced"
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// isMember checks if the pod is one of spec.pxc.size members of the cluster
+func isMember(cr *api.PerconaXtraDBCluster, podName string) bool {
+	sfs := statefulset.NewNode(cr).StatefulSet()
+	if !strings.HasPrefix(podName, sfs.Name+"-") {
+		return false
+	}
+	ord, err := k8s.PodOrderInSts(sfs.Name, podName)
+
+	return err == nil && ord >= 0 && int32(ord) < cr.Spec.PXC.Size
+}
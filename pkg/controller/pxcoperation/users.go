@@ -0,0 +1,91 @@
+package pxcoperation
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/users"
+)
+
+// rotatedUsers are the system users RotatePasswords generates new passwords for.
+// The password of pmmserver belongs to the PMM server, so it isn't rotated.
+var rotatedUsers = []string{"root", "xtrabackup", "monitor", "clustercheck", "proxyadmin", "operator", "replication"}
+
+// annotationRotatedBy is set on the users secret together with the new passwords,
+// it's the UID of the operation which generated them
+const annotationRotatedBy = "percona.com/passwords-rotated-by"
+
+// rotatePasswords sets new passwords of the system users in the users secret
+// and waits for the cluster reconcile to apply them. Users to rotate are saved in the status
+// before the secret is updated, and the secret is marked by the operation together with
+// the new passwords, so a step retried after a lost status write never generates them twice.
+func (r *ReconcilePerconaXtraDBClusterOperation) rotatePasswords(op *api.PerconaXtraDBClusterOperation, cr *api.PerconaXtraDBCluster) (bool, error) {
+	secret := corev1.Secret{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: cr.Spec.SecretsName, Namespace: cr.Namespace}, &secret)
+	if err != nil {
+		return false, errors.Wrapf(err, "get secret %s", cr.Spec.SecretsName)
+	}
+
+	if len(op.Status.Results) == 0 {
+		for _, name := range rotatedUsers {
+			if _, ok := secret.Data[name]; ok {
+				op.Status.Results = append(op.Status.Results, api.OperationResult{Target: name, Message: "updating secret"})
+			}
+		}
+		if len(op.Status.Results) == 0 {
+			return false, errors.Errorf("secret %s has no system users", cr.Spec.SecretsName)
+		}
+		op.Status.Message = "updating secret"
+		err = r.writeOperationStatus(op)
+		if err != nil {
+			return false, errors.Wrap(err, "save users to rotate")
+		}
+	}
+
+	if secret.Annotations[annotationRotatedBy] != string(op.UID) {
+		for _, res := range op.Status.Results {
+			secret.Data[res.Target], err = users.GeneratePass()
+			if err != nil {
+				return false, errors.Wrapf(err, "generate password for %s", res.Target)
+			}
+		}
+		if secret.Annotations == nil {
+			secret.Annotations = make(map[string]string)
+		}
+		secret.Annotations[annotationRotatedBy] = string(op.UID)
+
+		err = r.client.Update(context.TODO(), &secret)
+		if err != nil {
+			return false, errors.Wrapf(err, "update secret %s", cr.Spec.SecretsName)
+		}
+		for i := range op.Status.Results {
+			op.Status.Results[i].Message = "secret updated"
+		}
+		op.Status.Message = "waiting for new passwords to be applied"
+
+		return false, nil
+	}
+
+	internal := corev1.Secret{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: internalPrefix + cr.Name, Namespace: cr.Namespace}, &internal)
+	if err != nil {
+		return false, errors.Wrap(err, "get internal secret")
+	}
+
+	for _, res := range op.Status.Results {
+		if !bytes.Equal(secret.Data[res.Target], internal.Data[res.Target]) {
+			op.Status.Message = "waiting for new passwords to be applied"
+			return false, nil
+		}
+	}
+	for i := range op.Status.Results {
+		op.Status.Results[i].Message = "rotated"
+	}
+
+	return true, nil
+}
@@ -3,6 +3,7 @@ package pxcrestore
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
@@ -12,10 +13,12 @@ import (
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -24,8 +27,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	"github.com/percona/percona-xtradb-cluster-operator/clientcmd"
 	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
 	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/statefulset"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/backup"
 	"github.com/percona/percona-xtradb-cluster-operator/version"
 )
 
@@ -51,11 +56,19 @@ func newReconciler(mgr manager.Manager) (reconcile.Reconciler, error) {
 		return nil, errors.Wrap(err, "failed to create logger")
 	}
 
+	cli, err := clientcmd.NewClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "create clientcmd")
+	}
+
 	return &ReconcilePerconaXtraDBClusterRestore{
 		client:        mgr.GetClient(),
 		scheme:        mgr.GetScheme(),
 		serverVersion: sv,
+		clientcmd:     cli,
 		log:           zapr.NewLogger(zapLog),
+		recorder:      mgr.GetEventRecorderFor("pxcrestore-controller"),
+		restMapper:    mgr.GetRESTMapper(),
 	}, nil
 }
 
@@ -86,7 +99,10 @@ type ReconcilePerconaXtraDBClusterRestore struct {
 	scheme *runtime.Scheme
 
 	serverVersion *version.ServerVersion
+	clientcmd     *clientcmd.Client
 	log           logr.Logger
+	recorder      record.EventRecorder
+	restMapper    meta.RESTMapper
 }
 
 func (r *ReconcilePerconaXtraDBClusterRestore) logger(name, namespace string) logr.Logger {
@@ -99,148 +115,278 @@ func (r *ReconcilePerconaXtraDBClusterRestore) logger(name, namespace string) lo
 // Note:
 // The Controller will requeue the Request to be processed again if the returned error is non-nil or
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+//
+// The restore is a state machine driven by Status.State. Each reconcile
+// advances the current phase without blocking and requeues, so the restore
+// continues from the same phase if the operator is restarted.
 func (r *ReconcilePerconaXtraDBClusterRestore) Reconcile(request reconcile.Request) (reconcile.Result, error) {
-	rr := reconcile.Result{}
+	rr := reconcile.Result{
+		RequeueAfter: time.Second * 5,
+	}
 
 	cr := &api.PerconaXtraDBClusterRestore{}
 	err := r.client.Get(context.TODO(), request.NamespacedName, cr)
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
-			return rr, nil
+			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
-		return rr, err
+		return reconcile.Result{}, err
 	}
-	if cr.Status.State != api.RestoreNew {
-		return rr, nil
+	if cr.Status.State.Finished() {
+		return reconcile.Result{}, r.finalize(cr)
 	}
 
 	lgr := r.logger(request.Name, request.Namespace)
-	lgr.Info("backup restore request")
+	if cr.Status.State == api.RestoreNew {
+		lgr.Info("backup restore request")
+	}
 
-	err = r.setStatus(cr, api.RestoreStarting, "")
-	if err != nil {
-		return rr, errors.Wrap(err, "set status")
+	// unfinished restore can't be just deleted, the cluster has to be rolled back first
+	if cr.DeletionTimestamp == nil && !hasFinalizer(cr, api.FinalizerRollbackRestore) {
+		cr.SetFinalizers(append(cr.GetFinalizers(), api.FinalizerRollbackRestore))
+		err = r.client.Update(context.TODO(), cr)
+		if err != nil {
+			return rr, errors.Wrap(err, "add finalizer")
+		}
+		return rr, nil
 	}
-	rJobsList := &api.PerconaXtraDBClusterRestoreList{}
-	err = r.client.List(
-		context.TODO(),
-		rJobsList,
-		&client.ListOptions{
-			Namespace: cr.Namespace,
-		},
-	)
-	if err != nil {
-		return rr, errors.Wrap(err, "get restore jobs list")
+
+	if cancelRequested(cr) && cr.Status.State != api.RestoreRollback {
+		lgr.Info("restore is cancelled", "state", cr.Status.State)
+		state := api.RestoreRollback
+		if cr.Status.State == api.RestoreNew || cr.Status.State == api.RestoreStarting {
+			state = api.RestoreCancelled
+		}
+		return rr, errors.Wrap(r.setStatus(cr, state, "cancelled by user"), "set status")
 	}
 
-	returnMsg := fmt.Sprintf(backupRestoredMsg, cr.Name, cr.Spec.PXCCluster, cr.Name)
+	status := cr.Status.DeepCopy()
+	state, err := r.reconcileState(cr)
+	if err != nil {
+		if k8serrors.IsConflict(errors.Cause(err)) {
+			// the cluster was changed by someone else (e.g. the main controller), just try again
+			return rr, nil
+		}
 
-	defer func() {
-		status := api.BcpRestoreStates(api.RestoreSucceeded)
-		if err != nil {
-			status = api.RestoreFailed
-			returnMsg = err.Error()
+		lgr.Error(err, "restore failed", "state", cr.Status.State)
+		if cr.Status.State != api.RestoreRollback && canRollback(cr) {
+			lgr.Info("rolling back the cluster")
+			return rr, errors.Wrap(r.setStatus(cr, api.RestoreRollback, err.Error()), "set status")
 		}
-		r.setStatus(cr, status, returnMsg)
-	}()
+		return rr, errors.Wrap(r.setStatus(cr, api.RestoreFailed, err.Error()), "set status")
+	}
 
-	for _, j := range rJobsList.Items {
-		if j.Spec.PXCCluster == cr.Spec.PXCCluster &&
-			j.Name != cr.Name && j.Status.State != api.RestoreFailed &&
-			j.Status.State != api.RestoreSucceeded {
-			err = errors.Errorf("unable to continue, concurent restore job %s running now.", j.Name)
-			return rr, err
+	if state == cr.Status.State {
+		// phase is in progress, save progress reported by jobs if any
+		if !reflect.DeepEqual(status, &cr.Status) {
+			err = r.writeStatus(cr)
+			if err != nil {
+				return rr, errors.Wrap(err, "write status")
+			}
 		}
+		return rr, nil
 	}
 
-	err = cr.CheckNsetDefaults()
-	if err != nil {
-		return rr, err
+	comments := ""
+	switch {
+	case state == api.RestoreSucceeded:
+		comments = fmt.Sprintf(backupRestoredMsg, cr.Name, cr.Spec.PXCCluster, cr.Name)
+		lgr.Info(comments)
+	case state.Finished():
+		// keep the reason of the rollback
+		comments = cr.Status.Comments
+		lgr.Info("restore is rolled back", "state", state)
+	default:
+		lgr.Info("restore phase", "state", state, "cluster", cr.Spec.PXCCluster, "backup", cr.Spec.BackupName)
 	}
-	bcp, err := r.getBackup(cr)
+
+	err = r.setStatus(cr, state, comments)
 	if err != nil {
-		return rr, errors.Wrap(err, "get backup")
+		return rr, errors.Wrap(err, "set status")
 	}
 
-	cluster := api.PerconaXtraDBCluster{}
-	err = r.client.Get(context.TODO(), types.NamespacedName{Name: cr.Spec.PXCCluster, Namespace: cr.Namespace}, &cluster)
-	if err != nil {
-		err = errors.Wrapf(err, "get cluster %s", cr.Spec.PXCCluster)
-		return rr, err
+	return rr, nil
+}
+
+func hasFinalizer(cr *api.PerconaXtraDBClusterRestore, finalizer string) bool {
+	for _, f := range cr.GetFinalizers() {
+		if f == finalizer {
+			return true
+		}
 	}
 
-	_, err = cluster.CheckNSetDefaults(r.serverVersion, r.log)
+	return false
+}
+
+// reconcileState runs the current phase of the restore and returns the state
+// the restore should be moved to. The same state is returned if the phase isn't finished yet.
+func (r *ReconcilePerconaXtraDBClusterRestore) reconcileState(cr *api.PerconaXtraDBClusterRestore) (api.BcpRestoreStates, error) {
+	err := cr.CheckNsetDefaults()
 	if err != nil {
-		return reconcile.Result{}, fmt.Errorf("wrong PXC options: %v", err)
+		return cr.Status.State, err
 	}
 
-	lgr.Info("stopping cluster", "cluster", cr.Spec.PXCCluster)
-	err = r.setStatus(cr, api.RestoreStopCluster, "")
+	cluster := &api.PerconaXtraDBCluster{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: cr.Spec.PXCCluster, Namespace: cr.Namespace}, cluster)
 	if err != nil {
-		err = errors.Wrap(err, "set status")
-		return rr, err
+		return cr.Status.State, errors.Wrapf(err, "get cluster %s", cr.Spec.PXCCluster)
 	}
-	err = r.stopCluster(cluster.DeepCopy())
+
+	// jobs are built from the spec with defaults, the cluster itself is
+	// changed only by pausing/resizing, so defaults never get into the CR
+	defaulted := cluster.DeepCopy()
+	_, err = defaulted.CheckNSetDefaults(r.serverVersion, r.log)
 	if err != nil {
-		err = errors.Wrapf(err, "stop cluster %s", cluster.Name)
-		return rr, err
+		return cr.Status.State, fmt.Errorf("wrong PXC options: %v", err)
 	}
 
-	lgr.Info("starting restore", "cluster", cr.Spec.PXCCluster, "backup", cr.Spec.BackupName)
-	err = r.setStatus(cr, api.RestoreRestore, "")
-	if err != nil {
-		err = errors.Wrap(err, "set status")
-		return rr, err
+	if cr.Status.State == api.RestoreRollback {
+		done, err := r.rollback(cr, cluster, defaulted.Spec)
+		if err != nil || !done {
+			return cr.Status.State, err
+		}
+		if cancelRequested(cr) {
+			return api.RestoreCancelled, nil
+		}
+		return api.RestoreFailed, nil
 	}
-	err = r.restore(cr, bcp, cluster.Spec)
+
+	bcp, err := r.getBackup(cr)
 	if err != nil {
-		err = errors.Wrap(err, "run restore")
-		return rr, err
+		return cr.Status.State, errors.Wrap(err, "get backup")
 	}
 
-	lgr.Info("starting cluster", "cluster", cr.Spec.PXCCluster)
-	err = r.setStatus(cr, api.RestoreStartCluster, "")
-	if err != nil {
-		err = errors.Wrap(err, "set status")
-		return rr, err
+	if cr.Status.State == api.RestoreNew || cr.Status.State == api.RestoreStarting {
+		return r.validate(cr, bcp, cluster)
 	}
 
-	if cr.Spec.PITR != nil {
-		oldSize := cluster.Spec.PXC.Size
-		oldUnsafe := cluster.Spec.AllowUnsafeConfig
-		cluster.Spec.PXC.Size = 1
-		cluster.Spec.AllowUnsafeConfig = true
+	// jobs run in the restore namespace, so they use copies of the backup secrets
+	useSourceCopies(cr, bcp)
 
-		if err := r.startCluster(&cluster); err != nil {
-			return rr, errors.Wrap(err, "restart cluster for pitr")
+	switch cr.Status.State {
+	case api.RestoreStopCluster:
+		return r.stopCluster(cr, cluster)
+	case api.RestoreRestore:
+		var done bool
+		switch {
+		case bcp.Status.Type == api.BackupTypeLogical:
+			done, err = r.restoreLogical(cr, bcp, defaulted)
+			err = errors.Wrap(err, "logical")
+		case cr.IsSelective():
+			done, err = r.restorePartial(cr, bcp, defaulted)
+		default:
+			done, err = r.restore(cr, bcp, defaulted.Spec)
 		}
-
-		lgr.Info("point-in-time recovering", "cluster", cr.Spec.PXCCluster)
-		err = r.setStatus(cr, api.RestorePITR, "")
+		if err != nil || !done {
+			return cr.Status.State, err
+		}
+		switch {
+		case bcp.Status.Type == api.BackupTypeLogical || cr.IsSelective():
+			return api.RestoreSucceeded, nil
+		case cr.Spec.PITR != nil:
+			return api.RestorePrepareCluster, nil
+		}
+		return api.RestoreStartCluster, nil
+	case api.RestorePrepareCluster:
+		err = r.applySourceUsers(cr, cluster)
 		if err != nil {
-			return rr, errors.Wrap(err, "set status")
+			return cr.Status.State, errors.Wrap(err, "apply users of the source cluster")
 		}
-
-		err = r.pitr(cr, bcp, cluster.Spec)
+		ready, err := r.startCluster(cr, cluster, 1, true)
+		if err != nil || !ready {
+			return cr.Status.State, err
+		}
+		return api.RestorePITR, nil
+	case api.RestorePITR:
+		done, err := r.pitr(cr, bcp, sourceUsersSpec(cr, bcp, defaulted.Spec))
+		if err != nil || !done {
+			return cr.Status.State, err
+		}
+		return api.RestoreStartCluster, nil
+	case api.RestoreStartCluster:
+		err = r.applySourceUsers(cr, cluster)
 		if err != nil {
-			return rr, errors.Wrap(err, "run pitr")
+			return cr.Status.State, errors.Wrap(err, "apply users of the source cluster")
 		}
+		ready, err := r.startCluster(cr, cluster, cr.Status.PXCSize, cr.Status.AllowUnsafeConfig)
+		if err != nil || !ready {
+			return cr.Status.State, err
+		}
+		return api.RestoreSucceeded, nil
+	}
 
-		cluster.Spec.PXC.Size = oldSize
-		cluster.Spec.AllowUnsafeConfig = oldUnsafe
+	return cr.Status.State, errors.Errorf("unknown restore state %q", cr.Status.State)
+}
+
+// validate checks if the restore can be started and chooses the first phase
+func (r *ReconcilePerconaXtraDBClusterRestore) validate(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClusterBackup,
+	cluster *api.PerconaXtraDBCluster) (api.BcpRestoreStates, error) {
+	rJobsList := &api.PerconaXtraDBClusterRestoreList{}
+	err := r.client.List(
+		context.TODO(),
+		rJobsList,
+		&client.ListOptions{
+			Namespace: cr.Namespace,
+		},
+	)
+	if err != nil {
+		return cr.Status.State, errors.Wrap(err, "get restore jobs list")
 	}
 
-	err = r.startCluster(&cluster)
+	// restores of the cluster created at the same time are started in the order of creation
+	for _, j := range rJobsList.Items {
+		if j.Spec.PXCCluster != cr.Spec.PXCCluster || j.Name == cr.Name || j.Status.State.Finished() {
+			continue
+		}
+		if j.Status.State != api.RestoreNew || j.CreationTimestamp.Before(&cr.CreationTimestamp) ||
+			(j.CreationTimestamp.Equal(&cr.CreationTimestamp) && j.Name < cr.Name) {
+			return cr.Status.State, errors.Errorf("unable to continue, concurent restore job %s running now.", j.Name)
+		}
+	}
+
+	cr.Status.BackupName = cr.Spec.BackupName
+	cr.Status.BackupDestination = bcp.Status.Destination
+
+	err = r.copySourceSecrets(cr, bcp)
 	if err != nil {
-		err = errors.Wrap(err, "restart cluster")
-		return rr, err
+		return cr.Status.State, errors.Wrap(err, "copy backup secrets")
 	}
 
-	lgr.Info(returnMsg)
+	if bcp.Status.Type == api.BackupTypeLogical {
+		if cr.Spec.PITR != nil {
+			return cr.Status.State, errors.New("point-in-time recovery isn't supported for logical backups")
+		}
+
+		// logical restore loads dumps into the running cluster, so there is no need to stop it
+		return api.RestoreRestore, nil
+	}
+
+	if cr.IsSelective() {
+		if cr.Spec.PITR != nil || strings.HasPrefix(bcp.Status.Destination, backup.SnapshotDestinationPrefix) {
+			return cr.Status.State, errors.New("restore of chosen databases or tables isn't supported for snapshot backups and PITR")
+		}
+
+		// tablespaces are imported into the running cluster
+		return api.RestoreRestore, nil
+	}
 
-	return rr, err
+	if cr.Spec.PITR != nil && strings.HasPrefix(bcp.Status.Destination, backup.SnapshotDestinationPrefix) {
+		return cr.Status.State, errors.New("point-in-time recovery isn't supported for snapshot backups")
+	}
+
+	err = checkPhysicalS3(cr, bcp)
+	if err != nil {
+		return cr.Status.State, err
+	}
+
+	if cluster.Spec.PXC != nil {
+		cr.Status.PXCSize = cluster.Spec.PXC.Size
+	}
+	cr.Status.AllowUnsafeConfig = cluster.Spec.AllowUnsafeConfig
+
+	return api.RestoreStopCluster, nil
 }
 
 func (r *ReconcilePerconaXtraDBClusterRestore) getBackup(cr *api.PerconaXtraDBClusterRestore) (*api.PerconaXtraDBClusterBackup, error) {
@@ -257,15 +403,22 @@ func (r *ReconcilePerconaXtraDBClusterRestore) getBackup(cr *api.PerconaXtraDBCl
 			},
 			Status: api.PXCBackupStatus{
 				State:       api.BackupSucceeded,
+				Type:        cr.Spec.BackupSource.Type,
 				Destination: cr.Spec.BackupSource.Destination,
+				LastGTIDSet: cr.Spec.BackupSource.LastGTIDSet,
 				StorageName: cr.Spec.BackupSource.StorageName,
 				S3:          cr.Spec.BackupSource.S3,
 			},
 		}, nil
 	}
 
+	ns := cr.Namespace
+	if cr.Spec.BackupNamespace != "" {
+		ns = cr.Spec.BackupNamespace
+	}
+
 	bcp := &api.PerconaXtraDBClusterBackup{}
-	err := r.client.Get(context.TODO(), types.NamespacedName{Name: cr.Spec.BackupName, Namespace: cr.Namespace}, bcp)
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: cr.Spec.BackupName, Namespace: ns}, bcp)
 	if err != nil {
 		err = errors.Wrapf(err, "get backup %s", cr.Spec.BackupName)
 		return bcp, err
@@ -284,26 +437,15 @@ If everything is fine, you can cleanup the job:
 $ kubectl delete pxc-restore/%s
 `
 
-func (r *ReconcilePerconaXtraDBClusterRestore) stopCluster(c *api.PerconaXtraDBCluster) error {
-	var gracePeriodSec int64
-
-	if c.Spec.PXC != nil && c.Spec.PXC.TerminationGracePeriodSeconds != nil {
-		gracePeriodSec = int64(c.Spec.PXC.Size) * *c.Spec.PXC.TerminationGracePeriodSeconds
-	}
-
-	c.Spec.Pause = true
-
-	err := r.client.Update(context.TODO(), c)
-	if err != nil {
-		return errors.Wrap(err, "shutdown pods")
+// stopCluster pauses the cluster and removes datadirs of all members but the first one.
+// It returns RestoreRestore as soon as the cluster is stopped.
+func (r *ReconcilePerconaXtraDBClusterRestore) stopCluster(cr *api.PerconaXtraDBClusterRestore, c *api.PerconaXtraDBCluster) (api.BcpRestoreStates, error) {
+	stopped, err := r.pauseCluster(cr, c)
+	if err != nil || !stopped {
+		return cr.Status.State, err
 	}
 
 	ls := statefulset.NewNode(c).Labels()
-	err = r.waitForPodsShutdown(ls, c.Namespace, gracePeriodSec)
-	if err != nil {
-		return errors.Wrap(err, "shutdown pods")
-	}
-
 	pvcs := corev1.PersistentVolumeClaimList{}
 	err = r.client.List(
 		context.TODO(),
@@ -314,141 +456,170 @@ func (r *ReconcilePerconaXtraDBClusterRestore) stopCluster(c *api.PerconaXtraDBC
 		},
 	)
 	if err != nil {
-		return errors.Wrap(err, "get pvc list")
+		return cr.Status.State, errors.Wrap(err, "get pvc list")
 	}
 
 	pxcNode := statefulset.NewNode(c)
 	pvcNameTemplate := statefulset.DataVolumeName + "-" + pxcNode.StatefulSet().Name
+
+	if cr.Spec.SafetyNet != nil && cr.Spec.SafetyNet.Enabled {
+		datadirs := []string{}
+		for _, pvc := range pvcs.Items {
+			if strings.HasPrefix(pvc.Name, pvcNameTemplate) && pvc.DeletionTimestamp == nil {
+				datadirs = append(datadirs, pvc.Name)
+			}
+		}
+		// datadirs are removed only after all of them are saved
+		saved, err := r.saveVolumes(cr, datadirs)
+		if err != nil || !saved {
+			return cr.Status.State, err
+		}
+	}
+
+	left := 0
 	for _, pvc := range pvcs.Items {
 		// check prefix just in case, to be sure we're not going to delete a wrong pvc
 		if pvc.Name == pvcNameTemplate+"-0" || !strings.HasPrefix(pvc.Name, pvcNameTemplate) {
 			continue
 		}
 
+		left++
+		if pvc.DeletionTimestamp != nil {
+			continue
+		}
 		err = r.client.Delete(context.TODO(), &pvc)
-		if err != nil {
-			return errors.Wrap(err, "delete pvc")
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return cr.Status.State, errors.Wrap(err, "delete pvc")
 		}
 	}
-
-	err = r.waitForPVCShutdown(ls, c.Namespace)
-	if err != nil {
-		return errors.Wrap(err, "shutdown pvc")
+	if left > 0 {
+		return cr.Status.State, nil
 	}
 
-	return nil
+	return api.RestoreRestore, nil
 }
 
-func (r *ReconcilePerconaXtraDBClusterRestore) startCluster(cr *api.PerconaXtraDBCluster) (err error) {
-	// tryin several times just to avoid possible conflicts with the main controller
-	for i := 0; i < 5; i++ {
-		// need to get the object with latest version of meta-data for update
-		current := &api.PerconaXtraDBCluster{}
-		err = r.client.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, current)
+// pauseCluster pauses the cluster and reports if all its pods are gone
+func (r *ReconcilePerconaXtraDBClusterRestore) pauseCluster(cr *api.PerconaXtraDBClusterRestore, c *api.PerconaXtraDBCluster) (bool, error) {
+	if !c.Spec.Pause {
+		c.Spec.Pause = true
+		err := r.client.Update(context.TODO(), c)
 		if err != nil {
-			return errors.Wrap(err, "get cluster")
+			return false, errors.Wrap(err, "shutdown pods")
 		}
-
-		current.Spec = cr.Spec
-
-		uerr := r.client.Update(context.TODO(), current)
-		if uerr == nil {
-			break
-		}
-		err = errors.Wrap(uerr, "update cluster")
-		time.Sleep(time.Second * 1)
-	}
-	if err != nil {
-		return err
+		return false, nil
 	}
 
-	// give time for process new state
-	time.Sleep(10 * time.Second)
-
-	var waitLimit int32 = 2 * 60 * 60 // 2 hours
-	if cr.Spec.PXC.LivenessInitialDelaySeconds != nil {
-		waitLimit = *cr.Spec.PXC.LivenessInitialDelaySeconds * cr.Spec.PXC.Size
+	var gracePeriodSec int64
+	if c.Spec.PXC != nil && c.Spec.PXC.TerminationGracePeriodSeconds != nil {
+		gracePeriodSec = int64(c.Spec.PXC.Size) * *c.Spec.PXC.TerminationGracePeriodSeconds
+	}
+	if phaseExpired(cr, time.Duration(waitLimitSec+gracePeriodSec)*time.Second) {
+		return false, errors.New("shutdown pods: exceeded wait limit")
 	}
 
-	for i := int32(0); i < waitLimit; i++ {
-		current := &api.PerconaXtraDBCluster{}
-		err = r.client.Get(context.TODO(), types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, current)
-		if err != nil {
-			return errors.Wrap(err, "get cluster")
-		}
-		if current.Status.ObservedGeneration == current.Generation && current.Status.PXC.Status == api.AppStateReady {
-			return nil
-		}
-		time.Sleep(time.Second * 1)
+	pods := corev1.PodList{}
+	err := r.client.List(
+		context.TODO(),
+		&pods,
+		&client.ListOptions{
+			Namespace:     c.Namespace,
+			LabelSelector: labels.SelectorFromSet(statefulset.NewNode(c).Labels()),
+		},
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "get pods list")
 	}
 
-	return errors.Errorf("exceeded wait limit")
+	return len(pods.Items) == 0, nil
 }
 
-const waitLimitSec int64 = 300
-
-func (r *ReconcilePerconaXtraDBClusterRestore) waitForPodsShutdown(ls map[string]string, namespace string, gracePeriodSec int64) error {
-	for i := int64(0); i < waitLimitSec+gracePeriodSec; i++ {
-		pods := corev1.PodList{}
-
-		err := r.client.List(
-			context.TODO(),
-			&pods,
-			&client.ListOptions{
-				Namespace:     namespace,
-				LabelSelector: labels.SelectorFromSet(ls),
-			},
-		)
+// startCluster resumes the cluster with the given size and reports if the cluster is ready
+func (r *ReconcilePerconaXtraDBClusterRestore) startCluster(cr *api.PerconaXtraDBClusterRestore, c *api.PerconaXtraDBCluster,
+	size int32, allowUnsafe bool) (bool, error) {
+	changed := false
+	if c.Spec.Pause {
+		c.Spec.Pause = false
+		changed = true
+	}
+	// the size is unknown for restores started by older operator versions, keep it as is
+	if size > 0 && c.Spec.PXC != nil && c.Spec.PXC.Size != size {
+		c.Spec.PXC.Size = size
+		changed = true
+	}
+	if c.Spec.AllowUnsafeConfig != allowUnsafe {
+		c.Spec.AllowUnsafeConfig = allowUnsafe
+		changed = true
+	}
+	if changed {
+		err := r.client.Update(context.TODO(), c)
 		if err != nil {
-			return errors.Wrap(err, "get pods list")
-		}
-
-		if len(pods.Items) == 0 {
-			return nil
+			return false, errors.Wrap(err, "update cluster")
 		}
+		return false, nil
+	}
 
-		time.Sleep(time.Second * 1)
+	waitLimit := 2 * time.Hour
+	if c.Spec.PXC != nil && c.Spec.PXC.LivenessInitialDelaySeconds != nil {
+		waitLimit = time.Duration(*c.Spec.PXC.LivenessInitialDelaySeconds*c.Spec.PXC.Size) * time.Second
+	}
+	if phaseExpired(cr, waitLimit) {
+		return false, errors.New("start cluster: exceeded wait limit")
 	}
 
-	return errors.Errorf("exceeded wait limit")
+	return c.Status.ObservedGeneration == c.Generation && c.Status.PXC.Status == api.AppStateReady, nil
 }
 
-func (r *ReconcilePerconaXtraDBClusterRestore) waitForPVCShutdown(ls map[string]string, namespace string) error {
-	for i := int64(0); i < waitLimitSec; i++ {
-		pvcs := corev1.PersistentVolumeClaimList{}
+const waitLimitSec int64 = 300
 
-		err := r.client.List(
-			context.TODO(),
-			&pvcs,
-			&client.ListOptions{
-				Namespace:     namespace,
-				LabelSelector: labels.SelectorFromSet(ls),
-			},
-		)
-		if err != nil {
-			return errors.Wrap(err, "get pvc list")
-		}
+// phaseExpired checks if the current phase of the restore takes longer than limit
+func phaseExpired(cr *api.PerconaXtraDBClusterRestore, limit time.Duration) bool {
+	started := cr.Status.PhaseStarted()
+	return started != nil && time.Since(started.Time) > limit
+}
 
-		if len(pvcs.Items) == 1 {
-			return nil
-		}
+func (r *ReconcilePerconaXtraDBClusterRestore) setStatus(cr *api.PerconaXtraDBClusterRestore, state api.BcpRestoreStates, comments string) error {
+	if state != cr.Status.State {
+		r.stateEvent(cr, state, comments)
+	}
 
-		time.Sleep(time.Second * 1)
+	now := metav1.NewTime(time.Now())
+	cr.Status.SetState(state, now)
+	switch state {
+	case api.RestoreSucceeded:
+		cr.Status.CompletedAt = &now
 	}
 
-	return errors.Errorf("exceeded wait limit")
+	cr.Status.Comments = comments
+
+	return r.writeStatus(cr)
 }
 
-func (r *ReconcilePerconaXtraDBClusterRestore) setStatus(cr *api.PerconaXtraDBClusterRestore, state api.BcpRestoreStates, comments string) error {
-	cr.Status.State = state
+// stateEvent emits the event on the transition of the restore to the new phase
+func (r *ReconcilePerconaXtraDBClusterRestore) stateEvent(cr *api.PerconaXtraDBClusterRestore, state api.BcpRestoreStates, comments string) {
+	msg := fmt.Sprintf("restore of %s to cluster %s: %s", cr.Spec.BackupName, cr.Spec.PXCCluster, state)
+	if cr.Spec.BackupName == "" {
+		msg = fmt.Sprintf("restore to cluster %s: %s", cr.Spec.PXCCluster, state)
+	}
+	if comments != "" {
+		msg += ": " + comments
+	}
+
 	switch state {
 	case api.RestoreSucceeded:
-		tm := metav1.NewTime(time.Now())
-		cr.Status.CompletedAt = &tm
+		r.recorder.Event(cr, corev1.EventTypeNormal, "RestoreSucceeded", msg)
+	case api.RestoreFailed:
+		r.recorder.Event(cr, corev1.EventTypeWarning, "RestoreFailed", msg)
+	case api.RestoreRollback:
+		r.recorder.Event(cr, corev1.EventTypeWarning, "RestoreRollback", msg)
+	case api.RestoreCancelled:
+		r.recorder.Event(cr, corev1.EventTypeNormal, "RestoreCancelled", msg)
+	default:
+		r.recorder.Event(cr, corev1.EventTypeNormal, "RestorePhase", msg)
 	}
+}
 
-	cr.Status.Comments = comments
-
+func (r *ReconcilePerconaXtraDBClusterRestore) writeStatus(cr *api.PerconaXtraDBClusterRestore) error {
 	err := r.client.Status().Update(context.TODO(), cr)
 	if err != nil {
 		// may be it's k8s v1.10 and erlier (e.g. oc3.9) that doesn't support status updates
@@ -0,0 +1,238 @@
+package pxcrestore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/k8s"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/statefulset"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/backup"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/queries"
+)
+
+// restorePartial restores chosen tables from the physical backup into the running cluster.
+// The export pod prepares the backup with --export, then the tablespaces are imported
+// on every member one table per reconcile. It reports if all tables are restored.
+func (r *ReconcilePerconaXtraDBClusterRestore) restorePartial(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClusterBackup,
+	cluster *api.PerconaXtraDBCluster) (bool, error) {
+	if cluster.Spec.Backup == nil {
+		return false, errors.New("undefined backup section in a cluster spec")
+	}
+
+	if cluster.CompareVersionWith("1.9.0") < 0 {
+		return false, errors.New("restore of chosen databases or tables requires crVersion 1.9.0 or newer")
+	}
+	initImage, err := k8s.InitImage(cluster, r.client)
+	if err != nil {
+		return false, err
+	}
+
+	svc := backup.ExportService(cr)
+	k8s.SetControllerReference(cr, svc, r.scheme)
+	pod, err := backup.ExportPod(cr, bcp, cluster.Spec, initImage)
+	if err != nil {
+		return false, errors.Wrap(err, "export pod")
+	}
+	k8s.SetControllerReference(cr, pod, r.scheme)
+
+	err = r.client.Create(context.TODO(), svc)
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return false, errors.Wrap(err, "create export service")
+	}
+
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, pod)
+	if k8serrors.IsNotFound(err) {
+		err = r.client.Create(context.TODO(), pod)
+		if err != nil {
+			return false, errors.Wrap(err, "create export pod")
+		}
+		cr.Status.Pods = appendUnique(cr.Status.Pods, pod.Name)
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "get export pod")
+	}
+	if pod.Status.Phase == corev1.PodFailed {
+		return false, errors.Errorf("export pod %s failed", pod.Name)
+	}
+	if !k8s.IsPodReady(pod) {
+		return false, nil
+	}
+
+	tables, err := r.exportedTables(pod)
+	if err != nil {
+		return false, err
+	}
+	if len(tables) == 0 {
+		return false, errors.New("no requested tables found in the backup")
+	}
+
+	for _, table := range tables {
+		if contains(cr.Status.ImportedTables, table) {
+			continue
+		}
+
+		err = r.importTable(cluster, pod, svc, table)
+		if err != nil {
+			return false, errors.Wrapf(err, "import %s", table)
+		}
+		r.logger(cr.Name, cr.Namespace).Info("table is restored", "table", table)
+		cr.Status.ImportedTables = append(cr.Status.ImportedTables, table)
+
+		// one table per reconcile, so the progress is saved in between
+		return false, nil
+	}
+
+	r.client.Delete(context.TODO(), svc)
+	r.client.Delete(context.TODO(), pod)
+
+	return true, nil
+}
+
+// exportedTables returns db.table names prepared by the export pod
+func (r *ReconcilePerconaXtraDBClusterRestore) exportedTables(pod *corev1.Pod) ([]string, error) {
+	var outb, errb bytes.Buffer
+	err := r.clientcmd.Exec(pod, "export", []string{"cat", backup.ExportTablesFile}, nil, &outb, &errb, false)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get exported tables: %s", strings.TrimSpace(errb.String()))
+	}
+
+	tables := []string{}
+	for _, t := range strings.Split(outb.String(), "\n") {
+		if t = strings.TrimSpace(t); t != "" {
+			tables = append(tables, t)
+		}
+	}
+
+	return tables, nil
+}
+
+// importTable replaces the tablespace of the table on every member
+// with the one streamed from the export pod. Tablespace operations aren't
+// replicated, so the table is locked for writes on all members during the import
+// and verified to be the same on all of them before it's unlocked.
+func (r *ReconcilePerconaXtraDBClusterRestore) importTable(cluster *api.PerconaXtraDBCluster, exportPod *corev1.Pod, svc *corev1.Service, table string) error {
+	pods := corev1.PodList{}
+	err := r.client.List(context.TODO(),
+		&pods,
+		&client.ListOptions{
+			Namespace:     cluster.Namespace,
+			LabelSelector: labels.SelectorFromSet(statefulset.NewNode(cluster).Labels()),
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "get pods list")
+	}
+	if len(pods.Items) == 0 {
+		return errors.New("no PXC pods")
+	}
+
+	for i := range pods.Items {
+		if !k8s.IsPodReady(&pods.Items[i]) {
+			return errors.Errorf("pod %s isn't ready, tablespace has to be imported on all members", pods.Items[i].Name)
+		}
+	}
+
+	spl := strings.SplitN(table, ".", 2)
+	// the export pod streams files of the current table only
+	var errb bytes.Buffer
+	selectCmd := fmt.Sprintf("echo '%s/%s' > %s", spl[0], spl[1], backup.ExportCurrentFile)
+	err = r.clientcmd.Exec(exportPod, "export", []string{"bash", "-c", selectCmd}, nil, nil, &errb, false)
+	if err != nil {
+		return errors.Wrapf(err, "select table in %s: %s", exportPod.Name, strings.TrimSpace(errb.String()))
+	}
+
+	// the table files and partitions files: <db>/<table>.ibd, <db>/<table>.cfg, <db>/<table>#p#<partition>.ibd
+	files := fmt.Sprintf("%s/%s[.#]*", spl[0], spl[1])
+	copyCmd := fmt.Sprintf("ncat --recv-only %s.%s %d | tar -xf - --wildcards -C /var/lib/mysql '%s'",
+		svc.Name, svc.Namespace, backup.ExportPort, files)
+
+	// the table has to exist and be locked on all members before any of them discards its tablespace
+	databases := make([]queries.Database, 0, len(pods.Items))
+	locks := make([]*queries.TableLock, 0, len(pods.Items))
+	defer func() {
+		for _, l := range locks {
+			l.Release()
+		}
+		for _, db := range databases {
+			db.Close()
+		}
+	}()
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		host := pod.Name + "." + cluster.Name + "-pxc." + cluster.Namespace
+		database, err := queries.New(r.client, cluster.Namespace, "internal-"+cluster.Name, "operator", host, 33062)
+		if err != nil {
+			return errors.Wrapf(err, "failed to access %s", pod.Name)
+		}
+		databases = append(databases, database)
+
+		lock, err := database.LockTableWrite(table)
+		if err != nil {
+			return errors.Wrapf(err, "member %s", pod.Name)
+		}
+		locks = append(locks, lock)
+
+		if _, err := lock.Checksum(); err != nil {
+			return errors.Wrapf(err, "member %s", pod.Name)
+		}
+	}
+
+	imported := []string{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		err = locks[i].ImportTablespace(func() error {
+			var errb bytes.Buffer
+			err := r.clientcmd.Exec(pod, "pxc", []string{"bash", "-c", copyCmd}, nil, nil, &errb, false)
+			if err != nil {
+				return errors.Wrapf(err, "exec in %s: %s", pod.Name, strings.TrimSpace(errb.String()))
+			}
+			return nil
+		})
+		if err != nil {
+			return errors.Wrapf(err, "member %s (tablespace is already imported on %v), %s", pod.Name, imported, repairHint(table))
+		}
+		imported = append(imported, pod.Name)
+	}
+
+	var sum int64
+	for i := range pods.Items {
+		s, err := locks[i].Checksum()
+		if err != nil {
+			return errors.Wrapf(err, "verify member %s, %s", pods.Items[i].Name, repairHint(table))
+		}
+		if i > 0 && s != sum {
+			return errors.Errorf("checksum of the table on %s differs from the one on %s, %s",
+				pods.Items[i].Name, pods.Items[0].Name, repairHint(table))
+		}
+		sum = s
+	}
+
+	return nil
+}
+
+// repairHint tells how to fix the table which is left inconsistent between members by a failed import
+func repairHint(table string) string {
+	return fmt.Sprintf("the table is inconsistent between members: drop %s, create it again with the same definition "+
+		"and restore it with a new restore", table)
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,139 @@
+package pxcrestore
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+)
+
+// Restore jobs report their progress with log lines of the following format:
+//
+//	Restore progress: downloaded=<bytes> total=<bytes>
+//	PITR progress: binlog=<name> applied=<n>/<total> gtid=<gtid set>
+//
+// The first one is reported by the logical restore script (build/restore-logical.sh),
+// the second one by the PITR recoverer (cmd/pitr). Physical restores run the scripts
+// of the backup image, they don't report the progress of the download, so the byte
+// progress is left empty for them.
+//
+// Only the last reported line of each kind matters.
+const (
+	restoreProgressPrefix = "Restore progress:"
+	pitrProgressPrefix    = "PITR progress:"
+)
+
+var progressLogLines int64 = 100
+
+// updateProgress records pods of the job and the progress they have reported into the restore status
+func (r *ReconcilePerconaXtraDBClusterRestore) updateProgress(cr *api.PerconaXtraDBClusterRestore, job *batchv1.Job) error {
+	pods := corev1.PodList{}
+	err := r.client.List(context.TODO(),
+		&pods,
+		&client.ListOptions{
+			Namespace:     job.Namespace,
+			LabelSelector: labels.SelectorFromSet(map[string]string{"job-name": job.Name}),
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "get job pods")
+	}
+	if len(pods.Items) == 0 {
+		return nil
+	}
+
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].CreationTimestamp.Before(&pods.Items[j].CreationTimestamp)
+	})
+	for _, pod := range pods.Items {
+		cr.Status.Pods = appendUnique(cr.Status.Pods, pod.Name)
+	}
+
+	// the last pod is the current attempt of the job
+	pod := pods.Items[len(pods.Items)-1]
+	if pod.Status.Phase == corev1.PodPending {
+		return nil
+	}
+
+	logs, err := r.clientcmd.PodLogs(pod.Namespace, pod.Name, &corev1.PodLogOptions{TailLines: &progressLogLines})
+	if err != nil {
+		return errors.Wrapf(err, "get logs of %s", pod.Name)
+	}
+
+	if cr.Status.Progress == nil {
+		cr.Status.Progress = &api.RestoreProgress{}
+	}
+	parseProgress(cr.Status.Progress, logs)
+
+	return nil
+}
+
+func parseProgress(p *api.RestoreProgress, logs []string) {
+	restoreSeen, pitrSeen := false, false
+	for i := len(logs) - 1; i >= 0 && !(restoreSeen && pitrSeen); i-- {
+		line := logs[i]
+		switch {
+		case !restoreSeen && strings.Contains(line, restoreProgressPrefix):
+			restoreSeen = true
+			for k, v := range progressFields(line, restoreProgressPrefix) {
+				n, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					continue
+				}
+				switch k {
+				case "downloaded":
+					p.DownloadedBytes = n
+				case "total":
+					p.TotalBytes = n
+				}
+			}
+		case !pitrSeen && strings.Contains(line, pitrProgressPrefix):
+			pitrSeen = true
+			for k, v := range progressFields(line, pitrProgressPrefix) {
+				switch k {
+				case "binlog":
+					p.Binlog = v
+				case "gtid":
+					p.GTIDSet = v
+				case "applied":
+					spl := strings.SplitN(v, "/", 2)
+					if len(spl) != 2 {
+						continue
+					}
+					p.BinlogsApplied, _ = strconv.Atoi(spl[0])
+					p.BinlogsTotal, _ = strconv.Atoi(spl[1])
+				}
+			}
+		}
+	}
+}
+
+// progressFields returns key=value pairs that follow the prefix in the line
+func progressFields(line, prefix string) map[string]string {
+	fields := make(map[string]string)
+	line = line[strings.Index(line, prefix)+len(prefix):]
+	for _, f := range strings.Fields(line) {
+		kv := strings.SplitN(f, "=", 2)
+		if len(kv) == 2 {
+			fields[kv[0]] = kv[1]
+		}
+	}
+
+	return fields
+}
+
+func appendUnique(list []string, v string) []string {
+	if contains(list, v) {
+		return list
+	}
+
+	return append(list, v)
+}
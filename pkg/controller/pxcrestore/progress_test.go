@@ -0,0 +1,66 @@
+package pxcrestore
+
+import (
+	"reflect"
+	"testing"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+)
+
+func TestParseProgress(t *testing.T) {
+	cases := []struct {
+		name     string
+		logs     []string
+		initial  api.RestoreProgress
+		expected api.RestoreProgress
+	}{
+		{
+			name:     "no progress",
+			logs:     []string{"+ mysql -uroot", "nothing to report"},
+			expected: api.RestoreProgress{},
+		},
+		{
+			name: "the last restore line wins",
+			logs: []string{
+				"Restore progress: downloaded=0 total=300",
+				"+ mysql -uroot db1",
+				"Restore progress: downloaded=100 total=300",
+				"+ mysql -uroot db2",
+			},
+			expected: api.RestoreProgress{DownloadedBytes: 100, TotalBytes: 300},
+		},
+		{
+			name: "lines with timestamps",
+			logs: []string{
+				"2021/03/04 05:06:07 PITR progress: binlog=binlog_1 applied=1/3 gtid=uuid:1-10",
+				"2021/03/04 05:06:08 PITR progress: binlog=binlog_2 applied=2/3 gtid=uuid:11-20",
+			},
+			expected: api.RestoreProgress{Binlog: "binlog_2", GTIDSet: "uuid:11-20", BinlogsApplied: 2, BinlogsTotal: 3},
+		},
+		{
+			name: "both kinds",
+			logs: []string{
+				"Restore progress: downloaded=300 total=300",
+				"PITR progress: binlog=binlog_1 applied=1/1 gtid=uuid:1-10",
+			},
+			expected: api.RestoreProgress{
+				DownloadedBytes: 300, TotalBytes: 300,
+				Binlog: "binlog_1", GTIDSet: "uuid:1-10", BinlogsApplied: 1, BinlogsTotal: 1,
+			},
+		},
+		{
+			name:     "malformed values are skipped",
+			logs:     []string{"Restore progress: downloaded=abc total=300", "PITR progress: applied=1 binlog=binlog_1"},
+			initial:  api.RestoreProgress{DownloadedBytes: 50, BinlogsApplied: 1, BinlogsTotal: 2},
+			expected: api.RestoreProgress{DownloadedBytes: 50, TotalBytes: 300, Binlog: "binlog_1", BinlogsApplied: 1, BinlogsTotal: 2},
+		},
+	}
+
+	for _, c := range cases {
+		p := c.initial
+		parseProgress(&p, c.logs)
+		if !reflect.DeepEqual(p, c.expected) {
+			t.Errorf("%s: expected %+v, got %+v", c.name, c.expected, p)
+		}
+	}
+}
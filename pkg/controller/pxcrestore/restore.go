@@ -2,12 +2,14 @@ package pxcrestore
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
@@ -15,101 +17,228 @@ import (
 	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/backup"
 )
 
-func (r *ReconcilePerconaXtraDBClusterRestore) restore(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClusterBackup, cluster api.PerconaXtraDBClusterSpec) error {
+// restore runs the restore of the backup data and reports if it's done
+func (r *ReconcilePerconaXtraDBClusterRestore) restore(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClusterBackup, cluster api.PerconaXtraDBClusterSpec) (bool, error) {
 	if cluster.Backup == nil {
-		return errors.New("undefined backup section in a cluster spec")
+		return false, errors.New("undefined backup section in a cluster spec")
 	}
 	if len(bcp.Status.Destination) > 6 {
 		switch {
 		case bcp.Status.Destination[:4] == "pvc/":
-			return errors.Wrap(r.restorePVC(cr, bcp, bcp.Status.Destination[4:], cluster), "pvc")
+			done, err := r.restorePVC(cr, bcp, bcp.Status.Destination[4:], cluster)
+			return done, errors.Wrap(err, "pvc")
 		case bcp.Status.Destination[:5] == "s3://":
-			return errors.Wrap(r.restoreS3(cr, bcp, bcp.Status.Destination[5:], cluster, false), "s3")
+			done, err := r.restoreS3(cr, bcp, bcp.Status.Destination[5:], cluster, false)
+			return done, errors.Wrap(err, "s3")
+		case strings.HasPrefix(bcp.Status.Destination, backup.SnapshotDestinationPrefix):
+			done, err := r.restoreSnapshot(cr, strings.TrimPrefix(bcp.Status.Destination, backup.SnapshotDestinationPrefix), cluster)
+			return done, errors.Wrap(err, "snapshot")
 		}
 	}
 
-	return errors.Errorf("unknown destination %s", bcp.Status.Destination)
+	return false, errors.Errorf("unknown destination %s", bcp.Status.Destination)
 }
 
-func (r *ReconcilePerconaXtraDBClusterRestore) pitr(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClusterBackup, cluster api.PerconaXtraDBClusterSpec) error {
-	return errors.Wrap(r.restoreS3(cr, bcp, bcp.Status.Destination[5:], cluster, true), "PITR restore")
+func (r *ReconcilePerconaXtraDBClusterRestore) pitr(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClusterBackup, cluster api.PerconaXtraDBClusterSpec) (bool, error) {
+	if !strings.HasPrefix(bcp.Status.Destination, "s3://") {
+		return false, errors.New("PITR restore: backup isn't stored on s3")
+	}
+	done, err := r.restoreS3(cr, bcp, bcp.Status.Destination[5:], cluster, true)
+	return done, errors.Wrap(err, "PITR restore")
 }
 
-func (r *ReconcilePerconaXtraDBClusterRestore) restorePVC(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClusterBackup, pvcName string, cluster api.PerconaXtraDBClusterSpec) error {
+func (r *ReconcilePerconaXtraDBClusterRestore) restorePVC(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClusterBackup, pvcName string, cluster api.PerconaXtraDBClusterSpec) (bool, error) {
 	svc := backup.PVCRestoreService(cr)
 	k8s.SetControllerReference(cr, svc, r.scheme)
 	pod, err := backup.PVCRestorePod(cr, bcp.Status.StorageName, pvcName, cluster)
 	if err != nil {
-		return errors.Wrap(err, "restore pod")
+		return false, errors.Wrap(err, "restore pod")
 	}
 	k8s.SetControllerReference(cr, pod, r.scheme)
 
 	job, err := backup.PVCRestoreJob(cr, cluster)
 	if err != nil {
-		return errors.Wrap(err, "restore job")
+		return false, errors.Wrap(err, "restore job")
 	}
 	k8s.SetControllerReference(cr, job, r.scheme)
 
-	r.client.Delete(context.TODO(), svc)
-	r.client.Delete(context.TODO(), pod)
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, &batchv1.Job{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return false, errors.Wrap(err, "get job")
+	}
+	if k8serrors.IsNotFound(err) {
+		// the joiner job streams the backup from the donor pod, so the pod has to be running first
+		running, err := r.ensureRestoreSource(svc, pod)
+		if err != nil || !running {
+			return false, err
+		}
+	}
+
+	done, err := r.ensureJob(cr, job)
+	if done || err != nil {
+		r.client.Delete(context.TODO(), svc)
+		r.client.Delete(context.TODO(), pod)
+	}
 
-	err = r.client.Create(context.TODO(), svc)
+	return done, err
+}
+
+// ensureRestoreSource creates the service and the pod serving the backup
+// from PVC and reports if the pod is running
+func (r *ReconcilePerconaXtraDBClusterRestore) ensureRestoreSource(svc *corev1.Service, pod *corev1.Pod) (bool, error) {
+	err := r.client.Create(context.TODO(), svc)
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return false, errors.Wrap(err, "create service")
+	}
+
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, pod)
+	if k8serrors.IsNotFound(err) {
+		err = r.client.Create(context.TODO(), pod)
+		if err != nil {
+			return false, errors.Wrap(err, "create pod")
+		}
+		return false, nil
+	}
 	if err != nil {
-		return errors.Wrap(err, "create service")
+		return false, errors.Wrap(err, "get pod status")
 	}
-	err = r.client.Create(context.TODO(), pod)
+
+	return pod.Status.Phase == corev1.PodRunning, nil
+}
+
+func (r *ReconcilePerconaXtraDBClusterRestore) restoreS3(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClusterBackup, s3dest string, cluster api.PerconaXtraDBClusterSpec, pitr bool) (bool, error) {
+	job, err := backup.S3RestoreJob(cr, bcp, s3dest, cluster, pitr)
 	if err != nil {
-		return errors.Wrap(err, "create pod")
+		return false, err
 	}
+	k8s.SetControllerReference(cr, job, r.scheme)
 
-	for {
-		time.Sleep(time.Second * 1)
+	return r.ensureJob(cr, job)
+}
 
-		err := r.client.Get(context.TODO(), types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, pod)
-		if err != nil {
-			return errors.Wrap(err, "get pod status")
+// restoreSnapshot replaces the datadir of the first member with the volume
+// provisioned from the snapshot. The rest of members join it with SST.
+func (r *ReconcilePerconaXtraDBClusterRestore) restoreSnapshot(cr *api.PerconaXtraDBClusterRestore, snapshotName string, cluster api.PerconaXtraDBClusterSpec) (bool, error) {
+	ready, err := r.ensurePVCFromSnapshot(cr, cluster, "datadir-"+cr.Spec.PXCCluster+"-pxc-0", snapshotName)
+	if err != nil || !ready {
+		return false, err
+	}
+
+	job := backup.SnapshotPrepareJob(cr, cluster)
+	k8s.SetControllerReference(cr, job, r.scheme)
+	return r.ensureJob(cr, job)
+}
+
+// ensurePVCFromSnapshot replaces the PVC with the one provisioned from the snapshot
+// and reports if the new PVC is there
+func (r *ReconcilePerconaXtraDBClusterRestore) ensurePVCFromSnapshot(cr *api.PerconaXtraDBClusterRestore, cluster api.PerconaXtraDBClusterSpec,
+	pvcName, snapshotName string) (bool, error) {
+	pvc, err := backup.SnapshotPVC(&api.PerconaXtraDBCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cr.Spec.PXCCluster,
+			Namespace: cr.Namespace,
+		},
+		Spec: cluster,
+	}, pvcName, snapshotName)
+	if err != nil {
+		return false, err
+	}
+
+	old := &corev1.PersistentVolumeClaim{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: pvc.Name, Namespace: pvc.Namespace}, old)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return false, errors.Wrap(err, "get datadir pvc")
+	}
+
+	if err == nil {
+		if old.Spec.DataSource != nil && old.Spec.DataSource.Name == snapshotName && old.DeletionTimestamp == nil {
+			return true, nil
 		}
-		if pod.Status.Phase == corev1.PodRunning {
-			break
+
+		// the old datadir has to be gone before the new one is created with the same name
+		if old.DeletionTimestamp == nil {
+			err = r.client.Delete(context.TODO(), old)
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return false, errors.Wrap(err, "delete datadir pvc")
+			}
+		}
+		if phaseExpired(cr, time.Duration(waitLimitSec)*time.Second) {
+			return false, errors.Errorf("datadir pvc %s wasn't deleted in %d seconds", pvc.Name, waitLimitSec)
 		}
+		return false, nil
 	}
 
-	defer func() {
-		r.client.Delete(context.TODO(), svc)
-		r.client.Delete(context.TODO(), pod)
-	}()
+	gvk, err := backup.VolumeSnapshotGVK(r.restMapper)
+	if err != nil {
+		return false, err
+	}
+	snap := backup.NewVolumeSnapshot(gvk)
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: snapshotName, Namespace: cr.Namespace}, snap)
+	if err != nil {
+		return false, errors.Wrapf(err, "get volume snapshot %s", snapshotName)
+	}
+	if !backup.GetSnapshotStatus(snap).ReadyToUse {
+		return false, errors.Errorf("volume snapshot %s isn't ready to use", snapshotName)
+	}
+
+	err = r.client.Create(context.TODO(), pvc)
+	if err != nil {
+		return false, errors.Wrapf(err, "create pvc %s from snapshot", pvc.Name)
+	}
 
-	return r.createJob(job)
+	return false, nil
 }
 
-func (r *ReconcilePerconaXtraDBClusterRestore) restoreS3(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClusterBackup, s3dest string, cluster api.PerconaXtraDBClusterSpec, pitr bool) error {
-	job, err := backup.S3RestoreJob(cr, bcp, s3dest, cluster, pitr)
+func (r *ReconcilePerconaXtraDBClusterRestore) restoreLogical(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClusterBackup, cluster *api.PerconaXtraDBCluster) (bool, error) {
+	if cluster.Spec.Backup == nil {
+		return false, errors.New("undefined backup section in a cluster spec")
+	}
+	if cluster.CompareVersionWith("1.9.0") < 0 {
+		return false, errors.New("logical restore requires crVersion 1.9.0 or newer")
+	}
+	initImage, err := k8s.InitImage(cluster, r.client)
+	if err != nil {
+		return false, err
+	}
+	job, err := backup.LogicalRestoreJob(cr, bcp, cluster.Spec, initImage)
 	if err != nil {
-		return err
+		return false, errors.Wrap(err, "restore job")
 	}
 	k8s.SetControllerReference(cr, job, r.scheme)
 
-	return r.createJob(job)
+	return r.ensureJob(cr, job)
 }
 
-func (r *ReconcilePerconaXtraDBClusterRestore) createJob(job *batchv1.Job) error {
-	err := r.client.Create(context.TODO(), job)
+// ensureJob creates the job if it doesn't exist yet and reports if it's completed
+func (r *ReconcilePerconaXtraDBClusterRestore) ensureJob(cr *api.PerconaXtraDBClusterRestore, job *batchv1.Job) (bool, error) {
+	cr.Status.Jobs = appendUnique(cr.Status.Jobs, job.Name)
+
+	checkJob := batchv1.Job{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, &checkJob)
+	if k8serrors.IsNotFound(err) {
+		err = r.client.Create(context.TODO(), job)
+		if err != nil && !k8serrors.IsAlreadyExists(err) {
+			return false, errors.Wrap(err, "create job")
+		}
+		return false, nil
+	}
 	if err != nil {
-		return errors.Wrap(err, "create job")
+		return false, errors.Wrap(err, "get job status")
 	}
 
-	for {
-		time.Sleep(time.Second * 1)
+	err = r.updateProgress(cr, &checkJob)
+	if err != nil {
+		r.logger(cr.Name, cr.Namespace).Info("failed to get restore progress", "job", job.Name, "error", err.Error())
+	}
 
-		checkJob := batchv1.Job{}
-		err := r.client.Get(context.TODO(), types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, &checkJob)
-		if err != nil && !k8serrors.IsNotFound(err) {
-			return errors.Wrap(err, "get job status")
+	for _, cond := range checkJob.Status.Conditions {
+		if cond.Type == batchv1.JobComplete && cond.Status == corev1.ConditionTrue {
+			return true, nil
 		}
-		for _, cond := range checkJob.Status.Conditions {
-			if cond.Type == batchv1.JobComplete && cond.Status == corev1.ConditionTrue {
-				return nil
-			}
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			return false, errors.Errorf("job %s failed: %s", job.Name, cond.Message)
 		}
 	}
+
+	return false, nil
 }
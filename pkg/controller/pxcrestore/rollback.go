@@ -0,0 +1,225 @@
+package pxcrestore
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/backup"
+)
+
+// cancelRequested checks if the user asked to stop the restore
+func cancelRequested(cr *api.PerconaXtraDBClusterRestore) bool {
+	return cr.Spec.Cancel || cr.DeletionTimestamp != nil
+}
+
+func passedPhase(cr *api.PerconaXtraDBClusterRestore, state api.BcpRestoreStates) bool {
+	for _, p := range cr.Status.Phases {
+		if p.State == state {
+			return true
+		}
+	}
+
+	return false
+}
+
+// canRollback checks if the cluster can be brought back to the state it had before the restore.
+// It's possible if the cluster was stopped by the restore and its data is either
+// still untouched or saved by the safety net.
+func canRollback(cr *api.PerconaXtraDBClusterRestore) bool {
+	if !passedPhase(cr, api.RestoreStopCluster) {
+		return false
+	}
+
+	return !passedPhase(cr, api.RestoreRestore) || len(cr.Status.SavedVolumes) > 0
+}
+
+// saveVolumes takes safety net snapshots of the given PVCs and reports if all of them are ready to use
+func (r *ReconcilePerconaXtraDBClusterRestore) saveVolumes(cr *api.PerconaXtraDBClusterRestore, pvcNames []string) (bool, error) {
+	gvk, err := backup.VolumeSnapshotGVK(r.restMapper)
+	if err != nil {
+		return false, err
+	}
+
+	ready := true
+	for _, name := range pvcNames {
+		snap := backup.SafetyNetSnapshot(gvk, cr, name, cr.Spec.SafetyNet.VolumeSnapshotClassName)
+
+		existing := backup.NewVolumeSnapshot(gvk)
+		err := r.client.Get(context.TODO(), types.NamespacedName{Name: snap.GetName(), Namespace: snap.GetNamespace()}, existing)
+		if k8serrors.IsNotFound(err) {
+			// snapshots aren't owned by the restore, so the garbage collector can't remove them
+			// before the rollback is done, they are removed by the finalizer (see finalize)
+			err = r.client.Create(context.TODO(), snap)
+			if err != nil {
+				return false, errors.Wrapf(err, "create safety net snapshot of %s", name)
+			}
+			cr.Status.SavedVolumes = appendUnique(cr.Status.SavedVolumes, name)
+			ready = false
+			continue
+		}
+		if err != nil {
+			return false, errors.Wrapf(err, "get safety net snapshot of %s", name)
+		}
+
+		cr.Status.SavedVolumes = appendUnique(cr.Status.SavedVolumes, name)
+		st := backup.GetSnapshotStatus(existing)
+		if st.ErrorMessage != "" {
+			return false, errors.Errorf("safety net snapshot of %s: %s", name, st.ErrorMessage)
+		}
+		if !st.ReadyToUse {
+			ready = false
+		}
+	}
+
+	return ready, nil
+}
+
+// rollback stops the restore and brings the cluster back to the state it had before
+// the restore. It reports if the rollback is finished.
+func (r *ReconcilePerconaXtraDBClusterRestore) rollback(cr *api.PerconaXtraDBClusterRestore, c *api.PerconaXtraDBCluster,
+	cluster api.PerconaXtraDBClusterSpec) (bool, error) {
+	err := r.deleteRestoreObjects(cr)
+	if err != nil {
+		return false, err
+	}
+
+	// logical restore doesn't stop the cluster, there is nothing to bring back
+	if !passedPhase(cr, api.RestoreStopCluster) {
+		return true, nil
+	}
+
+	if passedPhase(cr, api.RestoreRestore) {
+		if len(cr.Status.SavedVolumes) == 0 {
+			cr.Status.Comments += "\ncluster is left paused: datadir may be partially restored and there is no safety net to bring it back"
+			return true, nil
+		}
+
+		stopped, err := r.pauseCluster(cr, c)
+		if err != nil || !stopped {
+			return false, err
+		}
+
+		for _, pvc := range cr.Status.SavedVolumes {
+			ready, err := r.ensurePVCFromSnapshot(cr, cluster, pvc, backup.SafetyNetSnapshotName(cr, pvc))
+			if err != nil || !ready {
+				return false, errors.Wrapf(err, "bring back %s", pvc)
+			}
+		}
+	}
+
+	err = r.revertSourceUsers(cr, c)
+	if err != nil {
+		return false, errors.Wrap(err, "bring back users of the cluster")
+	}
+
+	return r.startCluster(cr, c, cr.Status.PXCSize, cr.Status.AllowUnsafeConfig)
+}
+
+// deleteRestoreObjects removes jobs and pods started by the restore
+func (r *ReconcilePerconaXtraDBClusterRestore) deleteRestoreObjects(cr *api.PerconaXtraDBClusterRestore) error {
+	for _, name := range cr.Status.Jobs {
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: cr.Namespace,
+			},
+		}
+		err := r.client.Delete(context.TODO(), job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return errors.Wrapf(err, "delete job %s", name)
+		}
+	}
+
+	// backup source pod of PVC restore and export pod of partial restore have the same names as their services
+	for _, svc := range []*corev1.Service{backup.PVCRestoreService(cr), backup.ExportService(cr)} {
+		err := r.client.Delete(context.TODO(), svc)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return errors.Wrapf(err, "delete service %s", svc.Name)
+		}
+
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      svc.Name,
+				Namespace: cr.Namespace,
+			},
+		}
+		err = r.client.Delete(context.TODO(), pod)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return errors.Wrapf(err, "delete pod %s", pod.Name)
+		}
+	}
+
+	return nil
+}
+
+// finalize removes the safety net snapshots and the rollback finalizer of the finished restore.
+// Snapshots of the successful restore are kept until the restore object is deleted,
+// the ones of the rolled back restore are removed right away.
+func (r *ReconcilePerconaXtraDBClusterRestore) finalize(cr *api.PerconaXtraDBClusterRestore) error {
+	if !hasFinalizer(cr, api.FinalizerRollbackRestore) {
+		return nil
+	}
+
+	if len(cr.Status.SavedVolumes) > 0 {
+		if cr.DeletionTimestamp == nil && !passedPhase(cr, api.RestoreRollback) {
+			return nil
+		}
+
+		err := r.deleteSafetyNet(cr)
+		if err != nil {
+			return err
+		}
+	}
+
+	return r.removeRollbackFinalizer(cr)
+}
+
+// deleteSafetyNet removes snapshots taken before the restore
+func (r *ReconcilePerconaXtraDBClusterRestore) deleteSafetyNet(cr *api.PerconaXtraDBClusterRestore) error {
+	gvk, err := backup.VolumeSnapshotGVK(r.restMapper)
+	if err != nil {
+		return err
+	}
+
+	snaps := backup.NewVolumeSnapshotList(gvk)
+	err = r.client.List(context.TODO(), snaps, &client.ListOptions{
+		Namespace:     cr.Namespace,
+		LabelSelector: labels.SelectorFromSet(backup.SafetyNetSnapshotLabels(cr)),
+	})
+	if err != nil {
+		return errors.Wrap(err, "get safety net snapshots")
+	}
+
+	for i := range snaps.Items {
+		err := r.client.Delete(context.TODO(), &snaps.Items[i])
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return errors.Wrapf(err, "delete safety net snapshot %s", snaps.Items[i].GetName())
+		}
+	}
+
+	return nil
+}
+
+func (r *ReconcilePerconaXtraDBClusterRestore) removeRollbackFinalizer(cr *api.PerconaXtraDBClusterRestore) error {
+	finalizers := []string{}
+	for _, f := range cr.GetFinalizers() {
+		if f != api.FinalizerRollbackRestore {
+			finalizers = append(finalizers, f)
+		}
+	}
+	if len(finalizers) == len(cr.GetFinalizers()) {
+		return nil
+	}
+
+	cr.SetFinalizers(finalizers)
+	return errors.Wrap(r.client.Update(context.TODO(), cr), "remove finalizer")
+}
@@ -0,0 +1,228 @@
+package pxcrestore
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/k8s"
+)
+
+// foreignBackup checks if the backup was taken from another cluster,
+// so the restored datadir comes with system users of that cluster
+func foreignBackup(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClusterBackup) bool {
+	return cr.Spec.SourceUsersSecret != "" || bcp.Namespace != cr.Namespace || bcp.Spec.PXCCluster != cr.Spec.PXCCluster
+}
+
+func s3SecretName(cr *api.PerconaXtraDBClusterRestore) string {
+	return "restore-s3-" + cr.Name + "-" + cr.Spec.PXCCluster
+}
+
+func sourceUsersSecretName(cr *api.PerconaXtraDBClusterRestore) string {
+	return "restore-users-" + cr.Name + "-" + cr.Spec.PXCCluster
+}
+
+// copySourceSecrets copies secrets of the backup that are needed in the restore
+// namespace: S3 credentials of the backup from another namespace and system
+// users of the cluster the backup was taken from if its datadir is restored.
+// Secrets are copied from another namespace only if they allow it, otherwise
+// the user provides them in the restore namespace.
+func (r *ReconcilePerconaXtraDBClusterRestore) copySourceSecrets(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClusterBackup) error {
+	if bcp.Namespace != cr.Namespace {
+		if !strings.HasPrefix(bcp.Status.Destination, "s3://") {
+			return errors.Errorf("backup %s/%s isn't stored on s3, only s3 backups can be restored into another namespace", bcp.Namespace, bcp.Name)
+		}
+		if bcp.Status.S3 == nil {
+			return errors.New("nil s3 backup status")
+		}
+
+		if copiesS3Credentials(cr, bcp) {
+			src := types.NamespacedName{Name: bcp.Status.S3.CredentialsSecret, Namespace: bcp.Namespace}
+			if cr.Spec.SourceS3CredentialsSecret != "" {
+				src = types.NamespacedName{Name: cr.Spec.SourceS3CredentialsSecret, Namespace: cr.Namespace}
+			}
+			err := r.copySecret(cr, src, s3SecretName(cr))
+			if err != nil {
+				return errors.Wrap(err, "copy s3 credentials, set sourceS3CredentialsSecret if the secret can't be copied")
+			}
+		}
+	}
+
+	if bcp.Status.Type == api.BackupTypeLogical || cr.IsSelective() {
+		// data is loaded into the running cluster, its users stay as they are
+		return nil
+	}
+	if !foreignBackup(cr, bcp) {
+		// the restored datadir has users of the same cluster
+		return nil
+	}
+
+	src := types.NamespacedName{Name: "internal-" + bcp.Spec.PXCCluster, Namespace: bcp.Namespace}
+	if cr.Spec.SourceUsersSecret != "" {
+		src = types.NamespacedName{Name: cr.Spec.SourceUsersSecret, Namespace: cr.Namespace}
+	}
+	err := r.copySecret(cr, src, sourceUsersSecretName(cr))
+	if err != nil {
+		return errors.Wrap(err, "copy system users of the source cluster, set sourceUsersSecret if the cluster is gone or the secret can't be copied")
+	}
+
+	return nil
+}
+
+// copyAllowed checks if the secret can be copied into the namespace
+func copyAllowed(secret *corev1.Secret, namespace string) bool {
+	if secret.Namespace == namespace {
+		return true
+	}
+
+	for _, ns := range strings.Split(secret.Annotations[api.AnnotationRestoreAllowedNamespaces], ",") {
+		if ns = strings.TrimSpace(ns); ns == namespace || ns == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// copySecret creates or updates the secret with the given name in the restore namespace
+// with data of the source secret. The copy is removed together with the restore.
+func (r *ReconcilePerconaXtraDBClusterRestore) copySecret(cr *api.PerconaXtraDBClusterRestore, from types.NamespacedName, name string) error {
+	src := &corev1.Secret{}
+	err := r.client.Get(context.TODO(), from, src)
+	if err != nil {
+		return errors.Wrapf(err, "get secret %s/%s", from.Namespace, from.Name)
+	}
+	if !copyAllowed(src, cr.Namespace) {
+		return errors.Errorf("secret %s/%s can't be copied into namespace %s without %s annotation",
+			from.Namespace, from.Name, cr.Namespace, api.AnnotationRestoreAllowedNamespaces)
+	}
+
+	dst := &corev1.Secret{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: cr.Namespace}, dst)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Wrapf(err, "get secret %s", name)
+	}
+	if k8serrors.IsNotFound(err) {
+		dst = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: cr.Namespace,
+			},
+			Type: src.Type,
+			Data: src.Data,
+		}
+		k8s.SetControllerReference(cr, dst, r.scheme)
+		return errors.Wrapf(r.client.Create(context.TODO(), dst), "create secret %s", name)
+	}
+
+	dst.Data = src.Data
+	return errors.Wrapf(r.client.Update(context.TODO(), dst), "update secret %s", name)
+}
+
+// copiesS3Credentials checks if S3 credentials of the backup from another namespace are copied
+// into the restore namespace. A backup taken without static keys, e.g. with the IAM role
+// of the pod, is restored the same way, so there is nothing to copy.
+func copiesS3Credentials(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClusterBackup) bool {
+	if bcp.Namespace == cr.Namespace || bcp.Status.S3 == nil {
+		return false
+	}
+
+	return bcp.Status.S3.CredentialsSecret != "" || cr.Spec.SourceS3CredentialsSecret != ""
+}
+
+// useSourceCopies points the backup to the copies of its secrets in the restore namespace
+func useSourceCopies(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClusterBackup) {
+	if !copiesS3Credentials(cr, bcp) {
+		return
+	}
+
+	s3 := *bcp.Status.S3
+	s3.CredentialsSecret = s3SecretName(cr)
+	bcp.Status.S3 = &s3
+}
+
+// checkPhysicalS3 checks if the physical backup on s3 can be restored with the credentials
+// the restore jobs get, the copy of the secret if the backup is in another namespace
+func checkPhysicalS3(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClusterBackup) error {
+	if bcp.Status.S3 == nil || !strings.HasPrefix(bcp.Status.Destination, "s3://") {
+		return nil
+	}
+
+	jobBackup := bcp.DeepCopy()
+	useSourceCopies(cr, jobBackup)
+
+	return jobBackup.Status.S3.CheckPhysical()
+}
+
+// applySourceUsers puts system users of the source cluster into the internal secret
+// of the target cluster, so PXC pods start with passwords stored in the restored datadir.
+// Once the cluster is ready, the main controller changes passwords to the ones from
+// the users secret of the target cluster as it does for any other password change.
+func (r *ReconcilePerconaXtraDBClusterRestore) applySourceUsers(cr *api.PerconaXtraDBClusterRestore, cluster *api.PerconaXtraDBCluster) error {
+	src := &corev1.Secret{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: sourceUsersSecretName(cr), Namespace: cr.Namespace}, src)
+	if k8serrors.IsNotFound(err) {
+		// the backup belongs to the same cluster
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "get source users secret")
+	}
+
+	return r.setInternalUsers(cluster, src.Data)
+}
+
+// revertSourceUsers brings back system users of the target cluster if the
+// restore put users of the source cluster into its internal secret
+func (r *ReconcilePerconaXtraDBClusterRestore) revertSourceUsers(cr *api.PerconaXtraDBClusterRestore, cluster *api.PerconaXtraDBCluster) error {
+	if !passedPhase(cr, api.RestorePrepareCluster) && !passedPhase(cr, api.RestoreStartCluster) {
+		return nil
+	}
+
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: sourceUsersSecretName(cr), Namespace: cr.Namespace}, &corev1.Secret{})
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "get source users secret")
+	}
+
+	users := &corev1.Secret{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: cluster.Spec.SecretsName, Namespace: cluster.Namespace}, users)
+	if err != nil {
+		return errors.Wrapf(err, "get users secret %s", cluster.Spec.SecretsName)
+	}
+
+	return r.setInternalUsers(cluster, users.Data)
+}
+
+func (r *ReconcilePerconaXtraDBClusterRestore) setInternalUsers(cluster *api.PerconaXtraDBCluster, data map[string][]byte) error {
+	internal := &corev1.Secret{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: "internal-" + cluster.Name, Namespace: cluster.Namespace}, internal)
+	if err != nil {
+		return errors.Wrap(err, "get internal users secret")
+	}
+	if reflect.DeepEqual(internal.Data, data) {
+		return nil
+	}
+
+	internal.Data = data
+	return errors.Wrap(r.client.Update(context.TODO(), internal), "update internal users secret")
+}
+
+// sourceUsersSpec returns the cluster spec for jobs that connect to the restored
+// datadir before the main controller changes passwords of the source cluster
+func sourceUsersSpec(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClusterBackup, cluster api.PerconaXtraDBClusterSpec) api.PerconaXtraDBClusterSpec {
+	if foreignBackup(cr, bcp) {
+		cluster.SecretsName = "internal-" + cr.Spec.PXCCluster
+	}
+
+	return cluster
+}
@@ -0,0 +1,146 @@
+package pxcrestore
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake" // nolint
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+)
+
+func TestCopyAllowed(t *testing.T) {
+	cases := []struct {
+		namespace  string
+		annotation *string
+		allowed    bool
+	}{
+		{"prod", nil, true},
+		{"staging", nil, false},
+		{"staging", strPtr(""), false},
+		{"staging", strPtr("dev,qa"), false},
+		{"staging", strPtr("dev, staging"), true},
+		{"staging", strPtr("*"), true},
+	}
+
+	for _, c := range cases {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "internal-cluster1", Namespace: "prod"}}
+		if c.annotation != nil {
+			secret.Annotations = map[string]string{api.AnnotationRestoreAllowedNamespaces: *c.annotation}
+		}
+
+		if allowed := copyAllowed(secret, c.namespace); allowed != c.allowed {
+			t.Errorf("namespace %s, annotation %v: expected %t, got %t", c.namespace, secret.Annotations, c.allowed, allowed)
+		}
+	}
+}
+
+func TestCopySourceS3Credentials(t *testing.T) {
+	cases := []struct {
+		name         string
+		backupSecret string
+		sourceSecret string
+		expected     string
+	}{
+		{"backup credentials", "s3-secret", "", "restore-s3-restore1-cluster1"},
+		{"source credentials", "", "my-s3-secret", "restore-s3-restore1-cluster1"},
+		{"without credentials", "", "", ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cr := &api.PerconaXtraDBClusterRestore{
+				ObjectMeta: metav1.ObjectMeta{Name: "restore1", Namespace: "staging"},
+				Spec: api.PerconaXtraDBClusterRestoreSpec{
+					PXCCluster:                "cluster1",
+					SourceS3CredentialsSecret: c.sourceSecret,
+				},
+			}
+			bcp := &api.PerconaXtraDBClusterBackup{
+				ObjectMeta: metav1.ObjectMeta{Name: "backup1", Namespace: "prod"},
+				Spec:       api.PXCBackupSpec{PXCCluster: "cluster1"},
+				Status: api.PXCBackupStatus{
+					Type:        api.BackupTypeLogical,
+					Destination: "s3://bucket/backup1",
+					S3:          &api.BackupStorageS3Spec{Bucket: "bucket", CredentialsSecret: c.backupSecret},
+				},
+			}
+			secrets := []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "s3-secret",
+						Namespace:   "prod",
+						Annotations: map[string]string{api.AnnotationRestoreAllowedNamespaces: "staging"},
+					},
+				},
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "my-s3-secret", Namespace: "staging"}},
+			}
+			s := scheme.Scheme
+			s.AddKnownTypes(api.SchemeGroupVersion, &api.PerconaXtraDBClusterRestore{})
+			r := &ReconcilePerconaXtraDBClusterRestore{client: fake.NewFakeClientWithScheme(s, secrets...), scheme: s, log: logf.NullLogger{}}
+
+			err := r.copySourceSecrets(cr, bcp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			useSourceCopies(cr, bcp)
+			if bcp.Status.S3.CredentialsSecret != c.expected {
+				t.Fatalf("expected credentials secret %q, got %q", c.expected, bcp.Status.S3.CredentialsSecret)
+			}
+			if c.expected != "" {
+				err = r.client.Get(context.TODO(), types.NamespacedName{Name: c.expected, Namespace: cr.Namespace}, &corev1.Secret{})
+				if err != nil {
+					t.Errorf("credentials aren't copied: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckPhysicalS3(t *testing.T) {
+	cases := []struct {
+		name         string
+		namespace    string
+		backupSecret string
+		sourceSecret string
+		valid        bool
+	}{
+		{"same namespace", "prod", "s3-secret", "", true},
+		{"same namespace without credentials", "prod", "", "", false},
+		{"another namespace", "staging", "s3-secret", "", true},
+		{"another namespace with source credentials", "staging", "", "my-s3-secret", true},
+		{"another namespace without credentials", "staging", "", "", false},
+	}
+
+	for _, c := range cases {
+		cr := &api.PerconaXtraDBClusterRestore{
+			ObjectMeta: metav1.ObjectMeta{Name: "restore1", Namespace: c.namespace},
+			Spec:       api.PerconaXtraDBClusterRestoreSpec{PXCCluster: "cluster1", SourceS3CredentialsSecret: c.sourceSecret},
+		}
+		bcp := &api.PerconaXtraDBClusterBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: "backup1", Namespace: "prod"},
+			Status: api.PXCBackupStatus{
+				Destination: "s3://bucket/backup1",
+				S3:          &api.BackupStorageS3Spec{Bucket: "bucket", CredentialsSecret: c.backupSecret},
+			},
+		}
+
+		err := checkPhysicalS3(cr, bcp)
+		if valid := err == nil; valid != c.valid {
+			t.Errorf("%s: expected valid %t, got %v", c.name, c.valid, err)
+		}
+		if bcp.Status.S3.CredentialsSecret != c.backupSecret {
+			t.Errorf("%s: backup status is changed", c.name)
+		}
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,57 @@
+package k8s
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+)
+
+// IsBackupRunning checks if there is a starting or running backup of the cluster
+func IsBackupRunning(cl client.Client, cr *api.PerconaXtraDBCluster) (bool, error) {
+	bcpList := api.PerconaXtraDBClusterBackupList{}
+	if err := cl.List(context.TODO(), &bcpList, &client.ListOptions{Namespace: cr.Namespace}); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "failed to get backup object")
+	}
+
+	for _, bcp := range bcpList.Items {
+		if bcp.Spec.PXCCluster != cr.Name {
+			continue
+		}
+
+		if bcp.Status.State == api.BackupRunning || bcp.Status.State == api.BackupStarting {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// IsRestoreRunning checks if there is a restore of the cluster which is started, but not finished
+func IsRestoreRunning(cl client.Client, clusterName, namespace string) (bool, error) {
+	restoreList := api.PerconaXtraDBClusterRestoreList{}
+
+	err := cl.List(context.TODO(), &restoreList, &client.ListOptions{
+		Namespace: namespace,
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get restore list")
+	}
+
+	for _, v := range restoreList.Items {
+		if v.Spec.PXCCluster != clusterName {
+			continue
+		}
+
+		if v.Status.State != api.RestoreNew && !v.Status.State.Finished() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -6,9 +6,13 @@ import (
 	"os"
 	"strings"
 
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/version"
 )
 
 func OperatorPod(cl client.Client) (corev1.Pod, error) {
@@ -30,3 +34,23 @@ func OperatorPod(cl client.Client) (corev1.Pod, error) {
 
 	return operatorPod, nil
 }
+
+// InitImage returns the image with the operator scripts for the cluster pods and jobs.
+// It's the operator image of the cluster version unless spec.initImage is set.
+func InitImage(cr *api.PerconaXtraDBCluster, cl client.Client) (string, error) {
+	if len(cr.Spec.InitImage) > 0 {
+		return cr.Spec.InitImage, nil
+	}
+
+	operatorPod, err := OperatorPod(cl)
+	if err != nil {
+		return "", errors.Wrap(err, "get operator deployment")
+	}
+
+	imageName := operatorPod.Spec.Containers[0].Image
+	if cr.CompareVersionWith(version.Version) != 0 {
+		imageName = strings.Split(imageName, ":")[0] + ":" + cr.Spec.CRVersion
+	}
+
+	return imageName, nil
+}
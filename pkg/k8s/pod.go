@@ -0,0 +1,23 @@
+package k8s
+
+import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// IsPodReady checks if all containers of the pod are ready
+func IsPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.ContainersReady && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PodOrderInSts returns the ordinal of the StatefulSet pod
+func PodOrderInSts(stsName string, podName string) (int, error) {
+	return strconv.Atoi(podName[len(stsName)+1:])
+}
@@ -0,0 +1,89 @@
+package k8s
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReplaceVolume makes one step of replacing the volume of the StatefulSet pod and reports
+// if the pod is ready with the new volume. The PVC with oldUID is deleted. The deleted PVC
+// is kept while the pod uses it, so the pod is deleted until the old volume is gone.
+// If newPVC is set, it's created as soon as the old volume is gone, otherwise the StatefulSet
+// creates the new PVC from the template together with the new pod. A PVC which doesn't match
+// newPVC, e.g. the one the StatefulSet created first, is replaced too.
+func ReplaceVolume(cl client.Client, namespace, podName, pvcName string, oldUID types.UID, newPVC *corev1.PersistentVolumeClaim) (bool, error) {
+	pod := &corev1.Pod{}
+	err := cl.Get(context.TODO(), types.NamespacedName{Name: podName, Namespace: namespace}, pod)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return false, errors.Wrap(err, "get pod")
+	}
+	podExists := err == nil
+
+	deletePod := func() error {
+		if !podExists || pod.DeletionTimestamp != nil {
+			return nil
+		}
+		err := cl.Delete(context.TODO(), pod)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return errors.Wrap(err, "delete pod")
+		}
+		return nil
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	err = cl.Get(context.TODO(), types.NamespacedName{Name: pvcName, Namespace: namespace}, pvc)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return false, errors.Wrapf(err, "get pvc %s", pvcName)
+	}
+
+	if k8serrors.IsNotFound(err) {
+		if newPVC == nil {
+			return false, deletePod()
+		}
+		err = cl.Create(context.TODO(), newPVC.DeepCopy())
+		if err != nil && !k8serrors.IsAlreadyExists(err) {
+			return false, errors.Wrapf(err, "create pvc %s", pvcName)
+		}
+		return false, deletePod()
+	}
+
+	if pvc.DeletionTimestamp != nil {
+		return false, deletePod()
+	}
+
+	if pvc.UID == oldUID || (newPVC != nil && !pvcMatches(pvc, newPVC)) {
+		err = cl.Delete(context.TODO(), pvc, &client.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &pvc.UID}})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return false, errors.Wrapf(err, "delete pvc %s", pvcName)
+		}
+		return false, deletePod()
+	}
+
+	return podExists && pod.DeletionTimestamp == nil && IsPodReady(pod), nil
+}
+
+// pvcMatches reports if the PVC has the storage class and at least the size of the wanted one
+func pvcMatches(pvc, want *corev1.PersistentVolumeClaim) bool {
+	if storageClass(pvc.Spec.StorageClassName) != storageClass(want.Spec.StorageClassName) {
+		return false
+	}
+	size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	wantSize := want.Spec.Resources.Requests[corev1.ResourceStorage]
+
+	return size.Cmp(wantSize) >= 0
+}
+
+// storageClass returns the name of the storage class, "" if it isn't set
+func storageClass(name *string) string {
+	if name == nil {
+		return ""
+	}
+
+	return *name
+}
@@ -0,0 +1,163 @@
+package deployment
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/statefulset"
+)
+
+const arbitratorSSLDir = "/etc/mysql/ssl-internal"
+
+// garbdCmd joins the cluster with TLS if the internal certificates are mounted,
+// PXC members encrypt the cluster traffic in this case
+var garbdCmd = `OPTS=""
+if [ -f ` + arbitratorSSLDir + `/tls.key -a -f ` + arbitratorSSLDir + `/tls.crt -a -f ` + arbitratorSSLDir + `/ca.crt ]; then
+	OPTS="socket.ssl=yes;socket.ssl_key=` + arbitratorSSLDir + `/tls.key;socket.ssl_cert=` + arbitratorSSLDir + `/tls.crt;socket.ssl_ca=` + arbitratorSSLDir + `/ca.crt"
+fi
+exec garbd --group "$CLUSTER_NAME" --address "gcomm://$CLUSTER_ADDRESS" --options "$OPTS"`
+
+func GetArbitratorDeployment(cr *api.PerconaXtraDBCluster) (appsv1.Deployment, error) {
+	spec := cr.Spec.Arbitrator
+	name := GetArbitratorDeploymentName(cr)
+
+	labels := map[string]string{
+		"app.kubernetes.io/name":       "percona-xtradb-cluster",
+		"app.kubernetes.io/instance":   cr.Name,
+		"app.kubernetes.io/component":  "arbitrator",
+		"app.kubernetes.io/managed-by": "percona-xtradb-cluster-operator",
+		"app.kubernetes.io/part-of":    "percona-xtradb-cluster",
+	}
+	podLabels := make(map[string]string, len(labels)+len(spec.Labels))
+	for k, v := range spec.Labels {
+		podLabels[k] = v
+	}
+	for k, v := range labels {
+		podLabels[k] = v
+	}
+
+	// the arbitrator connects to PXC members by their names in the StatefulSet service,
+	// the same way the members connect to each other
+	node := statefulset.NewNode(cr)
+	sfs := node.StatefulSet()
+	members := make([]string, 0, cr.Spec.PXC.Size)
+	for i := int32(0); i < cr.Spec.PXC.Size; i++ {
+		members = append(members, fmt.Sprintf("%s-%d.%s", sfs.Name, i, node.Service()))
+	}
+
+	res, err := app.CreateResources(spec.Resources)
+	if err != nil {
+		return appsv1.Deployment{}, errors.Wrap(err, "create resources")
+	}
+
+	container := corev1.Container{
+		Name:            "garbd",
+		Image:           spec.Image,
+		ImagePullPolicy: spec.ImagePullPolicy,
+		Command:         []string{"/bin/sh", "-c", garbdCmd},
+		Env: []corev1.EnvVar{
+			{
+				Name:  "CLUSTER_NAME",
+				Value: node.Service(),
+			},
+			{
+				Name:  "CLUSTER_ADDRESS",
+				Value: strings.Join(members, ","),
+			},
+		},
+		Ports: []corev1.ContainerPort{
+			{
+				ContainerPort: 4567,
+				Name:          "galera",
+			},
+		},
+		Resources:       res,
+		SecurityContext: spec.ContainerSecurityContext,
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "ssl-internal",
+				MountPath: arbitratorSSLDir,
+			},
+		},
+	}
+
+	var affinity *corev1.Affinity
+	if spec.Affinity != nil {
+		switch {
+		case spec.Affinity.Advanced != nil:
+			affinity = spec.Affinity.Advanced
+		case spec.Affinity.TopologyKey != nil && strings.ToLower(*spec.Affinity.TopologyKey) != api.AffinityTopologyKeyOff:
+			// the arbitrator keeps the quorum if a node or a zone with a PXC member fails,
+			// so it's placed away from PXC members
+			affinity = &corev1.Affinity{
+				PodAntiAffinity: &corev1.PodAntiAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
+						{
+							LabelSelector: &metav1.LabelSelector{
+								MatchLabels: node.Labels(),
+							},
+							TopologyKey: *spec.Affinity.TopologyKey,
+						},
+					},
+				},
+			}
+		}
+	}
+
+	replicas := int32(1)
+
+	return appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cr.Namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			// two arbitrators at once would change the number of votes
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RecreateDeploymentStrategyType,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      podLabels,
+					Annotations: spec.Annotations,
+				},
+				Spec: corev1.PodSpec{
+					Containers:                    []corev1.Container{container},
+					ImagePullSecrets:              spec.ImagePullSecrets,
+					ServiceAccountName:            spec.ServiceAccountName,
+					SecurityContext:               spec.PodSecurityContext,
+					Affinity:                      affinity,
+					Tolerations:                   spec.Tolerations,
+					NodeSelector:                  spec.NodeSelector,
+					SchedulerName:                 spec.SchedulerName,
+					PriorityClassName:             spec.PriorityClassName,
+					TerminationGracePeriodSeconds: spec.TerminationGracePeriodSeconds,
+					RuntimeClassName:              spec.RuntimeClassName,
+					Volumes: []corev1.Volume{
+						app.GetSecretVolumes("ssl-internal", cr.Spec.PXC.SSLInternalSecretName, true),
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func GetArbitratorDeploymentName(cr *api.PerconaXtraDBCluster) string {
+	return cr.Name + "-arbitrator"
+}
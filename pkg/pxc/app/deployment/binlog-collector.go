@@ -36,18 +36,6 @@ func GetBinlogCollectorDeployment(cr *api.PerconaXtraDBCluster) (appsv1.Deployme
 		labels[key] = value
 	}
 	envs := []corev1.EnvVar{
-		{
-			Name: "SECRET_ACCESS_KEY",
-			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: app.SecretKeySelector(storage.S3.CredentialsSecret, "AWS_SECRET_ACCESS_KEY"),
-			},
-		},
-		{
-			Name: "ACCESS_KEY_ID",
-			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: app.SecretKeySelector(storage.S3.CredentialsSecret, "AWS_ACCESS_KEY_ID"),
-			},
-		},
 		{
 			Name:  "S3_BUCKET_URL",
 			Value: storage.S3.Bucket,
@@ -79,6 +67,7 @@ func GetBinlogCollectorDeployment(cr *api.PerconaXtraDBCluster) (appsv1.Deployme
 			Value: strconv.FormatInt(bufferSize, 10),
 		},
 	}
+	envs = append(envs, app.S3CredentialsEnvs(storage.S3.CredentialsSecret, "")...)
 	if len(storage.S3.EndpointURL) > 0 {
 		envs = append(envs, corev1.EnvVar{
 			Name:  "ENDPOINT",
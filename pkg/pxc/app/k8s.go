@@ -20,3 +20,27 @@ func SecretKeySelector(name, key string) *corev1.SecretKeySelector {
 
 	return evs
 }
+
+// S3CredentialsEnvs returns <prefix>ACCESS_KEY_ID and <prefix>SECRET_ACCESS_KEY
+// envs taken from the given secret. If there is no secret, nothing is returned
+// and the default AWS credentials chain (IRSA, instance metadata) is used.
+func S3CredentialsEnvs(secretName, prefix string) []corev1.EnvVar {
+	if secretName == "" {
+		return nil
+	}
+
+	return []corev1.EnvVar{
+		{
+			Name: prefix + "ACCESS_KEY_ID",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: SecretKeySelector(secretName, "AWS_ACCESS_KEY_ID"),
+			},
+		},
+		{
+			Name: prefix + "SECRET_ACCESS_KEY",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: SecretKeySelector(secretName, "AWS_SECRET_ACCESS_KEY"),
+			},
+		},
+	}
+}
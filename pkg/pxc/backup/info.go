@@ -0,0 +1,114 @@
+package backup
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Info is a summary of the finished backup. Backup job reports it through
+// the termination message of the backup container (see build/backup-physical.sh
+// and build/backup-logical.sh). The message has the xtrabackup_info format
+// (one "key = value" pair per line) extended with a few keys set by the backup
+// script: backup_size (in bytes), donor (the pod the backup was taken from)
+// and galera_gtid (from sst_info). The operator sets backup_size of backups
+// stored on S3 itself.
+type Info map[string]string
+
+// ParseInfo parses the termination message of the backup container
+func ParseInfo(msg string) Info {
+	info := make(Info)
+	key := ""
+	for _, line := range strings.Split(msg, "\n") {
+		kv := strings.SplitN(line, " = ", 2)
+		if len(kv) != 2 {
+			// long values (e.g. GTID sets with many sources) are split into several lines
+			if key != "" && strings.TrimSpace(line) != "" {
+				info[key] += "\n" + strings.TrimSpace(line)
+			}
+			continue
+		}
+		key = strings.TrimSpace(kv[0])
+		info[key] = strings.TrimSpace(kv[1])
+	}
+
+	return info
+}
+
+func (i Info) XtrabackupVersion() string {
+	return i["tool_version"]
+}
+
+func (i Info) ServerVersion() string {
+	return i["server_version"]
+}
+
+func (i Info) LSN() string {
+	return i["innodb_to_lsn"]
+}
+
+func (i Info) Donor() string {
+	return i["donor"]
+}
+
+// Size returns human-readable size of the backup
+func (i Info) Size() string {
+	size, err := strconv.ParseInt(i["backup_size"], 10, 64)
+	if err != nil || size < 0 {
+		return ""
+	}
+
+	return HumanSize(size)
+}
+
+// HumanSize formats size in bytes using binary units, e.g. 1.2GiB
+func HumanSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f%ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// GTIDSet returns the "GTID of the last change" from binlog_pos
+func (i Info) GTIDSet() string {
+	const sep = "GTID of the last change"
+	pos := i["binlog_pos"]
+
+	idx := strings.Index(pos, sep)
+	if idx == -1 {
+		return ""
+	}
+
+	set := strings.Join(strings.Fields(pos[idx+len(sep):]), "")
+	return strings.Trim(set, "'")
+}
+
+// LastGTIDSet returns GTID set of the cluster (identified by galera_gtid)
+// the backup ends with. Point-in-time recovery starts right after it.
+func (i Info) LastGTIDSet() string {
+	set := i.GTIDSet()
+	if set == "" {
+		return ""
+	}
+
+	uuid := strings.Split(i["galera_gtid"], ":")[0]
+	if uuid == "" {
+		return set
+	}
+
+	for _, v := range strings.Split(set, ",") {
+		v = strings.TrimSpace(v)
+		if strings.HasPrefix(v, uuid+":") {
+			return v
+		}
+	}
+
+	return ""
+}
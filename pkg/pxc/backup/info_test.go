@@ -0,0 +1,54 @@
+package backup
+
+import "testing"
+
+func TestParseInfo(t *testing.T) {
+	msg := `uuid = 6d6b4ba8-7a2c-11eb-9d4c-0242ac110004
+tool_version = 8.0.22-15
+server_version = 8.0.22-13.1
+binlog_pos = filename 'binlog.000003', position '1565', GTID of the last change '5d8e0a6a-7a2c-11eb-8d6e-8b6e6d1f7a2d:1-25,
+0c3fd3aa-7a2c-11eb-b8e5-0242ac110004:1-7'
+innodb_to_lsn = 21053574
+backup_size = 1288490188
+donor = cluster1-pxc-2
+galera_gtid = 5d8e0a6a-7a2c-11eb-8d6e-8b6e6d1f7a2d:42
+`
+
+	info := ParseInfo(msg)
+
+	cases := []struct {
+		name string
+		have string
+		want string
+	}{
+		{"xtrabackup version", info.XtrabackupVersion(), "8.0.22-15"},
+		{"server version", info.ServerVersion(), "8.0.22-13.1"},
+		{"lsn", info.LSN(), "21053574"},
+		{"donor", info.Donor(), "cluster1-pxc-2"},
+		{"size", info.Size(), "1.2GiB"},
+		{"gtid set", info.GTIDSet(), "5d8e0a6a-7a2c-11eb-8d6e-8b6e6d1f7a2d:1-25,0c3fd3aa-7a2c-11eb-b8e5-0242ac110004:1-7"},
+		{"last gtid set", info.LastGTIDSet(), "5d8e0a6a-7a2c-11eb-8d6e-8b6e6d1f7a2d:1-25"},
+	}
+
+	for _, c := range cases {
+		if c.have != c.want {
+			t.Errorf("%s: want %q, have %q", c.name, c.want, c.have)
+		}
+	}
+}
+
+func TestInfoSize(t *testing.T) {
+	cases := map[string]string{
+		"":        "",
+		"wrong":   "",
+		"512":     "512B",
+		"2048":    "2.0KiB",
+		"5242880": "5.0MiB",
+	}
+
+	for in, want := range cases {
+		if have := (Info{"backup_size": in}).Size(); have != want {
+			t.Errorf("%q: want %q, have %q", in, want, have)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package backup
 import (
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -21,6 +22,9 @@ func (*Backup) Job(cr *api.PerconaXtraDBClusterBackup, cluster *api.PerconaXtraD
 		labels[key] = value
 	}
 	labels["type"] = "xtrabackup"
+	if cr.Spec.Type == api.BackupTypeLogical {
+		labels["type"] = string(api.BackupTypeLogical)
+	}
 	labels["cluster"] = cr.Spec.PXCCluster
 	labels["job-name"] = GenName63(cr)
 
@@ -38,15 +42,44 @@ func (*Backup) Job(cr *api.PerconaXtraDBClusterBackup, cluster *api.PerconaXtraD
 	}
 }
 
-func (bcp *Backup) JobSpec(spec api.PXCBackupSpec, cluster api.PerconaXtraDBClusterSpec, job *batchv1.Job) (batchv1.JobSpec, error) {
+// JobSpec returns spec of the backup job. The backup scripts of the operator are used
+// if initImage is set, otherwise the job runs backup.sh of the backup image.
+func (bcp *Backup) JobSpec(spec api.PXCBackupSpec, cluster api.PerconaXtraDBClusterSpec, job *batchv1.Job, initImage string) (batchv1.JobSpec, error) {
 	resources, err := app.CreateResources(cluster.Backup.Storages[spec.StorageName].Resources)
 	if err != nil {
 		return batchv1.JobSpec{}, fmt.Errorf("cannot parse Backup resources: %w", err)
 	}
 
+	command := []string{"bash", "/usr/bin/backup.sh"}
+	if initImage != "" {
+		// the wrapper runs backup.sh and reports the backup summary
+		command = script("backup-physical.sh")
+	}
+	envs := []corev1.EnvVar{
+		{
+			Name:  "BACKUP_DIR",
+			Value: "/backup",
+		},
+		{
+			Name:  "PXC_SERVICE",
+			Value: spec.PXCCluster + "-pxc",
+		},
+		{
+			Name: "PXC_PASS",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: app.SecretKeySelector(cluster.SecretsName, "xtrabackup"),
+			},
+		},
+	}
+
+	if spec.Type == api.BackupTypeLogical && spec.Logical != nil {
+		command = script("backup-logical.sh")
+		envs = logicalBackupEnvs(spec, cluster)
+	}
+
 	manualSelector := true
 	backbackoffLimit := int32(10)
-	return batchv1.JobSpec{
+	jobSpec := batchv1.JobSpec{
 		BackoffLimit:   &backbackoffLimit,
 		ManualSelector: &manualSelector,
 		Selector: &metav1.LabelSelector{
@@ -68,24 +101,9 @@ func (bcp *Backup) JobSpec(spec api.PXCBackupSpec, cluster api.PerconaXtraDBClus
 						Image:           bcp.image,
 						SecurityContext: cluster.Backup.Storages[spec.StorageName].ContainerSecurityContext,
 						ImagePullPolicy: bcp.imagePullPolicy,
-						Command:         []string{"bash", "/usr/bin/backup.sh"},
-						Env: []corev1.EnvVar{
-							{
-								Name:  "BACKUP_DIR",
-								Value: "/backup",
-							},
-							{
-								Name:  "PXC_SERVICE",
-								Value: spec.PXCCluster + "-pxc",
-							},
-							{
-								Name: "PXC_PASS",
-								ValueFrom: &corev1.EnvVarSource{
-									SecretKeyRef: app.SecretKeySelector(cluster.SecretsName, "xtrabackup"),
-								},
-							},
-						},
-						Resources: resources,
+						Command:         command,
+						Env:             envs,
+						Resources:       resources,
 					},
 				},
 				Affinity:          cluster.Backup.Storages[spec.StorageName].Affinity,
@@ -96,7 +114,50 @@ func (bcp *Backup) JobSpec(spec api.PXCBackupSpec, cluster api.PerconaXtraDBClus
 				RuntimeClassName:  cluster.Backup.Storages[spec.StorageName].RuntimeClassName,
 			},
 		},
-	}, nil
+	}
+	if initImage != "" {
+		AddScripts(&jobSpec.Template.Spec, initImage, bcp.imagePullPolicy)
+	}
+
+	return jobSpec, nil
+}
+
+// logicalBackupEnvs returns environment for the backup-logical.sh script.
+// The script dumps each schema and each table into a separate file, so
+// later any of them can be restored independently.
+func logicalBackupEnvs(spec api.PXCBackupSpec, cluster api.PerconaXtraDBClusterSpec) []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{
+			Name:  "BACKUP_DIR",
+			Value: "/backup",
+		},
+		{
+			Name:  "PXC_SERVICE",
+			Value: spec.PXCCluster + "-pxc",
+		},
+		{
+			Name:  "PXC_USER",
+			Value: "root",
+		},
+		{
+			Name: "PXC_PASS",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: app.SecretKeySelector(cluster.SecretsName, "root"),
+			},
+		},
+		{
+			Name:  "LOGICAL_BACKUP_TOOL",
+			Value: string(spec.Logical.Tool),
+		},
+		{
+			Name:  "BACKUP_DATABASES",
+			Value: strings.Join(spec.Logical.Databases, ","),
+		},
+		{
+			Name:  "BACKUP_THREADS",
+			Value: strconv.Itoa(int(spec.Logical.Threads)),
+		},
+	}
 }
 
 func appendStorageSecret(job *batchv1.JobSpec, cr *api.PerconaXtraDBCluster) error {
@@ -165,16 +226,11 @@ func (Backup) SetStoragePVC(job *batchv1.JobSpec, cr *api.PerconaXtraDBCluster,
 		return errors.New("no containers in job spec")
 	}
 
-	job.Template.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{
-		{
-			Name:      pvc.Name,
-			MountPath: "/backup",
-		},
-	}
-
-	job.Template.Spec.Volumes = []corev1.Volume{
-		pvc,
-	}
+	job.Template.Spec.Containers[0].VolumeMounts = append(job.Template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      pvc.Name,
+		MountPath: "/backup",
+	})
+	job.Template.Spec.Volumes = append(job.Template.Spec.Volumes, pvc)
 
 	err := appendStorageSecret(job, cr)
 	if err != nil {
@@ -185,18 +241,6 @@ func (Backup) SetStoragePVC(job *batchv1.JobSpec, cr *api.PerconaXtraDBCluster,
 }
 
 func (Backup) SetStorageS3(job *batchv1.JobSpec, cr *api.PerconaXtraDBCluster, s3 api.BackupStorageS3Spec, destination string) error {
-	accessKey := corev1.EnvVar{
-		Name: "ACCESS_KEY_ID",
-		ValueFrom: &corev1.EnvVarSource{
-			SecretKeyRef: app.SecretKeySelector(s3.CredentialsSecret, "AWS_ACCESS_KEY_ID"),
-		},
-	}
-	secretKey := corev1.EnvVar{
-		Name: "SECRET_ACCESS_KEY",
-		ValueFrom: &corev1.EnvVarSource{
-			SecretKeyRef: app.SecretKeySelector(s3.CredentialsSecret, "AWS_SECRET_ACCESS_KEY"),
-		},
-	}
 	region := corev1.EnvVar{
 		Name:  "DEFAULT_REGION",
 		Value: s3.Region,
@@ -209,7 +253,8 @@ func (Backup) SetStorageS3(job *batchv1.JobSpec, cr *api.PerconaXtraDBCluster, s
 	if len(job.Template.Spec.Containers) == 0 {
 		return errors.New("no containers in job spec")
 	}
-	job.Template.Spec.Containers[0].Env = append(job.Template.Spec.Containers[0].Env, accessKey, secretKey, region, endpoint)
+	job.Template.Spec.Containers[0].Env = append(job.Template.Spec.Containers[0].Env, app.S3CredentialsEnvs(s3.CredentialsSecret, "")...)
+	job.Template.Spec.Containers[0].Env = append(job.Template.Spec.Containers[0].Env, region, endpoint)
 
 	u, err := parseS3URL(destination)
 	if err != nil {
@@ -226,9 +271,6 @@ func (Backup) SetStorageS3(job *batchv1.JobSpec, cr *api.PerconaXtraDBCluster, s
 	job.Template.Spec.Containers[0].Env = append(job.Template.Spec.Containers[0].Env, bucket, bucketPath)
 
 	// add SSL volumes
-	job.Template.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{}
-	job.Template.Spec.Volumes = []corev1.Volume{}
-
 	err = appendStorageSecret(job, cr)
 	if err != nil {
 		return errors.Wrap(err, "failed to append storage secrets")
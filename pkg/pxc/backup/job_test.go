@@ -0,0 +1,82 @@
+package backup
+
+import (
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+)
+
+func envByName(envs []corev1.EnvVar, name string) *corev1.EnvVar {
+	for i := range envs {
+		if envs[i].Name == name {
+			return &envs[i]
+		}
+	}
+
+	return nil
+}
+
+func checkS3Credentials(t *testing.T, envs []corev1.EnvVar, secret string) {
+	t.Helper()
+
+	for _, name := range []string{"ACCESS_KEY_ID", "SECRET_ACCESS_KEY"} {
+		env := envByName(envs, name)
+		if secret == "" {
+			if env != nil {
+				t.Errorf("%s is set without credentials secret", name)
+			}
+			continue
+		}
+		if env == nil || env.ValueFrom == nil || env.ValueFrom.SecretKeyRef == nil {
+			t.Errorf("%s isn't taken from a secret", name)
+			continue
+		}
+		if env.ValueFrom.SecretKeyRef.Name != secret || env.ValueFrom.SecretKeyRef.Key != "AWS_"+name {
+			t.Errorf("%s is taken from %s/%s", name, env.ValueFrom.SecretKeyRef.Name, env.ValueFrom.SecretKeyRef.Key)
+		}
+	}
+}
+
+func TestSetStorageS3Credentials(t *testing.T) {
+	for _, secret := range []string{"my-cluster-name-backup-s3", ""} {
+		cr := &api.PerconaXtraDBCluster{
+			Spec: api.PerconaXtraDBClusterSpec{PXC: &api.PXCSpec{PodSpec: &api.PodSpec{}}},
+		}
+		job := &batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "xtrabackup"}}},
+			},
+		}
+		s3 := api.BackupStorageS3Spec{Bucket: "bucket", CredentialsSecret: secret, Region: "us-west-2"}
+
+		err := Backup{}.SetStorageS3(job, cr, s3, "s3://bucket/cluster1-2021-01-12")
+		if err != nil {
+			t.Fatal(err)
+		}
+		envs := job.Template.Spec.Containers[0].Env
+		checkS3Credentials(t, envs, secret)
+		if env := envByName(envs, "S3_BUCKET_PATH"); env == nil || env.Value != "cluster1-2021-01-12" {
+			t.Errorf("unexpected bucket path %v", env)
+		}
+	}
+}
+
+func TestBackupSourceS3Credentials(t *testing.T) {
+	for _, secret := range []string{"my-cluster-name-backup-s3", ""} {
+		bcp := &api.PerconaXtraDBClusterBackup{
+			Status: api.PXCBackupStatus{
+				Destination: "s3://bucket/cluster1-2021-01-12",
+				S3:          &api.BackupStorageS3Spec{Bucket: "bucket", CredentialsSecret: secret},
+			},
+		}
+
+		envs, _, _, err := backupSource(bcp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkS3Credentials(t, envs, secret)
+	}
+}
@@ -0,0 +1,159 @@
+package backup
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app"
+)
+
+const (
+	// ExportDir is where the export pod keeps the backup prepared with --export
+	ExportDir = "/export"
+	// ExportTablesFile lists db.table names exported by the export pod, one per line
+	ExportTablesFile = ExportDir + "/tables"
+	// ExportCurrentFile is the db/table the export pod streams tablespace files of
+	ExportCurrentFile = ExportDir + "/current"
+	// ExportPort is the port the export pod streams tar of the exported tables on
+	ExportPort = 3307
+)
+
+func exportName(cr *api.PerconaXtraDBClusterRestore) string {
+	return "restore-export-" + cr.Name + "-" + cr.Spec.PXCCluster
+}
+
+// ExportService returns the service of the export pod
+func ExportService(cr *api.PerconaXtraDBClusterRestore) *corev1.Service {
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      exportName(cr),
+			Namespace: cr.Namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{
+				"name": exportName(cr),
+			},
+			Type: corev1.ServiceTypeClusterIP,
+			Ports: []corev1.ServicePort{
+				{
+					Port: ExportPort,
+					Name: "ncat",
+				},
+			},
+		},
+	}
+}
+
+// ExportPod returns the scratch pod for the partial restore. It prepares the full
+// backup with --export and streams the requested tablespaces to PXC members.
+// The pod is ready once the backup is prepared (see build/restore-export.sh).
+func ExportPod(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClusterBackup, cluster api.PerconaXtraDBClusterSpec,
+	initImage string) (*corev1.Pod, error) {
+	storage, ok := cluster.Backup.Storages[bcp.Status.StorageName]
+	if !ok {
+		storage = &api.BackupStorageSpec{}
+	}
+
+	resources, err := app.CreateResources(storage.Resources)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse backup resources: %w", err)
+	}
+
+	envs := []corev1.EnvVar{
+		{
+			Name:  "EXPORT_DIR",
+			Value: ExportDir,
+		},
+		{
+			Name:  "RESTORE_DATABASES",
+			Value: strings.Join(cr.Spec.Databases, ","),
+		},
+		{
+			Name:  "RESTORE_TABLES",
+			Value: strings.Join(cr.Spec.Tables, ","),
+		},
+	}
+	srcEnvs, volumeMounts, volumes, err := backupSource(bcp)
+	if err != nil {
+		return nil, err
+	}
+	envs = append(envs, srcEnvs...)
+
+	volumeMounts = append(volumeMounts,
+		corev1.VolumeMount{
+			Name:      "export",
+			MountPath: ExportDir,
+		},
+		corev1.VolumeMount{
+			Name:      "vault-keyring-secret",
+			MountPath: "/etc/mysql/vault-keyring-secret",
+		},
+	)
+	volumes = append(volumes,
+		corev1.Volume{
+			Name: "export",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+		app.GetSecretVolumes("vault-keyring-secret", cluster.PXC.VaultSecretName, true),
+	)
+
+	labels := make(map[string]string)
+	for key, value := range storage.Labels {
+		labels[key] = value
+	}
+	labels["name"] = exportName(cr)
+
+	pod := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Pod",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        exportName(cr),
+			Namespace:   cr.Namespace,
+			Annotations: storage.Annotations,
+			Labels:      labels,
+		},
+		Spec: corev1.PodSpec{
+			ImagePullSecrets: cluster.Backup.ImagePullSecrets,
+			SecurityContext:  storage.PodSecurityContext,
+			Containers: []corev1.Container{
+				{
+					Name:            "export",
+					Image:           cluster.Backup.Image,
+					ImagePullPolicy: cluster.Backup.ImagePullPolicy,
+					Command:         script("restore-export.sh"),
+					SecurityContext: storage.ContainerSecurityContext,
+					VolumeMounts:    volumeMounts,
+					Env:             envs,
+					Resources:       resources,
+					ReadinessProbe: app.Probe(&corev1.Probe{
+						PeriodSeconds: 5,
+					}, "test", "-f", ExportTablesFile),
+				},
+			},
+			Volumes:            volumes,
+			RestartPolicy:      corev1.RestartPolicyNever,
+			NodeSelector:       storage.NodeSelector,
+			Affinity:           storage.Affinity,
+			Tolerations:        storage.Tolerations,
+			SchedulerName:      storage.SchedulerName,
+			PriorityClassName:  storage.PriorityClassName,
+			ServiceAccountName: cluster.Backup.ServiceAccountName,
+			RuntimeClassName:   storage.RuntimeClassName,
+		},
+	}
+	AddScripts(&pod.Spec, initImage, cluster.Backup.ImagePullPolicy)
+
+	return pod, nil
+}
@@ -278,28 +278,6 @@ func S3RestoreJob(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClu
 			Name:  "DEFAULT_REGION",
 			Value: bcp.Status.S3.Region,
 		},
-		{
-			Name: "ACCESS_KEY_ID",
-			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: &corev1.SecretKeySelector{
-					LocalObjectReference: corev1.LocalObjectReference{
-						Name: bcp.Status.S3.CredentialsSecret,
-					},
-					Key: "AWS_ACCESS_KEY_ID",
-				},
-			},
-		},
-		{
-			Name: "SECRET_ACCESS_KEY",
-			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: &corev1.SecretKeySelector{
-					LocalObjectReference: corev1.LocalObjectReference{
-						Name: bcp.Status.S3.CredentialsSecret,
-					},
-					Key: "AWS_SECRET_ACCESS_KEY",
-				},
-			},
-		},
 		{
 			Name:  "PXC_SERVICE",
 			Value: cr.Spec.PXCCluster + "-pxc",
@@ -315,6 +293,7 @@ func S3RestoreJob(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClu
 			},
 		},
 	}
+	envs = append(envs, app.S3CredentialsEnvs(bcp.Status.S3.CredentialsSecret, "")...)
 	jobName := "restore-job-" + cr.Name + "-" + cr.Spec.PXCCluster
 	volumeMounts := []corev1.VolumeMount{
 		{
@@ -358,28 +337,7 @@ func S3RestoreJob(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClu
 			Name:  "BINLOG_S3_REGION",
 			Value: storageS3.Region,
 		})
-		envs = append(envs, corev1.EnvVar{
-			Name: "BINLOG_ACCESS_KEY_ID",
-			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: &corev1.SecretKeySelector{
-					LocalObjectReference: corev1.LocalObjectReference{
-						Name: storageS3.CredentialsSecret,
-					},
-					Key: "AWS_ACCESS_KEY_ID",
-				},
-			},
-		})
-		envs = append(envs, corev1.EnvVar{
-			Name: "BINLOG_SECRET_ACCESS_KEY",
-			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: &corev1.SecretKeySelector{
-					LocalObjectReference: corev1.LocalObjectReference{
-						Name: storageS3.CredentialsSecret,
-					},
-					Key: "AWS_SECRET_ACCESS_KEY",
-				},
-			},
-		})
+		envs = append(envs, app.S3CredentialsEnvs(storageS3.CredentialsSecret, "BINLOG_")...)
 
 		envs = append(envs, corev1.EnvVar{
 			Name:  "PITR_RECOVERY_TYPE",
@@ -397,6 +355,12 @@ func S3RestoreJob(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClu
 			Name:  "PITR_DATE",
 			Value: cr.Spec.PITR.Date,
 		})
+		if bcp.Status.LastGTIDSet != "" {
+			envs = append(envs, corev1.EnvVar{
+				Name:  "PITR_START_GTID",
+				Value: bcp.Status.LastGTIDSet,
+			})
+		}
 		jobName = "pitr-job-" + cr.Name + "-" + cr.Spec.PXCCluster
 		volumeMounts = []corev1.VolumeMount{}
 		jobPVCs = []corev1.Volume{}
@@ -493,3 +457,148 @@ func xbMemoryUse(cluster api.PerconaXtraDBClusterSpec) (useMem string, k8sQuanti
 
 	return useMem, k8sQuantity, err
 }
+
+// LogicalRestoreJob returns a job that loads the chosen databases and tables
+// from a logical backup into the running cluster
+func LogicalRestoreJob(cr *api.PerconaXtraDBClusterRestore, bcp *api.PerconaXtraDBClusterBackup, cluster api.PerconaXtraDBClusterSpec, initImage string) (*batchv1.Job, error) {
+	storage, ok := cluster.Backup.Storages[bcp.Status.StorageName]
+	if !ok {
+		storage = &api.BackupStorageSpec{}
+	}
+
+	resources, err := app.CreateResources(storage.Resources)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse backup resources: %w", err)
+	}
+
+	envs := []corev1.EnvVar{
+		{
+			Name:  "PXC_SERVICE",
+			Value: cr.Spec.PXCCluster + "-pxc",
+		},
+		{
+			Name:  "PXC_USER",
+			Value: "root",
+		},
+		{
+			Name: "PXC_PASS",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: app.SecretKeySelector(cluster.SecretsName, "root"),
+			},
+		},
+		{
+			Name:  "RESTORE_DATABASES",
+			Value: strings.Join(cr.Spec.Databases, ","),
+		},
+		{
+			Name:  "RESTORE_TABLES",
+			Value: strings.Join(cr.Spec.Tables, ","),
+		},
+	}
+	srcEnvs, volumeMounts, volumes, err := backupSource(bcp)
+	if err != nil {
+		return nil, err
+	}
+	envs = append(envs, srcEnvs...)
+
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "restore-job-" + cr.Name + "-" + cr.Spec.PXCCluster,
+			Namespace: cr.Namespace,
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: storage.Annotations,
+					Labels:      storage.Labels,
+				},
+				Spec: corev1.PodSpec{
+					ImagePullSecrets: cluster.Backup.ImagePullSecrets,
+					SecurityContext:  storage.PodSecurityContext,
+					Containers: []corev1.Container{
+						{
+							Name:            "restore",
+							Image:           cluster.Backup.Image,
+							ImagePullPolicy: cluster.Backup.ImagePullPolicy,
+							Command:         script("restore-logical.sh"),
+							SecurityContext: storage.ContainerSecurityContext,
+							VolumeMounts:    volumeMounts,
+							Env:             envs,
+							Resources:       resources,
+						},
+					},
+					RestartPolicy:      corev1.RestartPolicyNever,
+					Volumes:            volumes,
+					NodeSelector:       storage.NodeSelector,
+					Affinity:           storage.Affinity,
+					Tolerations:        storage.Tolerations,
+					SchedulerName:      storage.SchedulerName,
+					PriorityClassName:  storage.PriorityClassName,
+					ServiceAccountName: cluster.Backup.ServiceAccountName,
+					RuntimeClassName:   storage.RuntimeClassName,
+				},
+			},
+			BackoffLimit: func(i int32) *int32 { return &i }(4),
+		},
+	}
+	AddScripts(&job.Spec.Template.Spec, initImage, cluster.Backup.ImagePullPolicy)
+
+	return job, nil
+}
+
+// backupSource returns envs and volumes that give access to the backup for restore scripts:
+// the backup is either downloaded from S3_BUCKET_URL or read from BACKUP_DIR
+func backupSource(bcp *api.PerconaXtraDBClusterBackup) ([]corev1.EnvVar, []corev1.VolumeMount, []corev1.Volume, error) {
+	switch {
+	case strings.HasPrefix(bcp.Status.Destination, "s3://"):
+		if bcp.Status.S3 == nil {
+			return nil, nil, nil, errors.New("nil s3 backup status")
+		}
+		envs := []corev1.EnvVar{
+			{
+				Name:  "S3_BUCKET_URL",
+				Value: strings.TrimPrefix(bcp.Status.Destination, "s3://"),
+			},
+			{
+				Name:  "ENDPOINT",
+				Value: bcp.Status.S3.EndpointURL,
+			},
+			{
+				Name:  "DEFAULT_REGION",
+				Value: bcp.Status.S3.Region,
+			},
+		}
+		envs = append(envs, app.S3CredentialsEnvs(bcp.Status.S3.CredentialsSecret, "")...)
+		return envs, []corev1.VolumeMount{}, []corev1.Volume{}, nil
+	case strings.HasPrefix(bcp.Status.Destination, "pvc/"):
+		envs := []corev1.EnvVar{
+			{
+				Name:  "BACKUP_DIR",
+				Value: "/backup",
+			},
+		}
+		mounts := []corev1.VolumeMount{
+			{
+				Name:      "backup",
+				MountPath: "/backup",
+			},
+		}
+		volumes := []corev1.Volume{
+			{
+				Name: "backup",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: strings.TrimPrefix(bcp.Status.Destination, "pvc/"),
+					},
+				},
+			},
+		}
+		return envs, mounts, volumes, nil
+	}
+
+	return nil, nil, nil, errors.Errorf("unknown destination %s", bcp.Status.Destination)
+}
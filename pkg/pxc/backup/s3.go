@@ -0,0 +1,89 @@
+package backup
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+)
+
+// NewS3Client returns minio client for the given storage.
+// Static credentials are taken from the storage secret. If there is no secret
+// the default AWS credentials chain is used, so the operator can rely on the identity
+// of its service account (IRSA web identity token) or on the instance metadata.
+func NewS3Client(cl client.Client, namespace string, s3 api.BackupStorageS3Spec) (*minio.Client, error) {
+	creds := credentials.NewChainCredentials([]credentials.Provider{
+		&credentials.EnvAWS{},
+		&credentials.FileAWSCredentials{},
+		&credentials.IAM{
+			Client: &http.Client{
+				Transport: http.DefaultTransport,
+			},
+		},
+	})
+
+	if s3.CredentialsSecret != "" {
+		sec := corev1.Secret{}
+		err := cl.Get(context.Background(),
+			types.NamespacedName{Name: s3.CredentialsSecret, Namespace: namespace}, &sec)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get secret")
+		}
+
+		creds = credentials.NewStaticV4(string(sec.Data["AWS_ACCESS_KEY_ID"]), string(sec.Data["AWS_SECRET_ACCESS_KEY"]), "")
+	}
+
+	secure := true
+	if strings.HasPrefix(s3.EndpointURL, "http://") {
+		secure = false
+	}
+
+	ep := s3.EndpointURL
+	if len(ep) == 0 {
+		ep = "s3.amazonaws.com"
+	}
+
+	ep = strings.TrimPrefix(ep, "https://")
+	ep = strings.TrimPrefix(ep, "http://")
+	ep = strings.TrimSuffix(ep, "/")
+
+	return minio.New(ep, &minio.Options{
+		Creds:  creds,
+		Secure: secure,
+		Region: s3.Region,
+	})
+}
+
+// S3BackupSize returns the size in bytes of the backup objects in the destination (s3://bucket/path),
+// sst_info of the physical backup is stored next to the backup
+func S3BackupSize(cli *minio.Client, destination string) (int64, error) {
+	spl := strings.SplitN(strings.TrimPrefix(destination, "s3://"), "/", 2)
+	if len(spl) != 2 {
+		return 0, errors.Errorf("wrong destination %s", destination)
+	}
+	bucket, path := spl[0], spl[1]
+
+	size := int64(0)
+	for _, prefix := range []string{path + "/", path + ".sst_info/"} {
+		objs := cli.ListObjects(context.Background(), bucket, minio.ListObjectsOptions{
+			Recursive: true,
+			Prefix:    prefix,
+		})
+		for obj := range objs {
+			if obj.Err != nil {
+				return 0, errors.Wrap(obj.Err, "list objects")
+			}
+			size += obj.Size
+		}
+	}
+
+	return size, nil
+}
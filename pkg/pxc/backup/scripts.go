@@ -0,0 +1,39 @@
+package backup
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	scriptsVolumeName = "bin"
+	scriptsDir        = "/opt/percona"
+)
+
+// AddScripts makes backup and restore scripts of the operator available in the first container
+// of the pod. They are shipped with the init image and copied by an init container.
+func AddScripts(spec *corev1.PodSpec, initImage string, pullPolicy corev1.PullPolicy) {
+	mount := corev1.VolumeMount{
+		Name:      scriptsVolumeName,
+		MountPath: scriptsDir,
+	}
+
+	spec.InitContainers = append(spec.InitContainers, corev1.Container{
+		Name:            "backup-init",
+		Image:           initImage,
+		ImagePullPolicy: pullPolicy,
+		Command:         []string{"/backup-init-entrypoint.sh"},
+		VolumeMounts:    []corev1.VolumeMount{mount},
+		SecurityContext: spec.Containers[0].SecurityContext,
+	})
+	spec.Containers[0].VolumeMounts = append(spec.Containers[0].VolumeMounts, mount)
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name: scriptsVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	})
+}
+
+func script(name string) []string {
+	return []string{"bash", scriptsDir + "/" + name}
+}
@@ -0,0 +1,205 @@
+package backup
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app"
+	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxc/app/statefulset"
+)
+
+const SnapshotDestinationPrefix = "snapshot/"
+
+var snapshotAPIGroup = "snapshot.storage.k8s.io"
+
+const volumeSnapshotKind = "VolumeSnapshot"
+
+// VolumeSnapshotGVK returns the kind of CSI snapshots served by the cluster: v1 or v1beta1
+// on the clusters with older external-snapshotter. The operator doesn't vendor
+// external-snapshotter types so snapshots are handled as unstructured objects.
+func VolumeSnapshotGVK(mapper meta.RESTMapper) (schema.GroupVersionKind, error) {
+	m, err := mapper.RESTMapping(schema.GroupKind{Group: snapshotAPIGroup, Kind: volumeSnapshotKind}, "v1", "v1beta1")
+	if err != nil {
+		return schema.GroupVersionKind{}, errors.Wrap(err, "VolumeSnapshot API isn't available")
+	}
+
+	return m.GroupVersionKind, nil
+}
+
+// NewVolumeSnapshot returns an empty VolumeSnapshot object to get snapshots into
+func NewVolumeSnapshot(gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	snap := &unstructured.Unstructured{}
+	snap.SetGroupVersionKind(gvk)
+	return snap
+}
+
+// VolumeSnapshot returns the snapshot of the given PVC for the backup
+func VolumeSnapshot(gvk schema.GroupVersionKind, cr *api.PerconaXtraDBClusterBackup, pvcName, className string) *unstructured.Unstructured {
+	return newVolumeSnapshot(gvk, GenName63(cr), cr.Namespace, pvcName, className, map[string]string{
+		"cluster": cr.Spec.PXCCluster,
+		"backup":  cr.Name,
+	})
+}
+
+// SafetyNetSnapshot returns the snapshot of the given PVC taken before the restore wipes it
+func SafetyNetSnapshot(gvk schema.GroupVersionKind, cr *api.PerconaXtraDBClusterRestore, pvcName, className string) *unstructured.Unstructured {
+	return newVolumeSnapshot(gvk, SafetyNetSnapshotName(cr, pvcName), cr.Namespace, pvcName, className, SafetyNetSnapshotLabels(cr))
+}
+
+// SafetyNetSnapshotLabels returns labels of the snapshots taken before the restore.
+// The snapshots aren't owned by the restore, they are found by the labels.
+func SafetyNetSnapshotLabels(cr *api.PerconaXtraDBClusterRestore) map[string]string {
+	return map[string]string{
+		"cluster": cr.Spec.PXCCluster,
+		"restore": cr.Name,
+	}
+}
+
+// NewVolumeSnapshotList returns an empty list to get snapshots into
+func NewVolumeSnapshotList(gvk schema.GroupVersionKind) *unstructured.UnstructuredList {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	return list
+}
+
+// SafetyNetSnapshotName returns the name of the snapshot of the given PVC taken before the restore
+func SafetyNetSnapshotName(cr *api.PerconaXtraDBClusterRestore, pvcName string) string {
+	return cr.Name + "-" + pvcName
+}
+
+func newVolumeSnapshot(gvk schema.GroupVersionKind, name, namespace, pvcName, className string, labels map[string]string) *unstructured.Unstructured {
+	snap := NewVolumeSnapshot(gvk)
+	snap.SetName(name)
+	snap.SetNamespace(namespace)
+	snap.SetLabels(labels)
+
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{
+			"persistentVolumeClaimName": pvcName,
+		},
+	}
+	if className != "" {
+		spec["volumeSnapshotClassName"] = className
+	}
+	snap.Object["spec"] = spec
+
+	return snap
+}
+
+// SnapshotStatus is a part of the VolumeSnapshot status the operator relies on
+type SnapshotStatus struct {
+	Created      bool
+	ReadyToUse   bool
+	RestoreSize  string
+	ErrorMessage string
+}
+
+// GetSnapshotStatus extracts status of the VolumeSnapshot
+func GetSnapshotStatus(snap *unstructured.Unstructured) SnapshotStatus {
+	st := SnapshotStatus{}
+	st.ReadyToUse, _, _ = unstructured.NestedBool(snap.Object, "status", "readyToUse")
+	st.RestoreSize, _, _ = unstructured.NestedString(snap.Object, "status", "restoreSize")
+	st.ErrorMessage, _, _ = unstructured.NestedString(snap.Object, "status", "error", "message")
+	created, _, _ := unstructured.NestedString(snap.Object, "status", "creationTime")
+	st.Created = created != ""
+
+	return st
+}
+
+// SnapshotPVC returns the datadir PVC of the PXC pod provisioned from the snapshot
+func SnapshotPVC(cluster *api.PerconaXtraDBCluster, pvcName, snapshotName string) (*corev1.PersistentVolumeClaim, error) {
+	if cluster.Spec.PXC == nil || cluster.Spec.PXC.VolumeSpec == nil || cluster.Spec.PXC.VolumeSpec.PersistentVolumeClaim == nil {
+		return nil, fmt.Errorf("snapshot restore requires persistent volume claim for PXC")
+	}
+
+	node := statefulset.NewNode(cluster)
+	pvc := &corev1.PersistentVolumeClaim{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "PersistentVolumeClaim",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pvcName,
+			Namespace: cluster.Namespace,
+			Labels:    node.Labels(),
+		},
+		Spec: app.VolumeSpec(cluster.Spec.PXC.VolumeSpec),
+	}
+	pvc.Spec.DataSource = &corev1.TypedLocalObjectReference{
+		APIGroup: &snapshotAPIGroup,
+		Kind:     volumeSnapshotKind,
+		Name:     snapshotName,
+	}
+
+	return pvc, nil
+}
+
+// SnapshotPrepareJob returns the job that makes the datadir restored from
+// the snapshot of a running member bootstrappable
+func SnapshotPrepareJob(cr *api.PerconaXtraDBClusterRestore, cluster api.PerconaXtraDBClusterSpec) *batchv1.Job {
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "restore-job-" + cr.Name + "-" + cr.Spec.PXCCluster,
+			Namespace: cr.Namespace,
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: cluster.PXC.Annotations,
+					Labels:      cluster.PXC.Labels,
+				},
+				Spec: corev1.PodSpec{
+					ImagePullSecrets: cluster.Backup.ImagePullSecrets,
+					SecurityContext:  cluster.PXC.PodSecurityContext,
+					Containers: []corev1.Container{
+						{
+							Name:            "prepare",
+							Image:           cluster.Backup.Image,
+							ImagePullPolicy: cluster.Backup.ImagePullPolicy,
+							Command: []string{"bash", "-c",
+								"sed -i 's/^safe_to_bootstrap:.*/safe_to_bootstrap: 1/' /datadir/grastate.dat && rm -f /datadir/gvwstate.dat"},
+							SecurityContext: cluster.PXC.ContainerSecurityContext,
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "datadir",
+									MountPath: "/datadir",
+								},
+							},
+						},
+					},
+					RestartPolicy: corev1.RestartPolicyNever,
+					Volumes: []corev1.Volume{
+						{
+							Name: "datadir",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: "datadir-" + cr.Spec.PXCCluster + "-pxc-0",
+								},
+							},
+						},
+					},
+					NodeSelector:       cluster.PXC.NodeSelector,
+					Affinity:           cluster.PXC.Affinity.Advanced,
+					Tolerations:        cluster.PXC.Tolerations,
+					SchedulerName:      cluster.PXC.SchedulerName,
+					PriorityClassName:  cluster.PXC.PriorityClassName,
+					ServiceAccountName: cluster.PXC.ServiceAccountName,
+					RuntimeClassName:   cluster.PXC.RuntimeClassName,
+				},
+			},
+			BackoffLimit: func(i int32) *int32 { return &i }(4),
+		},
+	}
+}
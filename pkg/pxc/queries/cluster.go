@@ -0,0 +1,93 @@
+package queries
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+)
+
+// NewProxy connects to the admin interface of ProxySQL or to PXC through HAProxy,
+// whichever is enabled in the cluster
+func NewProxy(cl client.Client, cr *api.PerconaXtraDBCluster) (Database, error) {
+	var database Database
+	var user, host string
+	var port, proxySize int32
+
+	if cr.Spec.ProxySQL != nil && cr.Spec.ProxySQL.Enabled {
+		user = "proxyadmin"
+		host = fmt.Sprintf("%s-proxysql-unready.%s", cr.ObjectMeta.Name, cr.Namespace)
+		proxySize = cr.Spec.ProxySQL.Size
+		port = 6032
+	} else if cr.Spec.HAProxy != nil && cr.Spec.HAProxy.Enabled {
+		user = "monitor"
+		host = fmt.Sprintf("%s-haproxy.%s", cr.Name, cr.Namespace)
+		proxySize = cr.Spec.HAProxy.Size
+
+		hasKey, err := cr.ConfigHasKey("mysqld", "proxy_protocol_networks")
+		if err != nil {
+			return database, errors.Wrap(err, "check if config has proxy_protocol_networks key")
+		}
+
+		if hasKey && cr.CompareVersionWith("1.6.0") >= 0 {
+			port = 33062
+		} else {
+			port = 3306
+		}
+	} else {
+		return database, errors.New("can't detect enabled proxy, please enable HAProxy or ProxySQL")
+	}
+	secrets := cr.Spec.SecretsName
+	if cr.CompareVersionWith("1.6.0") >= 0 {
+		secrets = "internal-" + cr.Name
+	}
+	for i := 0; ; i++ {
+		db, err := New(cl, cr.Namespace, secrets, user, host, port)
+		if err != nil && i < int(proxySize) {
+			time.Sleep(time.Second)
+		} else if err != nil && i == int(proxySize) {
+			return database, err
+		} else {
+			database = db
+			break
+		}
+	}
+
+	return database, nil
+}
+
+// PrimaryPod asks the proxy which member is the writer
+func PrimaryPod(cl client.Client, cr *api.PerconaXtraDBCluster) (string, error) {
+	database, err := NewProxy(cl, cr)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get proxySQL db")
+	}
+
+	defer database.Close()
+
+	if cr.Spec.HAProxy != nil && cr.Spec.HAProxy.Enabled {
+		host, err := database.Hostname()
+		if err != nil {
+			return "", err
+		}
+
+		return host, nil
+	}
+
+	return database.PrimaryHost()
+}
+
+// MemberWsrepStatus returns the wsrep status of the PXC member
+func MemberWsrepStatus(cl client.Client, cr *api.PerconaXtraDBCluster, podName string) (WsrepStatus, error) {
+	database, err := New(cl, cr.Namespace, "internal-"+cr.Name, "operator", podName+"."+cr.Name+"-pxc."+cr.Namespace, 33062)
+	if err != nil {
+		return WsrepStatus{}, errors.Wrap(err, "failed to access PXC database")
+	}
+	defer database.Close()
+
+	st, err := database.WsrepStatus()
+	return st, errors.Wrap(err, "get wsrep status")
+}
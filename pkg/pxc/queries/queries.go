@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
 	_ "github.com/go-sql-driver/mysql"
 	corev1 "k8s.io/api/core/v1"
@@ -117,6 +119,55 @@ func (p *Database) WsrepLocalStateComment() (string, error) {
 	return value, nil
 }
 
+// WsrepStatus is the Galera status of the node
+type WsrepStatus struct {
+	LocalStateComment string
+	ClusterStatus     string
+	LastCommitted     int64
+	FlowControlPaused string
+}
+
+func (p *Database) WsrepStatus() (WsrepStatus, error) {
+	st := WsrepStatus{}
+
+	rows, err := p.db.Query("SHOW GLOBAL STATUS WHERE Variable_name IN " +
+		"('wsrep_local_state_comment', 'wsrep_cluster_status', 'wsrep_last_committed', 'wsrep_flow_control_paused')")
+	if err != nil {
+		return st, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, value string
+		err := rows.Scan(&name, &value)
+		if err != nil {
+			return st, err
+		}
+
+		switch name {
+		case "wsrep_local_state_comment":
+			st.LocalStateComment = value
+		case "wsrep_cluster_status":
+			st.ClusterStatus = value
+		case "wsrep_last_committed":
+			st.LastCommitted, err = strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return st, fmt.Errorf("parse wsrep_last_committed: %v", err)
+			}
+		case "wsrep_flow_control_paused":
+			st.FlowControlPaused = value
+		}
+	}
+
+	return st, rows.Err()
+}
+
+// BootstrapPrimaryComponent makes the non-Primary component of the node a new Primary component
+func (p *Database) BootstrapPrimaryComponent() error {
+	_, err := p.db.Exec("SET GLOBAL wsrep_provider_options='pc.bootstrap=YES'")
+	return err
+}
+
 func (p *Database) Version() (string, error) {
 	var version string
 
@@ -131,6 +182,319 @@ func (p *Database) Version() (string, error) {
 	return version, nil
 }
 
+// Exec executes given statements one by one in a single session
+func (p *Database) Exec(stmts ...string) error {
+	conn, err := p.db.Conn(context.TODO())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, stmt := range stmts {
+		_, err = conn.ExecContext(context.TODO(), stmt)
+		if err != nil {
+			return fmt.Errorf("exec %q: %v", stmt, err)
+		}
+	}
+
+	return nil
+}
+
+// TableLock is the write lock of a table held by a dedicated session of the node
+type TableLock struct {
+	conn  *sql.Conn
+	table string
+	name  string
+	mode  string
+}
+
+// LockTableWrite blocks writes to the table (in db.table form) on the node until
+// the lock is released. Explicit table locks and tablespace operations aren't allowed
+// by pxc_strict_mode, so the mode is relaxed while the lock is held.
+// The lock is held by the connection, so the database must not be closed before it.
+func (p *Database) LockTableWrite(table string) (*TableLock, error) {
+	ctx := context.TODO()
+
+	name, err := quoteTable(table)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	l := &TableLock{conn: conn, table: table, name: name}
+
+	err = conn.QueryRowContext(ctx, "SELECT @@GLOBAL.pxc_strict_mode").Scan(&l.mode)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("get pxc_strict_mode: %v", err)
+	}
+	if l.mode != "PERMISSIVE" && l.mode != "DISABLED" {
+		_, err = conn.ExecContext(ctx, "SET GLOBAL pxc_strict_mode=PERMISSIVE")
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("set pxc_strict_mode: %v", err)
+		}
+	}
+
+	_, err = conn.ExecContext(ctx, "SET SESSION foreign_key_checks=0")
+	if err == nil {
+		_, err = conn.ExecContext(ctx, "LOCK TABLES "+name+" WRITE")
+	}
+	if err != nil {
+		l.Release()
+		return nil, fmt.Errorf("lock table: %v", err)
+	}
+
+	return l, nil
+}
+
+// ImportTablespace replaces the tablespace of the locked table with the files
+// copyFiles puts into the datadir. Tablespace discard and import aren't
+// replicated by Galera, so it has to be done on every node separately.
+func (l *TableLock) ImportTablespace(copyFiles func() error) error {
+	ctx := context.TODO()
+
+	_, err := l.conn.ExecContext(ctx, "ALTER TABLE "+l.name+" DISCARD TABLESPACE")
+	if err != nil {
+		return fmt.Errorf("discard tablespace: %v", err)
+	}
+
+	err = copyFiles()
+	if err != nil {
+		return fmt.Errorf("copy tablespace files: %v", err)
+	}
+
+	_, err = l.conn.ExecContext(ctx, "ALTER TABLE "+l.name+" IMPORT TABLESPACE")
+	if err != nil {
+		return fmt.Errorf("import tablespace: %v", err)
+	}
+
+	return nil
+}
+
+// Checksum returns the live checksum of the locked table
+func (l *TableLock) Checksum() (int64, error) {
+	var tbl string
+	var sum sql.NullInt64
+	err := l.conn.QueryRowContext(context.TODO(), "CHECKSUM TABLE "+l.name).Scan(&tbl, &sum)
+	if err != nil {
+		return 0, fmt.Errorf("checksum table: %v", err)
+	}
+	if !sum.Valid {
+		return 0, fmt.Errorf("table %s doesn't exist", l.table)
+	}
+
+	return sum.Int64, nil
+}
+
+// Release unlocks the table and restores pxc_strict_mode
+func (l *TableLock) Release() error {
+	ctx := context.TODO()
+	defer l.conn.Close()
+
+	_, err := l.conn.ExecContext(ctx, "UNLOCK TABLES")
+	if err != nil {
+		err = fmt.Errorf("unlock tables: %v", err)
+	}
+	if l.mode != "PERMISSIVE" && l.mode != "DISABLED" {
+		_, merr := l.conn.ExecContext(ctx, "SET GLOBAL pxc_strict_mode="+l.mode)
+		if merr != nil && err == nil {
+			err = fmt.Errorf("restore pxc_strict_mode: %v", merr)
+		}
+	}
+
+	return err
+}
+
+// BackupLock is the backup lock held by a dedicated session of the node
+type BackupLock struct {
+	conn   *sql.Conn
+	unlock string
+}
+
+// AcquireBackupLock desyncs the node from the cluster and blocks changes to
+// the data files with the backup lock until the lock is released.
+// The lock is held by the connection, so the database must not be closed before it.
+func (p *Database) AcquireBackupLock() (*BackupLock, error) {
+	ctx := context.TODO()
+
+	var version string
+	err := p.db.QueryRowContext(ctx, "select @@VERSION;").Scan(&version)
+	if err != nil {
+		return nil, fmt.Errorf("get version: %v", err)
+	}
+	lock, unlock := "LOCK TABLES FOR BACKUP", "UNLOCK TABLES"
+	if strings.HasPrefix(version, "8.") {
+		lock, unlock = "LOCK INSTANCE FOR BACKUP", "UNLOCK INSTANCE"
+	}
+
+	// locks are held by the session, so all statements have to use the same connection
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = conn.ExecContext(ctx, "SET GLOBAL wsrep_desync=ON")
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("desync node: %v", err)
+	}
+
+	_, err = conn.ExecContext(ctx, lock)
+	if err != nil {
+		conn.ExecContext(ctx, "SET GLOBAL wsrep_desync=OFF")
+		conn.Close()
+		return nil, fmt.Errorf("%s: %v", lock, err)
+	}
+
+	return &BackupLock{conn: conn, unlock: unlock}, nil
+}
+
+// Release unlocks the node and brings it back in sync with the cluster
+func (l *BackupLock) Release() error {
+	ctx := context.TODO()
+	defer l.conn.Close()
+
+	_, err := l.conn.ExecContext(ctx, l.unlock)
+	if err != nil {
+		return fmt.Errorf("%s: %v", l.unlock, err)
+	}
+
+	_, err = l.conn.ExecContext(ctx, "SET GLOBAL wsrep_desync=OFF")
+	if err != nil {
+		return fmt.Errorf("resync node: %v", err)
+	}
+
+	return nil
+}
+
+// Resync brings the node back in sync with the cluster if it's left
+// desynced by the lost backup lock session
+func (p *Database) Resync() error {
+	_, err := p.db.Exec("SET GLOBAL wsrep_desync=OFF")
+	if err != nil {
+		return fmt.Errorf("resync node: %v", err)
+	}
+
+	return nil
+}
+
+// MaintainTable runs ANALYZE or OPTIMIZE TABLE on the table (in db.table form)
+// and returns the messages of the server. Failures are reported in the result
+// set rather than as the error of the statement.
+func (p *Database) MaintainTable(op, table string) ([]string, error) {
+	if op != "ANALYZE" && op != "OPTIMIZE" {
+		return nil, fmt.Errorf("unknown table operation %s", op)
+	}
+	name, err := quoteTable(table)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := p.db.Query(op + " TABLE " + name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var msgs []string
+	var failed bool
+	for rows.Next() {
+		var tbl, o, msgType, msgText string
+		err := rows.Scan(&tbl, &o, &msgType, &msgText)
+		if err != nil {
+			return msgs, err
+		}
+		msgs = append(msgs, msgType+": "+msgText)
+		if msgType == "error" {
+			failed = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return msgs, err
+	}
+	if failed {
+		return msgs, fmt.Errorf("%s TABLE %s: %s", op, table, strings.Join(msgs, "; "))
+	}
+
+	return msgs, nil
+}
+
+// KillLongQueries kills queries running longer than the given number of seconds
+// and returns their ids. Sessions of the server itself, of the operator and
+// of backups are left alone.
+func (p *Database) KillLongQueries(seconds int32) ([]int64, error) {
+	rows, err := p.db.Query("SELECT ID FROM information_schema.PROCESSLIST "+
+		"WHERE COMMAND = 'Query' AND TIME >= ? AND ID != CONNECTION_ID() "+
+		"AND USER NOT IN ('system user', 'event_scheduler', 'operator', 'xtrabackup')", seconds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	killed := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		_, err = p.db.Exec("KILL QUERY " + strconv.FormatInt(id, 10))
+		// the query could finish in the meantime
+		if err != nil && !strings.Contains(err.Error(), "Unknown thread id") {
+			return killed, fmt.Errorf("kill query %d: %v", id, err)
+		}
+		if err == nil {
+			killed = append(killed, id)
+		}
+	}
+
+	return killed, nil
+}
+
+// ClientConnections returns the number of sessions of clients connected to the node.
+// Sessions of the server itself and of system users are not counted.
+func (p *Database) ClientConnections() (int, error) {
+	var count int
+	err := p.db.QueryRow("SELECT COUNT(*) FROM information_schema.PROCESSLIST " +
+		"WHERE ID != CONNECTION_ID() AND USER NOT IN " +
+		"('system user', 'event_scheduler', 'unauthenticated user', 'operator', 'monitor', 'clustercheck', 'xtrabackup', 'proxyadmin')").Scan(&count)
+
+	return count, err
+}
+
+// SetOfflineSoft makes ProxySQL stop sending new queries to the server with the given
+// host prefix or ip, current connections are kept until they are closed
+func (p *Database) SetOfflineSoft(host, ip string) error {
+	_, err := p.db.Exec("UPDATE mysql_servers SET status = 'OFFLINE_SOFT' WHERE hostname LIKE ? OR hostname = ?", host+"%", ip)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.Exec("LOAD MYSQL SERVERS TO RUNTIME")
+	return err
+}
+
+func quoteTable(table string) (string, error) {
+	spl := strings.SplitN(table, ".", 2)
+	if len(spl) != 2 {
+		return "", fmt.Errorf("table %q should be in db.table form", table)
+	}
+
+	return "`" + strings.ReplaceAll(spl[0], "`", "``") + "`.`" + strings.ReplaceAll(spl[1], "`", "``") + "`", nil
+}
+
 func (p *Database) Close() error {
 	return p.db.Close()
 }
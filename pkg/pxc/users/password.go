@@ -0,0 +1,34 @@
+package users
+
+import (
+	"crypto/rand"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	passwordMaxLen = 20
+	passwordMinLen = 16
+	passSymbols    = "ABCDEFGHIJKLMNOPQRSTUVWXYZ" +
+		"abcdefghijklmnopqrstuvwxyz" +
+		"0123456789"
+)
+
+// GeneratePass generates a random password of passwordMinLen to passwordMaxLen symbols
+func GeneratePass() ([]byte, error) {
+	ln, err := rand.Int(rand.Reader, big.NewInt(passwordMaxLen-passwordMinLen))
+	if err != nil {
+		return nil, errors.Wrap(err, "get rand length")
+	}
+	b := make([]byte, ln.Int64()+passwordMinLen)
+	for i := range b {
+		randInt, err := rand.Int(rand.Reader, big.NewInt(int64(len(passSymbols))))
+		if err != nil {
+			return nil, errors.Wrap(err, "get rand int")
+		}
+		b[i] = passSymbols[randInt.Int64()]
+	}
+
+	return b, nil
+}
@@ -1,7 +1,6 @@
 package webhook
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"net/http"
@@ -23,7 +22,6 @@ import (
 	log "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
-	v1 "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
 	"github.com/percona/percona-xtradb-cluster-operator/pkg/k8s"
 	"github.com/percona/percona-xtradb-cluster-operator/pkg/pxctls"
 )
@@ -33,7 +31,10 @@ const certPath = "/tmp/k8s-webhook-server/serving-certs/"
 var hookPath = "/validate-percona-xtradbcluster"
 
 type hook struct {
-	cl        client.Client
+	cl client.Client
+	// reader reads objects validated resources refer to directly from the API server,
+	// backups and restores can be created in namespaces the operator doesn't cache
+	reader    client.Reader
 	scheme    *runtime.Scheme
 	caBunlde  []byte
 	namespace string
@@ -140,6 +141,15 @@ func (h *hook) createWebhook(ownerRef metav1.OwnerReference) error {
 						},
 						Operations: []admissionregistration.OperationType{"CREATE", "UPDATE"},
 					},
+					{
+						// backups and restores are changed only by the operator after they are created
+						Rule: admissionregistration.Rule{
+							APIGroups:   []string{"pxc.percona.com"},
+							APIVersions: []string{"*"},
+							Resources:   []string{"perconaxtradbclusterbackups", "perconaxtradbclusterrestores"},
+						},
+						Operations: []admissionregistration.OperationType{"CREATE"},
+					},
 				},
 			},
 		},
@@ -151,17 +161,18 @@ func (h *hook) createWebhook(ownerRef metav1.OwnerReference) error {
 	}
 
 	if err != nil && k8serrors.IsAlreadyExists(err) {
-		hook := &admissionregistration.ValidatingWebhookConfiguration{}
+		existing := &admissionregistration.ValidatingWebhookConfiguration{}
 		err := h.cl.Get(context.TODO(), types.NamespacedName{
 			Name: "percona-xtradbcluster-webhook",
-		}, hook)
+		}, existing)
 		if err != nil {
 			return err
 		}
 
-		hook.Webhooks[0].ClientConfig.CABundle = h.caBunlde
-		hook.ObjectMeta.OwnerReferences = []metav1.OwnerReference{ownerRef}
-		return h.cl.Update(context.TODO(), hook)
+		// webhooks are replaced to get rules added by newer operator versions
+		existing.Webhooks = hook.Webhooks
+		existing.ObjectMeta.OwnerReferences = []metav1.OwnerReference{ownerRef}
+		return h.cl.Update(context.TODO(), existing)
 	}
 	return err
 }
@@ -186,6 +197,7 @@ func SetupWebhook(mgr manager.Manager) error {
 
 	h := &hook{
 		cl:        mgr.GetClient(),
+		reader:    mgr.GetAPIReader(),
 		scheme:    mgr.GetScheme(),
 		caBunlde:  ca,
 		namespace: namespace,
@@ -250,28 +262,16 @@ func (h *hook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cr := &v1.PerconaXtraDBCluster{}
-	decoder := json.NewDecoder(bytes.NewReader(req.Request.Object.Raw))
-	decoder.DisallowUnknownFields()
-
-	err = decoder.Decode(cr)
-	if err != nil {
-		err = sendResponse(req.Request.UID, req.TypeMeta, w, err)
-		if err != nil {
-			log.Log.Error(err, "Can't send validation response")
-		}
-		return
-	}
-
-	if cr.Spec.EnableCRValidationWebhook == nil || !*cr.Spec.EnableCRValidationWebhook {
-		err = sendResponse(req.Request.UID, req.TypeMeta, w, nil)
-		if err != nil {
-			log.Log.Error(err, "Can't send validation response")
-		}
-		return
+	switch req.Request.Kind.Kind {
+	case "PerconaXtraDBClusterBackup":
+		err = h.validateBackup(req.Request.Object.Raw, req.Request.Namespace)
+	case "PerconaXtraDBClusterRestore":
+		err = h.validateRestore(req.Request.Object.Raw, req.Request.Namespace)
+	default:
+		err = h.validateCluster(req.Request.Object.Raw)
 	}
 
-	err = sendResponse(req.Request.UID, req.TypeMeta, w, cr.Validate())
+	err = sendResponse(req.Request.UID, req.TypeMeta, w, err)
 	if err != nil {
 		log.Log.Error(err, "Can't send validation response")
 	}
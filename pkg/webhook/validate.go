@@ -0,0 +1,168 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+)
+
+// pitrDateFormat is the format of the PITR date the recoverer accepts
+const pitrDateFormat = "2006-01-02 15:04:05"
+
+func decode(raw []byte, obj interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(obj)
+}
+
+// validationEnabled checks if the cluster opted in for the validation webhook
+func validationEnabled(cluster *v1.PerconaXtraDBCluster) bool {
+	return cluster.Spec.EnableCRValidationWebhook != nil && *cluster.Spec.EnableCRValidationWebhook
+}
+
+func (h *hook) validateCluster(raw []byte) error {
+	cr := &v1.PerconaXtraDBCluster{}
+	err := decode(raw, cr)
+	if err != nil {
+		return err
+	}
+
+	if !validationEnabled(cr) {
+		return nil
+	}
+
+	return cr.Validate()
+}
+
+// getCluster returns the cluster if it opted in for the validation webhook.
+// Backups and restores of a cluster that doesn't exist are rejected, the ones of
+// other clusters and of clusters the operator can't see are left to their controllers.
+func (h *hook) getCluster(name, namespace string) (*v1.PerconaXtraDBCluster, error) {
+	if name == "" {
+		return nil, errors.New("pxcCluster can't be empty")
+	}
+
+	cluster := &v1.PerconaXtraDBCluster{}
+	err := h.reader.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, cluster)
+	if k8serrors.IsNotFound(err) {
+		return nil, errors.Errorf("cluster %s doesn't exist", name)
+	}
+	if k8serrors.IsForbidden(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "get cluster %s", name)
+	}
+	if !validationEnabled(cluster) {
+		return nil, nil
+	}
+
+	return cluster, nil
+}
+
+// validateBackup rejects backups the backup controller would fail anyway
+func (h *hook) validateBackup(raw []byte, namespace string) error {
+	cr := &v1.PerconaXtraDBClusterBackup{}
+	err := decode(raw, cr)
+	if err != nil {
+		return err
+	}
+
+	cluster, err := h.getCluster(cr.Spec.PXCCluster, namespace)
+	if err != nil || cluster == nil {
+		return err
+	}
+
+	err = cr.CheckNsetDefaults()
+	if err != nil {
+		return err
+	}
+
+	if cluster.Spec.Backup == nil {
+		return errors.Errorf("cluster %s has no backup section", cluster.Name)
+	}
+	storage, ok := cluster.Spec.Backup.Storages[cr.Spec.StorageName]
+	if !ok {
+		return errors.Errorf("storage %s doesn't exist in cluster %s", cr.Spec.StorageName, cluster.Name)
+	}
+	if storage.Type == v1.BackupStorageS3 && cr.Spec.Type == v1.BackupTypePhysical {
+		return storage.S3.CheckPhysical()
+	}
+
+	return nil
+}
+
+// validateRestore rejects restores the restore controller would fail anyway
+func (h *hook) validateRestore(raw []byte, namespace string) error {
+	cr := &v1.PerconaXtraDBClusterRestore{}
+	err := decode(raw, cr)
+	if err != nil {
+		return err
+	}
+
+	cluster, err := h.getCluster(cr.Spec.PXCCluster, namespace)
+	if err != nil || cluster == nil {
+		return err
+	}
+
+	err = cr.CheckNsetDefaults()
+	if err != nil {
+		return err
+	}
+
+	restores := &v1.PerconaXtraDBClusterRestoreList{}
+	err = h.reader.List(context.TODO(), restores, &client.ListOptions{Namespace: namespace})
+	if err != nil {
+		return errors.Wrap(err, "get restores list")
+	}
+	for _, r := range restores.Items {
+		if r.Spec.PXCCluster == cr.Spec.PXCCluster && r.Name != cr.Name && !r.Status.State.Finished() {
+			return errors.Errorf("restore %s of cluster %s isn't finished yet", r.Name, cr.Spec.PXCCluster)
+		}
+	}
+
+	if cr.Spec.BackupSource != nil {
+		return nil
+	}
+
+	bcpNamespace := namespace
+	if cr.Spec.BackupNamespace != "" {
+		bcpNamespace = cr.Spec.BackupNamespace
+	}
+	bcp := &v1.PerconaXtraDBClusterBackup{}
+	err = h.reader.Get(context.TODO(), types.NamespacedName{Name: cr.Spec.BackupName, Namespace: bcpNamespace}, bcp)
+	if k8serrors.IsNotFound(err) {
+		return errors.Errorf("backup %s doesn't exist", cr.Spec.BackupName)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "get backup %s", cr.Spec.BackupName)
+	}
+
+	return validatePITR(cr, bcp)
+}
+
+// validatePITR checks that the point-in-time recovery target isn't earlier than the backup
+func validatePITR(cr *v1.PerconaXtraDBClusterRestore, bcp *v1.PerconaXtraDBClusterBackup) error {
+	if cr.Spec.PITR == nil || cr.Spec.PITR.Type != "date" {
+		return nil
+	}
+
+	target, err := time.Parse(pitrDateFormat, cr.Spec.PITR.Date)
+	if err != nil {
+		return errors.Wrapf(err, "PITR date should be in %q format", pitrDateFormat)
+	}
+	if bcp.Status.CompletedAt != nil && target.Before(bcp.Status.CompletedAt.Time) {
+		return errors.Errorf("PITR date %s is earlier than backup %s completion time %s",
+			cr.Spec.PITR.Date, bcp.Name, bcp.Status.CompletedAt.UTC().Format(pitrDateFormat))
+	}
+
+	return nil
+}
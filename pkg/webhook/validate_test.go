@@ -0,0 +1,169 @@
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake" // nolint
+
+	v1 "github.com/percona/percona-xtradb-cluster-operator/pkg/apis/pxc/v1"
+)
+
+const testNamespace = "pxc"
+
+func newTestHook(t *testing.T, objs ...runtime.Object) *hook {
+	s := runtime.NewScheme()
+	if err := v1.SchemeBuilder.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	s.AddKnownTypes(v1.SchemeGroupVersion, &v1.PerconaXtraDBCluster{}, &v1.PerconaXtraDBClusterList{})
+
+	cl := fake.NewFakeClientWithScheme(s, objs...)
+	return &hook{cl: cl, reader: cl, scheme: s}
+}
+
+func newTestCluster(name string, validation bool) *v1.PerconaXtraDBCluster {
+	return &v1.PerconaXtraDBCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Spec: v1.PerconaXtraDBClusterSpec{
+			EnableCRValidationWebhook: &validation,
+			Backup: &v1.PXCScheduledBackup{
+				Storages: map[string]*v1.BackupStorageSpec{
+					"s3-us-west": {
+						Type: v1.BackupStorageS3,
+						S3:   v1.BackupStorageS3Spec{Bucket: "bucket", CredentialsSecret: "my-cluster-name-backup-s3"},
+					},
+					"s3-no-credentials": {
+						Type: v1.BackupStorageS3,
+						S3:   v1.BackupStorageS3Spec{Bucket: "bucket"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func raw(t *testing.T, obj interface{}) []byte {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestValidateBackup(t *testing.T) {
+	h := newTestHook(t, newTestCluster("validated", true), newTestCluster("unvalidated", false))
+
+	cases := []struct {
+		cluster    string
+		storage    string
+		backupType v1.BackupType
+		valid      bool
+	}{
+		{"validated", "s3-us-west", "", true},
+		{"validated", "missing", "", false},
+		{"validated", "s3-us-west", "incremental", false},
+		{"validated", "s3-no-credentials", v1.BackupTypeLogical, true},
+		{"validated", "s3-no-credentials", "", false},
+		{"unvalidated", "missing", "", true},
+		{"missing", "s3-us-west", "", false},
+		{"", "s3-us-west", "", false},
+	}
+
+	for _, c := range cases {
+		bcp := &v1.PerconaXtraDBClusterBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: "backup1", Namespace: testNamespace},
+			Spec:       v1.PXCBackupSpec{PXCCluster: c.cluster, StorageName: c.storage, Type: c.backupType},
+		}
+		err := h.validateBackup(raw(t, bcp), testNamespace)
+		if (err == nil) != c.valid {
+			t.Errorf("cluster %q, storage %q, type %q: expected valid=%t, got error %v", c.cluster, c.storage, c.backupType, c.valid, err)
+		}
+	}
+}
+
+func TestValidateRestore(t *testing.T) {
+	completed := metav1.NewTime(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC))
+	bcp := &v1.PerconaXtraDBClusterBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup1", Namespace: testNamespace},
+		Spec:       v1.PXCBackupSpec{PXCCluster: "validated"},
+		Status:     v1.PXCBackupStatus{CompletedAt: &completed},
+	}
+	running := &v1.PerconaXtraDBClusterRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: testNamespace},
+		Spec:       v1.PerconaXtraDBClusterRestoreSpec{PXCCluster: "busy", BackupName: "backup1"},
+		Status:     v1.PerconaXtraDBClusterRestoreStatus{State: v1.RestoreRestore},
+	}
+	done := &v1.PerconaXtraDBClusterRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "done", Namespace: testNamespace},
+		Spec:       v1.PerconaXtraDBClusterRestoreSpec{PXCCluster: "validated", BackupName: "backup1"},
+		Status:     v1.PerconaXtraDBClusterRestoreStatus{State: v1.RestoreSucceeded},
+	}
+	h := newTestHook(t, newTestCluster("validated", true), newTestCluster("unvalidated", false),
+		newTestCluster("busy", true), bcp, running, done)
+
+	cases := []struct {
+		name  string
+		spec  v1.PerconaXtraDBClusterRestoreSpec
+		valid bool
+	}{
+		{"valid", v1.PerconaXtraDBClusterRestoreSpec{PXCCluster: "validated", BackupName: "backup1"}, true},
+		{"missing backup", v1.PerconaXtraDBClusterRestoreSpec{PXCCluster: "validated", BackupName: "backup2"}, false},
+		{"backup source", v1.PerconaXtraDBClusterRestoreSpec{PXCCluster: "validated", BackupSource: &v1.PXCBackupStatus{}}, true},
+		{"invalid spec", v1.PerconaXtraDBClusterRestoreSpec{PXCCluster: "validated"}, false},
+		{"invalid spec of unvalidated cluster", v1.PerconaXtraDBClusterRestoreSpec{PXCCluster: "unvalidated"}, true},
+		{"missing cluster", v1.PerconaXtraDBClusterRestoreSpec{PXCCluster: "missing", BackupName: "backup2"}, false},
+		{"empty cluster", v1.PerconaXtraDBClusterRestoreSpec{BackupName: "backup1"}, false},
+		{"concurrent restore", v1.PerconaXtraDBClusterRestoreSpec{PXCCluster: "busy", BackupName: "backup1"}, false},
+		{"PITR before the backup", v1.PerconaXtraDBClusterRestoreSpec{PXCCluster: "validated", BackupName: "backup1",
+			PITR: &v1.PITR{Type: "date", Date: "2021-03-04 05:00:00"}}, false},
+	}
+
+	for _, c := range cases {
+		cr := &v1.PerconaXtraDBClusterRestore{
+			ObjectMeta: metav1.ObjectMeta{Name: "restore1", Namespace: testNamespace},
+			Spec:       c.spec,
+		}
+		err := h.validateRestore(raw(t, cr), testNamespace)
+		if (err == nil) != c.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", c.name, c.valid, err)
+		}
+	}
+}
+
+func TestValidatePITR(t *testing.T) {
+	completed := metav1.NewTime(time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC))
+	bcp := &v1.PerconaXtraDBClusterBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup1"},
+		Status:     v1.PXCBackupStatus{CompletedAt: &completed},
+	}
+
+	cases := []struct {
+		pitr  *v1.PITR
+		valid bool
+	}{
+		{nil, true},
+		{&v1.PITR{Type: "latest"}, true},
+		{&v1.PITR{Type: "date", Date: "2021-03-04 05:06:07"}, true},
+		{&v1.PITR{Type: "date", Date: "2021-03-05 00:00:00"}, true},
+		{&v1.PITR{Type: "date", Date: "2021-03-04 05:06:06"}, false},
+		{&v1.PITR{Type: "date", Date: "2021-03-04T05:06:07Z"}, false},
+	}
+
+	for _, c := range cases {
+		cr := &v1.PerconaXtraDBClusterRestore{Spec: v1.PerconaXtraDBClusterRestoreSpec{PITR: c.pitr}}
+		err := validatePITR(cr, bcp)
+		if (err == nil) != c.valid {
+			t.Errorf("%+v: expected valid=%t, got error %v", c.pitr, c.valid, err)
+		}
+	}
+
+	// backups without the completion time can't be checked
+	cr := &v1.PerconaXtraDBClusterRestore{Spec: v1.PerconaXtraDBClusterRestoreSpec{PITR: &v1.PITR{Type: "date", Date: "2000-01-01 00:00:00"}}}
+	if err := validatePITR(cr, &v1.PerconaXtraDBClusterBackup{}); err != nil {
+		t.Errorf("unfinished backup: unexpected error %v", err)
+	}
+}